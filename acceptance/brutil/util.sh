@@ -16,10 +16,18 @@ create_veth() {
     sudo sysctl -qw net.ipv6.conf.$VETH_HOST.disable_ipv6=1
     sudo ip link set $VETH_HOST up
     sudo ip link set $VETH_CONTAINER netns $NS
-    sudo ip netns exec $NS sysctl -qw net.ipv6.conf.$VETH_CONTAINER.disable_ipv6=1
+    if [[ $IP_CONTAINER == *:* ]]; then
+        # IPv6 underlay: keep IPv6 enabled, but do not generate a link-local
+        # address and skip DAD so that the link stays quiet.
+        sudo ip netns exec $NS sysctl -qw net.ipv6.conf.$VETH_CONTAINER.addr_gen_mode=1
+        ADDR_FLAGS=nodad
+    else
+        sudo ip netns exec $NS sysctl -qw net.ipv6.conf.$VETH_CONTAINER.disable_ipv6=1
+        ADDR_FLAGS=
+    fi
     sudo ip netns exec $NS ethtool -K $VETH_CONTAINER rx off tx off 1>&2
     sudo ip netns exec $NS ip link set $VETH_CONTAINER address $MAC_CONTAINER
-    sudo ip netns exec $NS ip addr add $IP_CONTAINER dev $VETH_CONTAINER
+    sudo ip netns exec $NS ip addr add $IP_CONTAINER dev $VETH_CONTAINER $ADDR_FLAGS
     for ip in "$@"; do
         sudo ip netns exec $NS ip neigh add $ip lladdr f0:0d:ca:fe:be:ef nud permanent dev $VETH_CONTAINER
     done
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -78,10 +79,17 @@ func run(file string) error {
 	if err != nil {
 		return err
 	}
+	policing, err := policingConfig(cfg.BR.Policing)
+	if err != nil {
+		return serrors.WrapStr("loading policing config", err)
+	}
 	stop := make(chan struct{})
 	wg := new(sync.WaitGroup)
 	dp := &router.Connector{
-		DataPlane: router.DataPlane{},
+		DataPlane: router.DataPlane{
+			Metrics:  router.NewMetrics(),
+			Policing: policing,
+		},
 	}
 	iaCtx := &control.IACtx{
 		BRConf:                   brConf,
@@ -92,21 +100,41 @@ func run(file string) error {
 	if err := iaCtx.Start(wg, cfg.General.ReconnectToDispatcher); err != nil {
 		return serrors.WrapStr("starting dataplane", err)
 	}
-	if err := setupHTTPHandlers(cfg); err != nil {
+	env.SetupEnv(func() {
+		newConf, err := loadBRConf(cfg)
+		if err != nil {
+			log.Error("Unable to reload config", "err", err)
+			return
+		}
+		if err := iaCtx.Reload(newConf); err != nil {
+			log.Error("Unable to reload config", "err", err)
+			return
+		}
+		log.Info("Config reloaded")
+	})
+	if err := setupHTTPHandlers(cfg, &dp.DataPlane); err != nil {
 		return serrors.WrapStr("starting HTTP endpoints", err)
 	}
-	if err := dp.DataPlane.Run(); err != nil {
-		return serrors.WrapStr("starting dataplane", err)
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dpErr := make(chan error, 1)
+	go func() {
+		defer log.HandlePanic()
+		dpErr <- dp.DataPlane.Run(ctx)
+	}()
 
-	// XXX(lukedirtwalker): Currently not reachable because the dataplan run is
-	// blocking.
 	select {
 	case <-fatal.ShutdownChan():
 		// Whenever we receive a SIGINT or SIGTERM we exit without an error.
 		// Deferred shutdowns for all running servers run now.
+		cancel()
 		close(stop)
 		wg.Wait()
+		return <-dpErr
+	case err := <-dpErr:
+		if err != nil {
+			return serrors.WrapStr("running dataplane", err)
+		}
 		return nil
 	case <-fatal.FatalChan():
 		return serrors.New("shutdown on error")
@@ -147,11 +175,41 @@ func loadBRConf(cfg brconf.Config) (*brconf.BRConf, error) {
 	return newConf, nil
 }
 
-func setupHTTPHandlers(cfg brconf.Config) error {
+// policingConfig converts the policing configuration to the configuration of the
+// dataplane.
+func policingConfig(cfg brconf.Policing) (router.Policing, error) {
+	p := router.Policing{
+		Interface: router.RateLimit{
+			Rate:  float64(cfg.InterfaceRate),
+			Burst: cfg.InterfaceBurst,
+		},
+		SourceIA: router.RateLimit{
+			Rate:  float64(cfg.SourceIARate),
+			Burst: cfg.SourceIABurst,
+		},
+		SCMP: router.RateLimit{
+			Rate:  float64(cfg.SCMPRate),
+			Burst: cfg.SCMPBurst,
+		},
+		SignalSCMP: cfg.Action == brconf.PolicingActionSCMP,
+		Interfaces: make(map[uint16]router.RateLimit, len(cfg.Interfaces)),
+	}
+	for k, l := range cfg.Interfaces {
+		ifID, err := brconf.ParseInterface(k)
+		if err != nil {
+			return router.Policing{}, err
+		}
+		p.Interfaces[uint16(ifID)] = router.RateLimit{Rate: float64(l.Rate), Burst: l.Burst}
+	}
+	return p, nil
+}
+
+func setupHTTPHandlers(cfg brconf.Config, dp *router.DataPlane) error {
 	statusPages := service.StatusPages{
 		"info":      service.NewInfoHandler(),
 		"config":    service.NewConfigHandler(cfg),
 		"log/level": log.ConsoleLevel.ServeHTTP,
+		"capture":   router.NewCaptureHandler(dp),
 		// TODO: Add topology page
 	}
 	if err := statusPages.Register(http.DefaultServeMux, cfg.General.ID); err != nil {
@@ -72,20 +72,27 @@ type BR struct {
 	// RollbackFailAction indicates the action that should be taken
 	// if the rollback fails.
 	RollbackFailAction FailAction `toml:"rollback_fail_action,omitempty"`
+	// Policing is the admission control configuration.
+	Policing Policing `toml:"policing,omitempty"`
 }
 
 func (cfg *BR) InitDefaults() {
 	if cfg.RollbackFailAction != FailActionContinue {
 		cfg.RollbackFailAction = FailActionFatal
 	}
+	cfg.Policing.InitDefaults()
 }
 
 func (cfg *BR) Validate() error {
-	return cfg.RollbackFailAction.Validate()
+	if err := cfg.RollbackFailAction.Validate(); err != nil {
+		return err
+	}
+	return cfg.Policing.Validate()
 }
 
-func (cfg *BR) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+func (cfg *BR) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
 	config.WriteString(dst, brSample)
+	config.WriteSample(dst, path, ctx, &cfg.Policing)
 }
 
 func (cfg *BR) ConfigName() string {
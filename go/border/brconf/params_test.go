@@ -54,4 +54,75 @@ func CheckTestConfig(t *testing.T, cfg *brconf.Config, id string) {
 
 func CheckTestBRConfig(t *testing.T, cfg *brconf.BR) {
 	assert.Equal(t, brconf.FailActionFatal, cfg.RollbackFailAction)
+	assert.Equal(t, brconf.PolicingActionDrop, cfg.Policing.Action)
+	assert.Equal(t, brconf.DefaultSCMPRate, cfg.Policing.SCMPRate)
+	assert.Equal(t, brconf.InterfaceLimits{"1": {Rate: 10000, Burst: 1000}},
+		cfg.Policing.Interfaces)
+}
+
+func TestPolicingInitDefaults(t *testing.T) {
+	testCases := map[string]struct {
+		SCMPRate int
+		Expected int
+	}{
+		"unset":    {SCMPRate: 0, Expected: brconf.DefaultSCMPRate},
+		"set":      {SCMPRate: 10, Expected: 10},
+		"disabled": {SCMPRate: -1, Expected: -1},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			cfg := brconf.Policing{SCMPRate: tc.SCMPRate}
+			cfg.InitDefaults()
+			assert.Equal(t, tc.Expected, cfg.SCMPRate)
+		})
+	}
+}
+
+func TestPolicingValidate(t *testing.T) {
+	testCases := map[string]struct {
+		Policing  brconf.Policing
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Policing: brconf.Policing{
+				Action:        brconf.PolicingActionSCMP,
+				InterfaceRate: 1000,
+				Interfaces: brconf.InterfaceLimits{
+					"internal": {Rate: 10000},
+					"42":       {Rate: 100, Burst: 10},
+				},
+			},
+			Assertion: assert.NoError,
+		},
+		"unknown action": {
+			Policing:  brconf.Policing{Action: "reject"},
+			Assertion: assert.Error,
+		},
+		"negative rate": {
+			Policing:  brconf.Policing{Action: brconf.PolicingActionDrop, SourceIARate: -1},
+			Assertion: assert.Error,
+		},
+		"disabled SCMP limit": {
+			Policing:  brconf.Policing{Action: brconf.PolicingActionDrop, SCMPRate: -1},
+			Assertion: assert.NoError,
+		},
+		"negative burst": {
+			Policing:  brconf.Policing{Action: brconf.PolicingActionDrop, SCMPBurst: -1},
+			Assertion: assert.Error,
+		},
+		"invalid interface": {
+			Policing: brconf.Policing{
+				Action:     brconf.PolicingActionDrop,
+				Interfaces: brconf.InterfaceLimits{"0": {Rate: 1}},
+			},
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			tc.Assertion(t, tc.Policing.Validate())
+		})
+	}
 }
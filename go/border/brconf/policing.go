@@ -0,0 +1,196 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brconf
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/config"
+)
+
+const (
+	// DefaultSCMPRate is the default rate limit in packets per second for the
+	// SCMP messages generated per ingress interface.
+	DefaultSCMPRate = 100
+	// InternalInterface is the key of the internal interface in the interface
+	// specific limits.
+	InternalInterface = "internal"
+)
+
+var _ config.Config = (*Policing)(nil)
+
+// Policing is the admission control configuration of the border router. The
+// rates are in packets per second, a zero rate disables the limit. The SCMP
+// limit is the exception, it is enabled by default and disabled by a negative
+// rate. A zero burst defaults to the packets of one second at the configured
+// rate.
+type Policing struct {
+	// Action is the action that is taken for packets that exceed a limit.
+	Action PolicingAction `toml:"action,omitempty"`
+	// InterfaceRate is the rate limit of each ingress interface that has no
+	// specific limit in Interfaces.
+	InterfaceRate int `toml:"interface_rate,omitempty"`
+	// InterfaceBurst is the burst size of the ingress interface limit.
+	InterfaceBurst int `toml:"interface_burst,omitempty"`
+	// SourceIARate is the rate limit of each source ISD-AS.
+	SourceIARate int `toml:"source_ia_rate,omitempty"`
+	// SourceIABurst is the burst size of the source ISD-AS limit.
+	SourceIABurst int `toml:"source_ia_burst,omitempty"`
+	// SCMPRate is the rate limit for the SCMP messages that are generated in
+	// response to the packets of an ingress interface. If it is zero,
+	// DefaultSCMPRate is used. A negative rate disables the limit.
+	SCMPRate int `toml:"scmp_rate,omitempty"`
+	// SCMPBurst is the burst size of the SCMP limit.
+	SCMPBurst int `toml:"scmp_burst,omitempty"`
+	// Interfaces contains the limits of individual ingress interfaces.
+	Interfaces InterfaceLimits `toml:"interfaces,omitempty"`
+}
+
+func (cfg *Policing) InitDefaults() {
+	if cfg.Action == "" {
+		cfg.Action = PolicingActionDrop
+	}
+	if cfg.SCMPRate == 0 {
+		cfg.SCMPRate = DefaultSCMPRate
+	}
+}
+
+func (cfg *Policing) Validate() error {
+	if err := cfg.Action.Validate(); err != nil {
+		return err
+	}
+	if err := validateLimit("interface", cfg.InterfaceRate, cfg.InterfaceBurst); err != nil {
+		return err
+	}
+	if err := validateLimit("source_ia", cfg.SourceIARate, cfg.SourceIABurst); err != nil {
+		return err
+	}
+	if err := validateLimit("scmp", cfg.scmpRate(), cfg.SCMPBurst); err != nil {
+		return err
+	}
+	return cfg.Interfaces.Validate()
+}
+
+// scmpRate returns the SCMP rate with the negative rate that disables the limit
+// mapped to zero.
+func (cfg *Policing) scmpRate() int {
+	if cfg.SCMPRate < 0 {
+		return 0
+	}
+	return cfg.SCMPRate
+}
+
+func (cfg *Policing) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
+	config.WriteString(dst, policingSample)
+	config.WriteSample(dst, path, ctx, &cfg.Interfaces)
+}
+
+func (cfg *Policing) ConfigName() string {
+	return "policing"
+}
+
+var _ config.Config = (*InterfaceLimits)(nil)
+
+// InterfaceLimits maps ingress interfaces to their rate limits. The keys are
+// interface IDs, or InternalInterface for the internal interface.
+type InterfaceLimits map[string]RateLimit
+
+func (cfg *InterfaceLimits) InitDefaults() {}
+
+func (cfg *InterfaceLimits) Validate() error {
+	for k, l := range *cfg {
+		if _, err := ParseInterface(k); err != nil {
+			return err
+		}
+		if err := validateLimit("interfaces."+k, l.Rate, l.Burst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *InterfaceLimits) Sample(dst io.Writer, _ config.Path, _ config.CtxMap) {
+	config.WriteString(dst, interfacesSample)
+}
+
+func (cfg *InterfaceLimits) ConfigName() string {
+	return "interfaces"
+}
+
+// RateLimit is the rate limit of a single ingress interface.
+type RateLimit struct {
+	// Rate is the rate limit in packets per second.
+	Rate int `toml:"rate,omitempty"`
+	// Burst is the burst size in packets.
+	Burst int `toml:"burst,omitempty"`
+}
+
+// ParseInterface parses a key of the interface specific limits. The internal
+// interface is returned as interface 0.
+func ParseInterface(s string) (common.IFIDType, error) {
+	if strings.ToLower(s) == InternalInterface {
+		return 0, nil
+	}
+	ifID, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || ifID == 0 || ifID > math.MaxUint16 {
+		return 0, common.NewBasicError("Invalid interface", nil, "input", s)
+	}
+	return common.IFIDType(ifID), nil
+}
+
+func validateLimit(name string, rate, burst int) error {
+	if rate < 0 {
+		return common.NewBasicError("Invalid rate", nil, "limit", name, "rate", rate)
+	}
+	if burst < 0 {
+		return common.NewBasicError("Invalid burst", nil, "limit", name, "burst", burst)
+	}
+	return nil
+}
+
+type PolicingAction string
+
+const (
+	// PolicingActionDrop indicates that over-limit packets are dropped.
+	PolicingActionDrop PolicingAction = "drop"
+	// PolicingActionSCMP indicates that over-limit packets are dropped and
+	// answered with an SCMP destination unreachable message.
+	PolicingActionSCMP PolicingAction = "scmp"
+)
+
+func (a *PolicingAction) Validate() error {
+	switch *a {
+	case PolicingActionDrop, PolicingActionSCMP:
+		return nil
+	default:
+		return common.NewBasicError("Unknown PolicingAction", nil, "input", *a)
+	}
+}
+
+func (a *PolicingAction) UnmarshalText(text []byte) error {
+	switch PolicingAction(strings.ToLower(string(text))) {
+	case PolicingActionDrop:
+		*a = PolicingActionDrop
+	case PolicingActionSCMP:
+		*a = PolicingActionSCMP
+	default:
+		return common.NewBasicError("Unknown PolicingAction", nil, "input", string(text))
+	}
+	return nil
+}
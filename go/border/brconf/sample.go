@@ -21,3 +21,40 @@ const brSample = `
 # (fatal | continue) (default fatal)
 rollback_fail_action = "fatal"
 `
+
+const policingSample = `
+# Action that is taken for packets that exceed a rate limit. The packets are
+# either dropped silently, or dropped and answered with an SCMP destination
+# unreachable message. (drop | scmp) (default drop)
+action = "drop"
+
+# Rate limit in packets per second of each ingress interface, including the
+# internal interface. A rate of 0 disables the limit. (default 0)
+interface_rate = 0
+
+# Burst size in packets of the ingress interface limit. A burst of 0 allows
+# the packets of one second at the configured rate. (default 0)
+interface_burst = 0
+
+# Rate limit in packets per second of each source ISD-AS. A rate of 0 disables
+# the limit. (default 0)
+source_ia_rate = 0
+
+# Burst size in packets of the source ISD-AS limit. (default 0)
+source_ia_burst = 0
+
+# Rate limit in packets per second for the SCMP messages that are generated in
+# response to the packets of an ingress interface. A negative rate disables the
+# limit. (default 100)
+scmp_rate = 100
+
+# Burst size in packets of the SCMP limit. (default 0)
+scmp_burst = 0
+`
+
+const interfacesSample = `
+# Rate limits of individual ingress interfaces. They override interface_rate and
+# interface_burst. The keys are interface IDs, the internal interface is
+# "internal".
+"1" = { rate = 10000, burst = 1000 }
+`
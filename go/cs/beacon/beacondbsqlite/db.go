@@ -18,6 +18,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -182,6 +183,65 @@ func (e *executor) BeaconSources(ctx context.Context) ([]addr.IA, error) {
 	return ias, nil
 }
 
+func (e *executor) GetBeacons(ctx context.Context,
+	params beacon.QueryParams) ([]beacon.StoredBeacon, error) {
+
+	e.RLock()
+	defer e.RUnlock()
+	var conds []string
+	var args []interface{}
+	if params.StartsAt.I != 0 {
+		conds = append(conds, "StartIsd == ?")
+		args = append(args, params.StartsAt.I)
+	}
+	if params.StartsAt.A != 0 {
+		conds = append(conds, "StartAs == ?")
+		args = append(args, params.StartsAt.A)
+	}
+	if params.IngressInterface != 0 {
+		conds = append(conds, "InIntfID == ?")
+		args = append(args, params.IngressInterface)
+	}
+	where := ``
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT Beacon, InIntfID, Usage, LastUpdated
+		FROM Beacons
+		%s
+		ORDER BY StartIsd, StartAs, InIntfID, HopsLength
+	`, where)
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, db.NewReadError("Error selecting beacons", err)
+	}
+	defer rows.Close()
+	var beacons []beacon.StoredBeacon
+	for rows.Next() {
+		var rawBeacon sql.RawBytes
+		var inIntfID common.IFIDType
+		var usage beacon.Usage
+		var lastUpdated int64
+		if err := rows.Scan(&rawBeacon, &inIntfID, &usage, &lastUpdated); err != nil {
+			return nil, db.NewReadError(beacon.ErrReadingRows, err)
+		}
+		s, err := beacon.UnpackBeacon(rawBeacon)
+		if err != nil {
+			return nil, db.NewDataError(beacon.ErrParse, err)
+		}
+		beacons = append(beacons, beacon.StoredBeacon{
+			Beacon:      beacon.Beacon{Segment: s, InIfId: inIntfID},
+			Usage:       usage,
+			LastUpdated: time.Unix(0, lastUpdated),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.NewReadError(beacon.ErrReadingRows, err)
+	}
+	return beacons, nil
+}
+
 func (e *executor) CandidateBeacons(ctx context.Context, setSize int, usage beacon.Usage,
 	src addr.IA) (<-chan beacon.BeaconOrErr, error) {
 
@@ -133,6 +133,8 @@ func Test(t *testing.T, db Testable) {
 		testWrapper(testUpdateOlderIgnored))
 	t.Run("CandidateBeacons returns the expected beacons",
 		tableWrapper(false, testCandidateBeacons))
+	t.Run("GetBeacons returns the matching beacons",
+		testWrapper(testGetBeacons))
 	t.Run("DeleteExpired should delete expired segments",
 		testWrapper(testDeleteExpiredBeacons))
 	t.Run("DeleteRevokedBeacons",
@@ -172,6 +174,8 @@ func Test(t *testing.T, db Testable) {
 			txTestWrapper(testUpdateOlderIgnored))
 		t.Run("CandidateBeacons returns the expected beacons",
 			tableWrapper(true, testCandidateBeacons))
+		t.Run("GetBeacons returns the matching beacons",
+			txTestWrapper(testGetBeacons))
 		t.Run("DeleteExpired should delete expired segments",
 			txTestWrapper(testDeleteExpiredBeacons))
 		t.Run("DeleteRevokedBeacons",
@@ -352,6 +356,66 @@ func testCandidateBeacons(t *testing.T, db Testable, inTx bool) {
 	}
 }
 
+func testGetBeacons(t *testing.T, ctrl *gomock.Controller, db beacon.DBReadWrite) {
+	start := time.Now()
+	b3 := InsertBeacon(t, ctrl, db, Info3, 12, 0, beacon.UsageProp)
+	b2 := InsertBeacon(t, ctrl, db, Info2, 13, 0, beacon.UsageUpReg|beacon.UsageDownReg)
+	b1 := InsertBeacon(t, ctrl, db, Info1, 12, 0, beacon.UsageCoreReg)
+	usages := map[string]beacon.Usage{
+		string(b3.Segment.ID()): beacon.UsageProp,
+		string(b2.Segment.ID()): beacon.UsageUpReg | beacon.UsageDownReg,
+		string(b1.Segment.ID()): beacon.UsageCoreReg,
+	}
+
+	tests := map[string]struct {
+		Params   beacon.QueryParams
+		Expected []beacon.Beacon
+	}{
+		"all": {
+			Expected: []beacon.Beacon{b1, b3, b2},
+		},
+		"start ISD-AS": {
+			Params:   beacon.QueryParams{StartsAt: ia330},
+			Expected: []beacon.Beacon{b3, b2},
+		},
+		"start ISD wildcard": {
+			Params:   beacon.QueryParams{StartsAt: addr.IA{I: 1}},
+			Expected: []beacon.Beacon{b1, b3, b2},
+		},
+		"start AS wildcard": {
+			Params:   beacon.QueryParams{StartsAt: addr.IA{A: ia311.A}},
+			Expected: []beacon.Beacon{b1},
+		},
+		"ingress interface": {
+			Params:   beacon.QueryParams{IngressInterface: 12},
+			Expected: []beacon.Beacon{b1, b3},
+		},
+		"start ISD-AS and ingress interface": {
+			Params:   beacon.QueryParams{StartsAt: ia330, IngressInterface: 13},
+			Expected: []beacon.Beacon{b2},
+		},
+		"no match": {
+			Params: beacon.QueryParams{StartsAt: ia333},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancelF := context.WithTimeout(context.Background(), timeout)
+			defer cancelF()
+			stored, err := db.GetBeacons(ctx, test.Params)
+			require.NoError(t, err)
+			require.Len(t, stored, len(test.Expected))
+			for i, expected := range test.Expected {
+				id := string(expected.Segment.ID())
+				assert.Equal(t, id, string(stored[i].Segment.ID()), "Beacon %d", i)
+				assert.Equal(t, expected.InIfId, stored[i].InIfId, "Beacon %d", i)
+				assert.Equal(t, usages[id], stored[i].Usage, "Beacon %d", i)
+				assert.False(t, stored[i].LastUpdated.Before(start), "Beacon %d", i)
+			}
+		})
+	}
+}
+
 func testDeleteExpiredBeacons(t *testing.T, ctrl *gomock.Controller, db beacon.DBReadWrite) {
 	ts1 := uint32(10)
 	ts2 := uint32(20)
@@ -51,6 +51,11 @@ type DBRead interface {
 		<-chan BeaconOrErr, error)
 	// BeaconSources returns all source ISD-AS of the beacons in the database.
 	BeaconSources(ctx context.Context) ([]addr.IA, error)
+	// GetBeacons returns all beacons that match the query parameters,
+	// together with their stored usage and the time they were last updated.
+	// The beacons are ordered by start ISD-AS, ingress interface and segment
+	// length.
+	GetBeacons(ctx context.Context, params QueryParams) ([]StoredBeacon, error)
 	// AllRevocations returns all revocations in the database as a channel. The
 	// result channel either carries revocations or errors. The error can
 	// either be ErrReadingRows or ErrParse. After a ErrReadingRows occurs the
@@ -60,6 +65,28 @@ type DBRead interface {
 	AllRevocations(ctx context.Context) (<-chan RevocationOrErr, error)
 }
 
+// QueryParams restricts the beacons that are returned by GetBeacons. Fields
+// with the zero value do not restrict the result.
+type QueryParams struct {
+	// StartsAt restricts the result to beacons that are originated by the
+	// ISD-AS. The ISD and the AS can be wildcards.
+	StartsAt addr.IA
+	// IngressInterface restricts the result to beacons that are received on
+	// the interface.
+	IngressInterface common.IFIDType
+}
+
+// StoredBeacon is a beacon with the metadata that is stored alongside it in
+// the beacon DB.
+type StoredBeacon struct {
+	Beacon
+	// Usage is the usage of the beacon according to the policies at the time
+	// the beacon was inserted or last updated.
+	Usage Usage
+	// LastUpdated is the time the beacon was inserted or last updated.
+	LastUpdated time.Time
+}
+
 // InsertStats provides statistics about an insertion.
 type InsertStats struct {
 	Inserted, Updated, Filtered int
@@ -0,0 +1,28 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacon
+
+// SelectAndServe serves the beacons selected by the algorithm of the given
+// type. It allows testing the algorithms without a beacon store.
+func SelectAndServe(t AlgorithmType, beacons <-chan BeaconOrErr, results chan<- BeaconOrErr,
+	resultSize int) error {
+
+	algo, err := t.selectionAlgorithm()
+	if err != nil {
+		return err
+	}
+	algo.SelectAndServe(beacons, results, resultSize)
+	return nil
+}
@@ -213,6 +213,16 @@ func (e *executor) BeaconSources(ctx context.Context) ([]addr.IA, error) {
 	return ret, err
 }
 
+func (e *executor) GetBeacons(ctx context.Context, params QueryParams) ([]StoredBeacon, error) {
+	var ret []StoredBeacon
+	var err error
+	e.metrics.Observe(ctx, "get_beacons", func(ctx context.Context) error {
+		ret, err = e.db.GetBeacons(ctx, params)
+		return err
+	})
+	return ret, err
+}
+
 func (e *executor) AllRevocations(ctx context.Context) (<-chan RevocationOrErr, error) {
 	var ret <-chan RevocationOrErr
 	var err error
@@ -172,6 +172,21 @@ func (mr *MockDBMockRecorder) DeleteRevokedBeacons(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRevokedBeacons", reflect.TypeOf((*MockDB)(nil).DeleteRevokedBeacons), arg0, arg1)
 }
 
+// GetBeacons mocks base method
+func (m *MockDB) GetBeacons(arg0 context.Context, arg1 beacon.QueryParams) ([]beacon.StoredBeacon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeacons", arg0, arg1)
+	ret0, _ := ret[0].([]beacon.StoredBeacon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeacons indicates an expected call of GetBeacons
+func (mr *MockDBMockRecorder) GetBeacons(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeacons", reflect.TypeOf((*MockDB)(nil).GetBeacons), arg0, arg1)
+}
+
 // InsertBeacon mocks base method
 func (m *MockDB) InsertBeacon(arg0 context.Context, arg1 beacon.Beacon, arg2 beacon.Usage) (beacon.InsertStats, error) {
 	m.ctrl.T.Helper()
@@ -366,6 +381,21 @@ func (mr *MockTransactionMockRecorder) DeleteRevokedBeacons(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRevokedBeacons", reflect.TypeOf((*MockTransaction)(nil).DeleteRevokedBeacons), arg0, arg1)
 }
 
+// GetBeacons mocks base method
+func (m *MockTransaction) GetBeacons(arg0 context.Context, arg1 beacon.QueryParams) ([]beacon.StoredBeacon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeacons", arg0, arg1)
+	ret0, _ := ret[0].([]beacon.StoredBeacon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeacons indicates an expected call of GetBeacons
+func (mr *MockTransactionMockRecorder) GetBeacons(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeacons", reflect.TypeOf((*MockTransaction)(nil).GetBeacons), arg0, arg1)
+}
+
 // InsertBeacon mocks base method
 func (m *MockTransaction) InsertBeacon(arg0 context.Context, arg1 beacon.Beacon, arg2 beacon.Usage) (beacon.InsertStats, error) {
 	m.ctrl.T.Helper()
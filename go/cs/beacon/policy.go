@@ -16,13 +16,17 @@ package beacon
 
 import (
 	"io/ioutil"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/util"
 )
 
 // PolicyType is the policy type.
@@ -81,6 +85,11 @@ func (p *Policies) Validate() error {
 		return common.NewBasicError("Invalid policy type", nil,
 			"expected", DownRegPolicy, "actual", p.DownReg.Type)
 	}
+	for _, policy := range []*Policy{&p.Prop, &p.UpReg, &p.DownReg} {
+		if err := policy.Validate(); err != nil {
+			return common.NewBasicError("Invalid policy", err, "type", policy.Type)
+		}
+	}
 	return nil
 }
 
@@ -143,6 +152,11 @@ func (p *CorePolicies) Validate() error {
 		return common.NewBasicError("Invalid policy type", nil,
 			"expected", CoreRegPolicy, "actual", p.CoreReg.Type)
 	}
+	for _, policy := range []*Policy{&p.Prop, &p.CoreReg} {
+		if err := policy.Validate(); err != nil {
+			return common.NewBasicError("Invalid policy", err, "type", policy.Type)
+		}
+	}
 	return nil
 }
 
@@ -189,6 +203,9 @@ type Policy struct {
 	Filter Filter `yaml:"Filter"`
 	// Type is the policy type.
 	Type PolicyType `yaml:"Type"`
+	// Algorithm is the algorithm that selects the best beacons from the
+	// candidate beacons.
+	Algorithm AlgorithmType `yaml:"Algorithm"`
 }
 
 // InitDefaults initializes the default values for unset fields.
@@ -203,9 +220,20 @@ func (p *Policy) InitDefaults() {
 		m := DefaultMaxExpTime
 		p.MaxExpTime = &m
 	}
+	if p.Algorithm == "" {
+		p.Algorithm = DefaultAlgorithm
+	}
 	p.Filter.InitDefaults()
 }
 
+// Validate checks that the policy is valid.
+func (p *Policy) Validate() error {
+	if _, err := p.Algorithm.selectionAlgorithm(); err != nil {
+		return err
+	}
+	return p.Filter.Validate()
+}
+
 func (p *Policy) initDefaults(t PolicyType) error {
 	p.InitDefaults()
 	if p.Type != "" && p.Type != t {
@@ -213,7 +241,7 @@ func (p *Policy) initDefaults(t PolicyType) error {
 			"expected", t, "actual", p.Type)
 	}
 	p.Type = t
-	return nil
+	return p.Validate()
 }
 
 // ParsePolicyYaml parses the policy in yaml format and initializes the default values.
@@ -248,6 +276,30 @@ type Filter struct {
 	IsdBlackList []addr.ISD `yaml:"IsdBlackList"`
 	// AllowIsdLoop indicates whether ISD loops should not be filtered.
 	AllowIsdLoop *bool `yaml:"AllowIsdLoop"`
+	// AsAllowList contains all ASes that may appear in a segment. If it is
+	// empty, all ASes that are not blocked may appear.
+	AsAllowList []addr.AS `yaml:"AsAllowList"`
+	// IsdAllowList contains all ISDs that may appear in a segment. If it is
+	// empty, all ISDs that are not blocked may appear.
+	IsdAllowList []addr.ISD `yaml:"IsdAllowList"`
+	// IABlackList contains all ISD-ASes that may not appear in a segment. A
+	// wildcard ISD or AS matches any ISD or AS respectively.
+	IABlackList []addr.IA `yaml:"IABlackList"`
+	// IAAllowList contains all ISD-ASes that may appear in a segment. A
+	// wildcard ISD or AS matches any ISD or AS respectively. If it is empty,
+	// all ISD-ASes that are not blocked may appear.
+	IAAllowList []addr.IA `yaml:"IAAllowList"`
+	// MinExpiration is the minimum time until a segment expires. Segments that
+	// expire earlier are filtered.
+	MinExpiration util.DurWrap `yaml:"MinExpiration"`
+	// ACL is a path policy ACL that every interface of the segment must be
+	// allowed by. The segment is evaluated from the originating AS to the
+	// local AS, i.e., the last interface is the ingress interface of the
+	// beacon. This allows filtering on individual interfaces.
+	ACL *pathpol.ACL `yaml:"ACL"`
+	// Sequence is a path policy sequence that the segment must match. The
+	// segment is evaluated from the originating AS to the local AS.
+	Sequence *pathpol.Sequence `yaml:"Sequence"`
 }
 
 // InitDefaults initializes the default values for unset fields.
@@ -261,6 +313,16 @@ func (f *Filter) InitDefaults() {
 	}
 }
 
+// Validate checks that the filter is valid.
+func (f Filter) Validate() error {
+	if f.ACL != nil {
+		if _, err := pathpol.NewACL(f.ACL.Entries...); err != nil {
+			return serrors.WrapStr("invalid ACL", err)
+		}
+	}
+	return nil
+}
+
 // Apply returns an error if the beacon is filtered.
 func (f Filter) Apply(beacon Beacon) error {
 	if len(beacon.Segment.ASEntries) > f.MaxHopsLength {
@@ -282,10 +344,104 @@ func (f Filter) Apply(beacon Beacon) error {
 				return serrors.New("contains blocked ISD", "isd_as", ia)
 			}
 		}
+		for _, blocked := range f.IABlackList {
+			if matchIA(blocked, ia) {
+				return serrors.New("contains blocked ISD-AS", "isd_as", ia)
+			}
+		}
+		if len(f.AsAllowList) > 0 && !containsAS(f.AsAllowList, ia.A) {
+			return serrors.New("contains AS that is not allowed", "isd_as", ia)
+		}
+		if len(f.IsdAllowList) > 0 && !containsISD(f.IsdAllowList, ia.I) {
+			return serrors.New("contains ISD that is not allowed", "isd_as", ia)
+		}
+		if len(f.IAAllowList) > 0 && !containsIA(f.IAAllowList, ia) {
+			return serrors.New("contains ISD-AS that is not allowed", "isd_as", ia)
+		}
+	}
+	if f.MinExpiration.Duration > 0 {
+		if remaining := time.Until(beacon.Segment.MinExpiry()); remaining <
+			f.MinExpiration.Duration {
+
+			return serrors.New("expires too early", "min", f.MinExpiration,
+				"remaining", remaining)
+		}
+	}
+	if f.ACL == nil && f.Sequence == nil {
+		return nil
+	}
+	set := pathpol.PathSet{"": beaconPath(beacon)}
+	if len(f.ACL.Eval(set)) == 0 {
+		return serrors.New("denied by ACL")
+	}
+	if len(f.Sequence.Eval(set)) == 0 {
+		return serrors.New("does not match sequence", "sequence", f.Sequence)
 	}
 	return nil
 }
 
+// matchIA returns whether the ISD-AS matches the pattern. A wildcard ISD or
+// AS in the pattern matches any ISD or AS respectively.
+func matchIA(pattern, ia addr.IA) bool {
+	return (pattern.I == 0 || pattern.I == ia.I) && (pattern.A == 0 || pattern.A == ia.A)
+}
+
+func containsIA(patterns []addr.IA, ia addr.IA) bool {
+	for _, pattern := range patterns {
+		if matchIA(pattern, ia) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAS(ases []addr.AS, as addr.AS) bool {
+	for _, other := range ases {
+		if other == as {
+			return true
+		}
+	}
+	return false
+}
+
+func containsISD(isds []addr.ISD, isd addr.ISD) bool {
+	for _, other := range isds {
+		if other == isd {
+			return true
+		}
+	}
+	return false
+}
+
+// beaconPath is the path from the originating AS of the beacon to the local
+// AS. It is used to evaluate path policies on beacons.
+type beaconPath Beacon
+
+func (p beaconPath) Interfaces() []snet.PathInterface {
+	entries := p.Segment.ASEntries
+	ifaces := make([]snet.PathInterface, 0, 2*len(entries))
+	for _, entry := range entries {
+		hf := entry.HopEntry.HopField
+		for _, ifid := range []uint16{hf.ConsIngress, hf.ConsEgress} {
+			if ifid != 0 {
+				ifaces = append(ifaces, pathInterface{ia: entry.Local, id: common.IFIDType(ifid)})
+			}
+		}
+	}
+	if len(entries) > 0 && !entries[len(entries)-1].Next.IsZero() {
+		ifaces = append(ifaces, pathInterface{ia: entries[len(entries)-1].Next, id: p.InIfId})
+	}
+	return ifaces
+}
+
+type pathInterface struct {
+	ia addr.IA
+	id common.IFIDType
+}
+
+func (i pathInterface) IA() addr.IA         { return i.ia }
+func (i pathInterface) ID() common.IFIDType { return i.id }
+
 // FilterLoop returns an error if the beacon contains an AS or ISD loop. If ISD
 // loops are allowed, an error is returned only on AS loops.
 func FilterLoop(beacon Beacon, next addr.IA, allowIsdLoop bool) error {
@@ -16,13 +16,19 @@ package beacon_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/cs/beacon/beacondbtest"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/util"
 	"github.com/scionproto/scion/go/lib/xtest"
 )
 
@@ -76,10 +82,20 @@ func TestLoadPolicyFromYaml(t *testing.T) {
 			assert.Equal(t, []addr.AS{ia110.A, ia111.A}, p.Filter.AsBlackList)
 			assert.Equal(t, []addr.ISD{1, 2, 3}, p.Filter.IsdBlackList)
 			assert.True(t, *p.Filter.AllowIsdLoop)
+			assert.Equal(t, beacon.MaxDisjointAlgorithm, p.Algorithm)
 		})
 	}
 }
 
+func TestParsePolicyYamlAlgorithm(t *testing.T) {
+	p, err := beacon.ParsePolicyYaml([]byte("BestSetSize: 5"), beacon.PropPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, beacon.DefaultAlgorithm, p.Algorithm)
+
+	_, err = beacon.ParsePolicyYaml([]byte("Algorithm: Unknown"), beacon.PropPolicy)
+	assert.Error(t, err)
+}
+
 func TestFilterApply(t *testing.T) {
 	defaultFilter := &beacon.Filter{
 		MaxHopsLength: 2,
@@ -147,6 +163,55 @@ func TestFilterApply(t *testing.T) {
 			Filter:       &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val},
 			ErrAssertion: assert.NoError,
 		},
+		{
+			Name:   "Blacklisted ISD-AS [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IABlackList: []addr.IA{ia311}},
+			ErrAssertion: assert.Error,
+		},
+		{
+			Name:   "Same AS in other ISD not blacklisted [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IABlackList: []addr.IA{{I: 1, A: ia311.A}}},
+			ErrAssertion: assert.NoError,
+		},
+		{
+			Name:   "Blacklisted wildcard ISD-AS [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IABlackList: []addr.IA{{I: 3}}},
+			ErrAssertion: assert.Error,
+		},
+		{
+			Name:   "Allowed ISD-ASes [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IAAllowList: []addr.IA{ia110, {I: 3}}},
+			ErrAssertion: assert.NoError,
+		},
+		{
+			Name:   "ISD-AS not allowed [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IAAllowList: []addr.IA{ia110}},
+			ErrAssertion: assert.Error,
+		},
+		{
+			Name:   "AS not allowed [1-ff00:0:110, 1-ff00:0:111]",
+			Beacon: newTestBeacon(ia110, ia111),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				AsAllowList: []addr.AS{ia110.A}},
+			ErrAssertion: assert.Error,
+		},
+		{
+			Name:   "ISD not allowed [1-ff00:0:110, 3-ff00:0:311]",
+			Beacon: newTestBeacon(ia110, ia311),
+			Filter: &beacon.Filter{MaxHopsLength: 8, AllowIsdLoop: &true_val,
+				IsdAllowList: []addr.ISD{1, 2}},
+			ErrAssertion: assert.Error,
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.Name, func(t *testing.T) {
@@ -155,6 +220,99 @@ func TestFilterApply(t *testing.T) {
 	}
 }
 
+func TestFilterApplyPathPolicy(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	// The beacon traverses 1-ff00:0:330#5 1-ff00:0:331#2,3 1-ff00:0:332#1,7 and
+	// is received on interface 4 in 1-ff00:0:333.
+	b, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info3, 4,
+		uint32(time.Now().Unix()))
+
+	mustACL := func(entries ...string) *pathpol.ACL {
+		var acl pathpol.ACL
+		for _, entry := range entries {
+			var aclEntry pathpol.ACLEntry
+			require.NoError(t, aclEntry.LoadFromString(entry))
+			acl.Entries = append(acl.Entries, &aclEntry)
+		}
+		return &acl
+	}
+	mustSequence := func(seq string) *pathpol.Sequence {
+		s, err := pathpol.NewSequence(seq)
+		require.NoError(t, err)
+		return s
+	}
+	tests := map[string]struct {
+		Filter       beacon.Filter
+		ErrAssertion assert.ErrorAssertionFunc
+	}{
+		"no path policy": {
+			ErrAssertion: assert.NoError,
+		},
+		"ACL allows": {
+			Filter:       beacon.Filter{ACL: mustACL("- 1-ff00:0:331#4", "+")},
+			ErrAssertion: assert.NoError,
+		},
+		"ACL denies interface": {
+			Filter:       beacon.Filter{ACL: mustACL("- 1-ff00:0:331#3", "+")},
+			ErrAssertion: assert.Error,
+		},
+		"ACL denies ingress interface": {
+			Filter:       beacon.Filter{ACL: mustACL("- 1-ff00:0:333#4", "+")},
+			ErrAssertion: assert.Error,
+		},
+		"sequence matches": {
+			Filter:       beacon.Filter{Sequence: mustSequence("1-ff00:0:330 0* 1-ff00:0:333#4")},
+			ErrAssertion: assert.NoError,
+		},
+		"sequence does not match": {
+			Filter:       beacon.Filter{Sequence: mustSequence("1-ff00:0:330 1-ff00:0:333")},
+			ErrAssertion: assert.Error,
+		},
+		"expires late enough": {
+			Filter:       beacon.Filter{MinExpiration: util.DurWrap{Duration: time.Minute}},
+			ErrAssertion: assert.NoError,
+		},
+		"expires too early": {
+			Filter:       beacon.Filter{MinExpiration: util.DurWrap{Duration: 24 * time.Hour}},
+			ErrAssertion: assert.Error,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.Filter.InitDefaults()
+			test.ErrAssertion(t, test.Filter.Apply(b))
+		})
+	}
+}
+
+func TestParsePolicyYamlFilter(t *testing.T) {
+	raw := `
+Filter:
+  IAAllowList: ["1-0", "2-ff00:0:210"]
+  MinExpiration: 1h
+  ACL:
+    - "- 1-ff00:0:110#2"
+    - "+"
+  Sequence: "1-ff00:0:110 0*"
+`
+	p, err := beacon.ParsePolicyYaml([]byte(raw), beacon.PropPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, []addr.IA{{I: 1}, ia210}, p.Filter.IAAllowList)
+	assert.Equal(t, time.Hour, p.Filter.MinExpiration.Duration)
+	assert.Len(t, p.Filter.ACL.Entries, 2)
+	assert.Equal(t, "1-ff00:0:110 0*", p.Filter.Sequence.String())
+
+	// ACL without default entry.
+	raw = `
+Filter:
+  ACL:
+    - "- 1-ff00:0:110#2"
+`
+	_, err = beacon.ParsePolicyYaml([]byte(raw), beacon.PropPolicy)
+	assert.Error(t, err)
+}
+
 func TestFilterLoop(t *testing.T) {
 	testCases := []struct {
 		Name         string
@@ -14,7 +14,41 @@
 
 package beacon
 
-import "math"
+import (
+	"math"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// AlgorithmType is the type of the algorithm that selects the best beacons
+// from the candidate beacons.
+type AlgorithmType string
+
+const (
+	// ShortestDiverseAlgorithm selects the shortest beacons, but also tries to
+	// achieve some path diversity.
+	ShortestDiverseAlgorithm AlgorithmType = "ShortestDiverse"
+	// MaxDisjointAlgorithm selects the beacons that are as link disjoint as
+	// possible.
+	MaxDisjointAlgorithm AlgorithmType = "MaxDisjoint"
+)
+
+// DefaultAlgorithm is the default selection algorithm.
+const DefaultAlgorithm = ShortestDiverseAlgorithm
+
+// selectionAlgorithm returns the selection algorithm of the type.
+func (t AlgorithmType) selectionAlgorithm() (selectionAlgorithm, error) {
+	switch t {
+	case ShortestDiverseAlgorithm:
+		return baseAlgo{}, nil
+	case MaxDisjointAlgorithm:
+		return maxDisjointAlgo{}, nil
+	default:
+		return nil, serrors.New("unknown selection algorithm", "algorithm", t)
+	}
+}
 
 type selectionAlgorithm interface {
 	// SelectAndServe selects the n best beacons from the beacons channel and
@@ -110,6 +144,57 @@ func (baseAlgo) serveMostDiverse(beacons <-chan BeaconOrErr, results chan<- Beac
 	results <- BeaconOrErr{Beacon: first}
 }
 
+// maxDisjointAlgo implements a selection algorithm that optimizes for link
+// disjointness across all selected beacons.
+type maxDisjointAlgo struct{}
+
+// SelectAndServe greedily selects resultSize beacons. The first beacon is the
+// shortest one. Each subsequent beacon is the one with the most links that do
+// not appear in any of the already selected beacons. Ties are broken in favor
+// of shorter beacons, and then in favor of the beacons that were received
+// earlier from the beacons channel. Errors are served as they are encountered.
+func (maxDisjointAlgo) SelectAndServe(beacons <-chan BeaconOrErr, results chan<- BeaconOrErr,
+	resultSize int) {
+
+	var candidates []Beacon
+	for res := range beacons {
+		if res.Err != nil {
+			results <- res
+			continue
+		}
+		candidates = append(candidates, res.Beacon)
+	}
+	type beaconLink struct {
+		ia   addr.IA
+		ifid common.IFIDType
+	}
+	used := make(map[beaconLink]struct{})
+	for served := 0; served < resultSize && len(candidates) > 0; served++ {
+		best, bestNew := -1, -1
+		for i, c := range candidates {
+			// Without selected beacons, only the length matters.
+			var newLinks int
+			for _, entry := range c.Segment.ASEntries {
+				ia, ifid := link(entry)
+				if _, ok := used[beaconLink{ia: ia, ifid: ifid}]; !ok && served > 0 {
+					newLinks++
+				}
+			}
+			if newLinks > bestNew || (newLinks == bestNew &&
+				len(c.Segment.ASEntries) < len(candidates[best].Segment.ASEntries)) {
+
+				best, bestNew = i, newLinks
+			}
+		}
+		for _, entry := range candidates[best].Segment.ASEntries {
+			ia, ifid := link(entry)
+			used[beaconLink{ia: ia, ifid: ifid}] = struct{}{}
+		}
+		results <- BeaconOrErr{Beacon: candidates[best]}
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,154 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/cs/beacon/beacondbtest"
+	"github.com/scionproto/scion/go/cs/beacon/mock_beacon"
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+func TestSelectionAlgorithms(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	// Links: 330#0 331#1
+	short, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info2, 1, 1)
+	// Links: 330#0 331#2 332#1
+	long, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info3, 1, 1)
+	// Same links as long.
+	longDup, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info3, 1, 2)
+	// Links: 330#0 331#3 332#2
+	info := append([]beacondbtest.IfInfo{}, beacondbtest.Info3...)
+	info[1].Ingress, info[2].Ingress = 3, 2
+	longDisjoint, _ := beacondbtest.AllocBeacon(t, mctrl, info, 1, 1)
+
+	candidates := []beacon.Beacon{short, long, longDup, longDisjoint}
+	beaconErr := beacon.BeaconOrErr{Err: errors.New("fail")}
+	tests := map[string]struct {
+		Algorithm beacon.AlgorithmType
+		BestSize  int
+		Results   []beacon.BeaconOrErr
+		Expected  []beacon.Beacon
+		ExpectErr bool
+	}{
+		"shortest diverse": {
+			Algorithm: beacon.ShortestDiverseAlgorithm,
+			BestSize:  3,
+			Results:   beaconsOrErrs(candidates...),
+			Expected:  []beacon.Beacon{short, long, longDup},
+		},
+		"max disjoint": {
+			Algorithm: beacon.MaxDisjointAlgorithm,
+			BestSize:  3,
+			Results:   beaconsOrErrs(candidates...),
+			Expected:  []beacon.Beacon{short, long, longDisjoint},
+		},
+		"max disjoint with error": {
+			Algorithm: beacon.MaxDisjointAlgorithm,
+			BestSize:  2,
+			Results: append([]beacon.BeaconOrErr{beaconErr},
+				beaconsOrErrs(long, longDup, longDisjoint)...),
+			Expected:  []beacon.Beacon{long, longDisjoint},
+			ExpectErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			beacons := make(chan beacon.BeaconOrErr, len(test.Results))
+			for _, res := range test.Results {
+				beacons <- res
+			}
+			close(beacons)
+			results := make(chan beacon.BeaconOrErr, len(test.Results))
+			err := beacon.SelectAndServe(test.Algorithm, beacons, results, test.BestSize)
+			require.NoError(t, err)
+			close(results)
+			var served []beacon.Beacon
+			var errs int
+			for bOrErr := range results {
+				if bOrErr.Err != nil {
+					errs++
+					continue
+				}
+				served = append(served, bOrErr.Beacon)
+			}
+			assert.Equal(t, test.Expected, served)
+			assert.Equal(t, test.ExpectErr, errs > 0)
+		})
+	}
+}
+
+func TestNewBeaconStoreShortestDiverse(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	// Links: 330#0 331#1
+	short, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info2, 1, 1)
+	long, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info3, 1, 1)
+	db := mock_beacon.NewMockDB(mctrl)
+	policy := beacon.Policy{BestSetSize: 2, Algorithm: beacon.ShortestDiverseAlgorithm}
+	store, err := beacon.NewBeaconStore(beacon.Policies{
+		Prop:    policy,
+		UpReg:   policy,
+		DownReg: policy,
+	}, db)
+	require.NoError(t, err)
+	db.EXPECT().CandidateBeacons(gomock.Any(), gomock.Any(), gomock.Any(),
+		addr.IA{}).DoAndReturn(
+		func(_ ...interface{}) (<-chan beacon.BeaconOrErr, error) {
+			results := make(chan beacon.BeaconOrErr, 2)
+			defer close(results)
+			results <- beacon.BeaconOrErr{Beacon: short}
+			results <- beacon.BeaconOrErr{Beacon: long}
+			return results, nil
+		},
+	)
+	res, err := store.BeaconsToPropagate(context.Background())
+	require.NoError(t, err)
+	var served []beacon.Beacon
+	for bOrErr := range res {
+		require.NoError(t, bOrErr.Err)
+		served = append(served, bOrErr.Beacon)
+	}
+	assert.Equal(t, []beacon.Beacon{short, long}, served)
+}
+
+func TestNewBeaconStoreUnknownAlgorithm(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	policies := beacon.Policies{
+		Prop: beacon.Policy{Algorithm: "unknown"},
+	}
+	_, err := beacon.NewBeaconStore(policies, mock_beacon.NewMockDB(mctrl))
+	assert.Error(t, err)
+}
+
+func beaconsOrErrs(beacons ...beacon.Beacon) []beacon.BeaconOrErr {
+	results := make([]beacon.BeaconOrErr, 0, len(beacons))
+	for _, b := range beacons {
+		results = append(results, beacon.BeaconOrErr{Beacon: b})
+	}
+	return results
+}
@@ -24,7 +24,6 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
 	"github.com/scionproto/scion/go/lib/log"
-	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/spath"
 )
 
@@ -41,7 +40,7 @@ type usager interface {
 // core AS.
 type Store struct {
 	baseStore
-	policies Policies
+	policies *storePolicies
 }
 
 // NewBeaconStore creates a new beacon store for a non-core AS.
@@ -52,12 +51,11 @@ func NewBeaconStore(policies Policies, db DB) (*Store, error) {
 	}
 	s := &Store{
 		baseStore: baseStore{
-			db:   db,
-			algo: baseAlgo{},
+			db: db,
 		},
-		policies: policies,
+		policies: &storePolicies{policies: policies},
 	}
-	s.baseStore.usager = &s.policies
+	s.baseStore.usager = s.policies
 	return s, nil
 }
 
@@ -65,7 +63,7 @@ func NewBeaconStore(policies Policies, db DB) (*Store, error) {
 // at the time of the call. The selection is based on the configured propagation
 // policy.
 func (s *Store) BeaconsToPropagate(ctx context.Context) (<-chan BeaconOrErr, error) {
-	return s.getBeacons(ctx, &s.policies.Prop)
+	return s.getBeacons(ctx, s.policies.get().Prop)
 }
 
 // SegmentsToRegister returns a channel that provides all beacons to register at
@@ -74,11 +72,12 @@ func (s *Store) BeaconsToPropagate(ctx context.Context) (<-chan BeaconOrErr, err
 func (s *Store) SegmentsToRegister(ctx context.Context, segType seg.Type) (
 	<-chan BeaconOrErr, error) {
 
+	policies := s.policies.get()
 	switch segType {
 	case seg.TypeDown:
-		return s.getBeacons(ctx, &s.policies.DownReg)
+		return s.getBeacons(ctx, policies.DownReg)
 	case seg.TypeUp:
-		return s.getBeacons(ctx, &s.policies.UpReg)
+		return s.getBeacons(ctx, policies.UpReg)
 	default:
 		return nil, common.NewBasicError("Unsupported segment type", nil, "type", segType)
 	}
@@ -86,7 +85,11 @@ func (s *Store) SegmentsToRegister(ctx context.Context, segType seg.Type) (
 
 // getBeacons fetches the candidate beacons from the database and serves the
 // best beacons according to the policy.
-func (s *Store) getBeacons(ctx context.Context, policy *Policy) (<-chan BeaconOrErr, error) {
+func (s *Store) getBeacons(ctx context.Context, policy Policy) (<-chan BeaconOrErr, error) {
+	algo, err := policy.Algorithm.selectionAlgorithm()
+	if err != nil {
+		return nil, err
+	}
 	beacons, err := s.db.CandidateBeacons(ctx, policy.CandidateSetSize,
 		UsageFromPolicyType(policy.Type), addr.IA{})
 	if err != nil {
@@ -96,31 +99,54 @@ func (s *Store) getBeacons(ctx context.Context, policy *Policy) (<-chan BeaconOr
 	go func() {
 		defer log.HandlePanic()
 		defer close(results)
-		s.algo.SelectAndServe(beacons, results, policy.BestSetSize)
+		algo.SelectAndServe(filterBeacons(beacons, policy.Filter), results, policy.BestSetSize)
 	}()
 	return results, nil
 }
 
 // MaxExpTime returns the segment maximum expiration time for the given policy.
 func (s *Store) MaxExpTime(policyType PolicyType) spath.ExpTimeType {
+	policies := s.policies.get()
 	switch policyType {
 	case UpRegPolicy:
-		return *s.policies.UpReg.MaxExpTime
+		return *policies.UpReg.MaxExpTime
 	case DownRegPolicy:
-		return *s.policies.DownReg.MaxExpTime
+		return *policies.DownReg.MaxExpTime
 	case PropPolicy:
-		return *s.policies.Prop.MaxExpTime
+		return *policies.Prop.MaxExpTime
 	}
 	return DefaultMaxExpTime
 }
 
+// UpdatePolicies validates the policies and replaces all policies of the
+// store. If any of the policies is invalid, the store keeps the previous
+// policies. The update is atomic, all beacons that are selected after the
+// update are selected according to the new policies. The usage of the beacons
+// in the store is not recomputed. Instead, they are filtered with the new
+// policies when they are selected. Beacons that are allowed by the new
+// policies, but were filtered by the old ones, are only available once they
+// are received again.
+func (s *Store) UpdatePolicies(ctx context.Context, policies Policies) error {
+	policies.InitDefaults()
+	if err := policies.Validate(); err != nil {
+		return err
+	}
+	s.policies.set(policies)
+	return nil
+}
+
+// Policies returns the policies that are currently used by the store.
+func (s *Store) Policies() Policies {
+	return s.policies.get()
+}
+
 // CoreStore provides abstracted access to the beacon database in a core AS. The
 // store helps inserting beacons and revocations, and selects the best beacons
 // for given purposes based on the configured policies. It should not be used in
 // a non-core AS.
 type CoreStore struct {
 	baseStore
-	policies CorePolicies
+	policies *coreStorePolicies
 }
 
 // NewCoreBeaconStore creates a new beacon store for a non-core AS.
@@ -131,12 +157,11 @@ func NewCoreBeaconStore(policies CorePolicies, db DB) (*CoreStore, error) {
 	}
 	s := &CoreStore{
 		baseStore: baseStore{
-			db:   db,
-			algo: baseAlgo{},
+			db: db,
 		},
-		policies: policies,
+		policies: &coreStorePolicies{policies: policies},
 	}
-	s.usager = &s.policies
+	s.usager = s.policies
 	return s, nil
 }
 
@@ -144,7 +169,7 @@ func NewCoreBeaconStore(policies CorePolicies, db DB) (*CoreStore, error) {
 // at the time of the call. The selection is based on the configured propagation
 // policy.
 func (s *CoreStore) BeaconsToPropagate(ctx context.Context) (<-chan BeaconOrErr, error) {
-	return s.getBeacons(ctx, &s.policies.Prop)
+	return s.getBeacons(ctx, s.policies.get().Prop)
 }
 
 // SegmentsToRegister returns a channel that provides all beacons to register at
@@ -156,12 +181,16 @@ func (s *CoreStore) SegmentsToRegister(ctx context.Context, segType seg.Type) (
 	if segType != seg.TypeCore {
 		return nil, common.NewBasicError("Unsupported segment type", nil, "type", segType)
 	}
-	return s.getBeacons(ctx, &s.policies.CoreReg)
+	return s.getBeacons(ctx, s.policies.get().CoreReg)
 }
 
 // getBeacons fetches the candidate beacons from the database and serves the
 // best beacons according to the policy.
-func (s *CoreStore) getBeacons(ctx context.Context, policy *Policy) (<-chan BeaconOrErr, error) {
+func (s *CoreStore) getBeacons(ctx context.Context, policy Policy) (<-chan BeaconOrErr, error) {
+	algo, err := policy.Algorithm.selectionAlgorithm()
+	if err != nil {
+		return nil, err
+	}
 	srcs, err := s.db.BeaconSources(ctx)
 	if err != nil {
 		return nil, err
@@ -183,7 +212,8 @@ func (s *CoreStore) getBeacons(ctx context.Context, policy *Policy) (<-chan Beac
 		go func() {
 			defer log.HandlePanic()
 			defer wg.Done()
-			s.algo.SelectAndServe(beacons, results, policy.BestSetSize)
+			algo.SelectAndServe(filterBeacons(beacons, policy.Filter), results,
+				policy.BestSetSize)
 		}()
 	}
 	go func() {
@@ -201,20 +231,38 @@ func (s *CoreStore) getBeacons(ctx context.Context, policy *Policy) (<-chan Beac
 
 // MaxExpTime returns the segment maximum expiration time for the given policy.
 func (s *CoreStore) MaxExpTime(policyType PolicyType) spath.ExpTimeType {
+	policies := s.policies.get()
 	switch policyType {
 	case CoreRegPolicy:
-		return *s.policies.CoreReg.MaxExpTime
+		return *policies.CoreReg.MaxExpTime
 	case PropPolicy:
-		return *s.policies.Prop.MaxExpTime
+		return *policies.Prop.MaxExpTime
 	}
 	return DefaultMaxExpTime
 }
 
+// UpdatePolicies validates the policies and replaces all policies of the
+// store. If any of the policies is invalid, the store keeps the previous
+// policies. The update is atomic, see Store.UpdatePolicies for how the new
+// policies are applied to the beacons in the store.
+func (s *CoreStore) UpdatePolicies(ctx context.Context, policies CorePolicies) error {
+	policies.InitDefaults()
+	if err := policies.Validate(); err != nil {
+		return err
+	}
+	s.policies.set(policies)
+	return nil
+}
+
+// Policies returns the policies that are currently used by the store.
+func (s *CoreStore) Policies() CorePolicies {
+	return s.policies.get()
+}
+
 // baseStore is the basis for the beacon store.
 type baseStore struct {
 	db     DB
 	usager usager
-	algo   selectionAlgorithm
 }
 
 // PreFilter indicates whether the beacon will be filtered on insert by
@@ -271,17 +319,87 @@ func (s *baseStore) DeleteExpiredRevocations(ctx context.Context) (int, error) {
 	return s.db.DeleteExpiredRevocations(ctx, time.Now())
 }
 
-// UpdatePolicy updates the policy. Beacons that are filtered by all
-// policies after the update are removed.
-func (s *baseStore) UpdatePolicy(ctx context.Context, policy Policy) error {
-	return serrors.New("policy update not supported")
-}
-
 // Close closes the store and the underlying database connection.
 func (s *baseStore) Close() error {
 	return s.db.Close()
 }
 
+// filterBeacons drops the beacons that are filtered by the filter. The usage
+// that is stored with the beacons reflects the policies at insertion time,
+// which might have been updated since.
+func filterBeacons(beacons <-chan BeaconOrErr, filter Filter) <-chan BeaconOrErr {
+	filtered := make(chan BeaconOrErr)
+	go func() {
+		defer log.HandlePanic()
+		defer close(filtered)
+		for res := range beacons {
+			if res.Err == nil && filter.Apply(res.Beacon) != nil {
+				continue
+			}
+			filtered <- res
+		}
+	}()
+	return filtered
+}
+
+// storePolicies guards the policies of a non-core store, such that they can be
+// updated while the store is in use.
+type storePolicies struct {
+	mtx      sync.RWMutex
+	policies Policies
+}
+
+func (p *storePolicies) get() Policies {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.policies
+}
+
+func (p *storePolicies) set(policies Policies) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.policies = policies
+}
+
+func (p *storePolicies) Filter(beacon Beacon) error {
+	policies := p.get()
+	return policies.Filter(beacon)
+}
+
+func (p *storePolicies) Usage(beacon Beacon) Usage {
+	policies := p.get()
+	return policies.Usage(beacon)
+}
+
+// coreStorePolicies guards the policies of a core store, such that they can be
+// updated while the store is in use.
+type coreStorePolicies struct {
+	mtx      sync.RWMutex
+	policies CorePolicies
+}
+
+func (p *coreStorePolicies) get() CorePolicies {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.policies
+}
+
+func (p *coreStorePolicies) set(policies CorePolicies) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.policies = policies
+}
+
+func (p *coreStorePolicies) Filter(beacon Beacon) error {
+	policies := p.get()
+	return policies.Filter(beacon)
+}
+
+func (p *coreStorePolicies) Usage(beacon Beacon) Usage {
+	policies := p.get()
+	return policies.Usage(beacon)
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -368,6 +368,98 @@ func testCoreStoreSelection(t *testing.T,
 	}
 }
 
+func TestStoreUpdatePolicies(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	g := graph.NewDefaultGraph(mctrl)
+
+	stub := graph.If_111_A_112_X
+	short := testBeaconOrErr(g, graph.If_120_X_111_B, stub)
+	long := testBeaconOrErr(g, graph.If_130_B_120_A, graph.If_120_X_111_B, stub)
+
+	db := mock_beacon.NewMockDB(mctrl)
+	policies := beacon.Policies{
+		Prop:    beacon.Policy{BestSetSize: 5},
+		UpReg:   beacon.Policy{BestSetSize: 5},
+		DownReg: beacon.Policy{BestSetSize: 5},
+	}
+	store, err := beacon.NewBeaconStore(policies, db)
+	require.NoError(t, err)
+	db.EXPECT().CandidateBeacons(gomock.Any(), gomock.Any(), gomock.Any(),
+		addr.IA{}).DoAndReturn(
+		func(_ ...interface{}) (<-chan beacon.BeaconOrErr, error) {
+			results := make(chan beacon.BeaconOrErr, 2)
+			defer close(results)
+			results <- short
+			results <- long
+			return results, nil
+		},
+	).AnyTimes()
+	propagated := func() []beacon.BeaconOrErr {
+		res, err := store.BeaconsToPropagate(context.Background())
+		require.NoError(t, err)
+		var served []beacon.BeaconOrErr
+		for bOrErr := range res {
+			served = append(served, bOrErr)
+		}
+		return served
+	}
+	require.ElementsMatch(t, []beacon.BeaconOrErr{short, long}, propagated())
+
+	// Beacons that are filtered by the new policies are not selected anymore.
+	update := beacon.Policies{
+		Prop: beacon.Policy{
+			BestSetSize: 5,
+			Filter: beacon.Filter{
+				IABlackList: []addr.IA{xtest.MustParseIA("1-ff00:0:130")},
+			},
+		},
+		UpReg:   beacon.Policy{BestSetSize: 4},
+		DownReg: beacon.Policy{BestSetSize: 3},
+	}
+	require.NoError(t, store.UpdatePolicies(context.Background(), update))
+	require.Equal(t, []beacon.BeaconOrErr{short}, propagated())
+	current := store.Policies()
+	require.Equal(t, update.Prop.Filter.IABlackList, current.Prop.Filter.IABlackList)
+	require.Equal(t, 4, current.UpReg.BestSetSize)
+	require.Equal(t, 3, current.DownReg.BestSetSize)
+
+	// If any of the policies is invalid, none of them is applied and the
+	// previous policies are kept.
+	invalid := beacon.Policies{
+		Prop:    beacon.Policy{BestSetSize: 2},
+		UpReg:   beacon.Policy{BestSetSize: 2},
+		DownReg: beacon.Policy{BestSetSize: 2, Algorithm: "unknown"},
+	}
+	require.Error(t, store.UpdatePolicies(context.Background(), invalid))
+	require.Equal(t, current, store.Policies())
+	require.Equal(t, []beacon.BeaconOrErr{short}, propagated())
+}
+
+func TestCoreStoreUpdatePolicies(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	db := mock_beacon.NewMockDB(mctrl)
+	store, err := beacon.NewCoreBeaconStore(beacon.CorePolicies{}, db)
+	require.NoError(t, err)
+
+	update := beacon.CorePolicies{
+		Prop:    beacon.Policy{BestSetSize: 4},
+		CoreReg: beacon.Policy{BestSetSize: 3},
+	}
+	require.NoError(t, store.UpdatePolicies(context.Background(), update))
+	current := store.Policies()
+	require.Equal(t, 4, current.Prop.BestSetSize)
+	require.Equal(t, 3, current.CoreReg.BestSetSize)
+
+	invalid := beacon.CorePolicies{
+		Prop:    beacon.Policy{BestSetSize: 2},
+		CoreReg: beacon.Policy{Type: beacon.UpRegPolicy},
+	}
+	require.Error(t, store.UpdatePolicies(context.Background(), invalid))
+	require.Equal(t, current, store.Policies())
+}
+
 func testBeaconOrErr(g *graph.Graph, desc ...common.IFIDType) beacon.BeaconOrErr {
 	pseg := testBeacon(g, desc)
 	asEntry := pseg.ASEntries[pseg.MaxIdx()]
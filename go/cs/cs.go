@@ -65,6 +65,7 @@ import (
 	cstrustmetrics "github.com/scionproto/scion/go/pkg/cs/trust/metrics"
 	libgrpc "github.com/scionproto/scion/go/pkg/grpc"
 	cppb "github.com/scionproto/scion/go/pkg/proto/control_plane"
+	"github.com/scionproto/scion/go/pkg/service"
 	"github.com/scionproto/scion/go/pkg/storage"
 	"github.com/scionproto/scion/go/pkg/trust"
 	"github.com/scionproto/scion/go/pkg/trust/compat"
@@ -118,7 +119,15 @@ func run(file string) error {
 	// approach.
 	metrics.InitBSMetrics()
 	metrics.InitPSMetrics()
-	intfs, err := setup(&cfg)
+	// The beaconing policies are reloaded on SIGHUP once the beacon store is
+	// initialized. Reload requests that arrive during a reload are coalesced.
+	policyReloads := make(chan struct{}, 1)
+	intfs, err := setup(&cfg, func() {
+		select {
+		case policyReloads <- struct{}{}:
+		default:
+		}
+	})
 	if err != nil {
 		return err
 	}
@@ -181,11 +190,27 @@ func run(file string) error {
 		return err
 	}
 
-	beaconStore, isdLoopAllowed, err := loadBeaconStore(topo.Core(), topo.IA(), cfg)
+	beaconDB, err := storage.NewBeaconStorage(cfg.BeaconDB, topo.IA())
+	if err != nil {
+		return serrors.WrapStr("initializing beacon storage", err)
+	}
+	beaconDB = beacon.DBWithMetrics(string(storage.BackendSqlite), beaconDB)
+	beaconStore, isdLoopAllowed, err := loadBeaconStore(topo.Core(), beaconDB, cfg)
 	if err != nil {
 		return serrors.WrapStr("initializing beacon store", err)
 	}
 	defer beaconStore.Close()
+	policyReloader := &cs.PolicyReloader{
+		Policies: cfg.BS.Policies,
+		Store:    beaconStore,
+		Reloads:  libmetrics.NewPromCounter(metrics.Beaconing.PolicyReloads),
+	}
+	go func() {
+		defer log.HandlePanic()
+		for range policyReloads {
+			policyReloader.Reload(context.Background())
+		}
+	}()
 
 	inspector := trust.DBInspector{DB: trustDB}
 	provider := trust.FetchingProvider{
@@ -395,7 +420,15 @@ func run(file string) error {
 		quicStack.Legacy.ListenAndServe()
 	}()
 	defer quicStack.Legacy.CloseServer()
-	err = cs.StartHTTPEndpoints(cfg.General.ID, cfg, signer, chainBuilder, cfg.Metrics)
+	err = cs.StartHTTPEndpoints(cfg.General.ID, cfg, signer, chainBuilder, cfg.Metrics,
+		service.StatusPages{
+			"beaconing/policies/reload": policyReloader.ServeHTTP,
+			"beacons": cs.BeaconsHandler{
+				DB:       beaconDB,
+				Policies: beaconingPolicies(beaconStore),
+			}.ServeHTTP,
+		},
+	)
 	if err != nil {
 		return serrors.WrapStr("registering status pages", err)
 	}
@@ -506,7 +539,7 @@ func setupBasic(file string) (config.Config, error) {
 	return cfg, nil
 }
 
-func setup(cfg *config.Config) (*ifstate.Interfaces, error) {
+func setup(cfg *config.Config, onReload func()) (*ifstate.Interfaces, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, serrors.WrapStr("validating config", err)
 	}
@@ -528,16 +561,11 @@ func setup(cfg *config.Config) (*ifstate.Interfaces, error) {
 	if err := itopo.Update(topo); err != nil {
 		return nil, serrors.WrapStr("setting initial static topology", err)
 	}
-	infraenv.InitInfraEnvironment(cfg.General.Topology())
+	infraenv.InitInfraEnvironmentFunc(cfg.General.Topology(), onReload)
 	return intfs, nil
 }
 
-func loadBeaconStore(core bool, ia addr.IA, cfg config.Config) (cs.Store, bool, error) {
-	db, err := storage.NewBeaconStorage(cfg.BeaconDB, ia)
-	if err != nil {
-		return nil, false, err
-	}
-	db = beacon.DBWithMetrics(string(storage.BackendSqlite), db)
+func loadBeaconStore(core bool, db beacon.DB, cfg config.Config) (cs.Store, bool, error) {
 	if core {
 		policies, err := cs.LoadCorePolicies(cfg.BS.Policies)
 		if err != nil {
@@ -553,3 +581,22 @@ func loadBeaconStore(core bool, ia addr.IA, cfg config.Config) (cs.Store, bool,
 	store, err := beacon.NewBeaconStore(policies, db)
 	return store, *policies.Prop.Filter.AllowIsdLoop, err
 }
+
+// beaconingPolicies returns a function that returns the beaconing policies
+// that are currently used by the store.
+func beaconingPolicies(store cs.Store) func() []beacon.Policy {
+	switch s := store.(type) {
+	case *beacon.CoreStore:
+		return func() []beacon.Policy {
+			policies := s.Policies()
+			return []beacon.Policy{policies.Prop, policies.CoreReg}
+		}
+	case *beacon.Store:
+		return func() []beacon.Policy {
+			policies := s.Policies()
+			return []beacon.Policy{policies.Prop, policies.UpReg, policies.DownReg}
+		}
+	default:
+		return nil
+	}
+}
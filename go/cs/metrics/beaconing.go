@@ -46,8 +46,24 @@ func (l BeaconingLabels) WithResult(result string) BeaconingLabels {
 	return l
 }
 
+// PolicyReloadLabels contains the labels for the policy reload metric.
+type PolicyReloadLabels struct {
+	Result string
+}
+
+// Labels returns the name of the labels in correct order.
+func (l PolicyReloadLabels) Labels() []string {
+	return []string{prom.LabelResult}
+}
+
+// Values returns the values of the label in correct order.
+func (l PolicyReloadLabels) Values() []string {
+	return []string{l.Result}
+}
+
 type beaconing struct {
 	BeaconsReceived *prometheus.CounterVec
+	PolicyReloads   *prometheus.CounterVec
 }
 
 func newBeaconing() beaconing {
@@ -55,6 +71,8 @@ func newBeaconing() beaconing {
 	return beaconing{
 		BeaconsReceived: prom.NewCounterVecWithLabels(ns, sub, "received_beacons_total",
 			"Total number of received beacons.", BeaconingLabels{}),
+		PolicyReloads: prom.NewCounterVecWithLabels(ns, sub, "policy_reloads_total",
+			"Total number of beaconing policy reloads.", PolicyReloadLabels{}),
 	}
 }
 
@@ -196,3 +196,7 @@ func (m pathMetadata) MTU() uint16 {
 func (m pathMetadata) Expiry() time.Time {
 	return m.expiry
 }
+
+func (m pathMetadata) Hops() []snet.HopMetadata {
+	return nil
+}
@@ -0,0 +1,306 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/integration/braccept_v2/runner"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+// ChildToParentIPv6 tests transit traffic that enters the BR over a child
+// link with an IPv6 underlay and leaves over a parent link with an IPv4
+// underlay.
+func ChildToParentIPv6(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:11 EthernetType=IPv6
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x11},
+		EthernetType: layers.EthernetTypeIPv6,
+	}
+	// IP6: Src=fd00:f00d:cafe::11:3 Dst=fd00:f00d:cafe::11:2 NextHdr=UDP
+	ip6 := &layers.IPv6{
+		Version:    6,
+		HopLimit:   64,
+		SrcIP:      net.ParseIP("fd00:f00d:cafe::11:3"),
+		DstIP:      net.ParseIP("fd00:f00d:cafe::11:2"),
+		NextHeader: layers.IPProtocolUDP,
+	}
+	// UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	udp.SetNetworkLayerForChecksum(ip6)
+
+	// pkt0.ParsePacket(`
+	//	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4
+	//		ADDR: SrcIA=1-ff00:0:6 Src=172.16.6.1 DstIA=1-ff00:0:3 Dst=174.16.3.1
+	//		IF_1: ISD=1 Hops=3
+	//			HF_1: ConsIngress=311 ConsEgress=0
+	//			HF_2: ConsIngress=131 ConsEgress=111
+	//			HF_3: ConsIngress=0 ConsEgress=611
+	//	UDP_1: Src=40111 Dst=40222
+	// `)
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   false,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 311, ConsEgress: 0},
+			{ConsIngress: 131, ConsEgress: 111},
+			{ConsIngress: 0, ConsEgress: 611},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      common.L4UDP,
+		PathType:     slayers.PathTypeSCION,
+		SrcIA:        xtest.MustParseIA("1-ff00:0:6"),
+		DstIA:        xtest.MustParseIA("1-ff00:0:3"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("172.16.6.1")}); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(&net.IPAddr{IP: net.ParseIP("174.16.3.1")}); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = layers.UDPPort(40111)
+	scionudp.DstPort = layers.UDPPort(40222)
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip6, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:13 DstMAC=f0:0d:ca:fe:be:ef EthernetType=IPv4
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ethernet.EthernetType = layers.EthernetTypeIPv4
+	// IP4: Src=192.168.13.2 Dst=192.168.13.3 NextHdr=UDP Flags=DF
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 2},
+		DstIP:    net.IP{192, 168, 13, 3},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	// UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	udp.SetNetworkLayerForChecksum(ip)
+	// SCION: CurrHopF=2
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ChildToParentIPv6",
+		WriteTo:  "veth_111_host",
+		ReadFrom: "veth_131_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ChildToParentIPv6"),
+	}
+}
+
+// ParentToChildIPv6 tests transit traffic that enters the BR over a parent
+// link with an IPv4 underlay and leaves over a child link with an IPv6
+// underlay.
+func ParentToChildIPv6(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:13 EthernetType=IPv4
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	// IP4: Src=192.168.13.3 Dst=192.168.13.2 NextHdr=UDP Flags=DF
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	// UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	udp.SetNetworkLayerForChecksum(ip)
+
+	// pkt0.ParsePacket(`
+	//	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4
+	//		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:6 Dst=174.16.6.1
+	//		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	//			HF_1: ConsIngress=0 ConsEgress=311
+	//			HF_2: ConsIngress=131 ConsEgress=111
+	//			HF_3: ConsIngress=611 ConsEgress=0
+	//	UDP_1: Src=40111 Dst=40222
+	// `)
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 111},
+			{ConsIngress: 611, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      common.L4UDP,
+		PathType:     slayers.PathTypeSCION,
+		SrcIA:        xtest.MustParseIA("1-ff00:0:3"),
+		DstIA:        xtest.MustParseIA("1-ff00:0:6"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("172.16.3.1")}); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(&net.IPAddr{IP: net.ParseIP("174.16.6.1")}); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = layers.UDPPort(40111)
+	scionudp.DstPort = layers.UDPPort(40222)
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:11 DstMAC=f0:0d:ca:fe:be:ef EthernetType=IPv6
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x11}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ethernet.EthernetType = layers.EthernetTypeIPv6
+	// IP6: Src=fd00:f00d:cafe::11:2 Dst=fd00:f00d:cafe::11:3 NextHdr=UDP
+	ip6 := &layers.IPv6{
+		Version:    6,
+		HopLimit:   64,
+		SrcIP:      net.ParseIP("fd00:f00d:cafe::11:2"),
+		DstIP:      net.ParseIP("fd00:f00d:cafe::11:3"),
+		NextHeader: layers.IPProtocolUDP,
+	}
+	// UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	udp.SetNetworkLayerForChecksum(ip6)
+	// SCION: CurrHopF=2
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip6, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ParentToChildIPv6",
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_111_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildIPv6"),
+	}
+}
@@ -0,0 +1,868 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/integration/braccept_v2/runner"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/onehop"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+// SCMPInvalidSrcIAFromChild tests a packet from a child AS that was sent by a
+// host in another AS than the child.
+func SCMPInvalidSrcIAFromChild(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:5 Src=172.16.5.1 DstIA=1-ff00:0:3 Dst=174.16.3.1
+	// 		IF_1: ISD=1 Hops=3
+	// 			HF_1: ConsIngress=411 ConsEgress=0
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=0 ConsEgress=311
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 411, ConsEgress: 0},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 0, ConsEgress: 311},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:5", "172.16.5.1", "1-ff00:0:3", "174.16.3.1")
+	return paramProblemCase("SCMPInvalidSrcIAFromChild", artifactsDir, childLink141,
+		scionL, sp, slayers.SCMPCodeInvalidSourceAddress, slayers.CmnHdrLen+addr.IABytes)
+}
+
+// SCMPInvalidSrcISDFromChild tests a packet from a child AS that originates in
+// another ISD.
+func SCMPInvalidSrcISDFromChild(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=2-ff00:0:5 Src=172.16.5.1 DstIA=1-ff00:0:3 Dst=174.16.3.1
+	// 		IF_1: ISD=1 Hops=4
+	// 			HF_1: ConsIngress=421 ConsEgress=0
+	// 			HF_2: ConsIngress=411 ConsEgress=412
+	// 			HF_3: ConsIngress=131 ConsEgress=141
+	// 			HF_4: ConsIngress=0 ConsEgress=311
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 2,
+				SegLen: [3]uint8{4, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 4,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 421, ConsEgress: 0},
+			{ConsIngress: 411, ConsEgress: 412},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 0, ConsEgress: 311},
+		},
+	}
+	sp.HopFields[2].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[2])
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[2].Mac)
+
+	scionL := paramProblemSCION(sp, "2-ff00:0:5", "172.16.5.1", "1-ff00:0:3", "174.16.3.1")
+	return paramProblemCase("SCMPInvalidSrcISDFromChild", artifactsDir, childLink141,
+		scionL, sp, slayers.SCMPCodeInvalidSourceAddress, slayers.CmnHdrLen+addr.IABytes)
+}
+
+// SCMPInvalidCurrINF tests a packet with a current info field that is not the
+// info field of the segment of the current hop field.
+func SCMPInvalidCurrINF(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=1 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 		IF_2: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrINF: 0,
+				CurrHF:  1,
+				SegLen:  [3]uint8{1, 2, 0},
+			},
+			NumINF:  2,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			{
+				SegID:     0x222,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[1], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	return paramProblemCase("SCMPInvalidCurrINF", artifactsDir, parentLink131,
+		scionL, sp, slayers.SCMPCodeInvalidPath, slayers.CmnHdrLen+scionL.AddrHdrLen())
+}
+
+// SCMPInvalidPeering tests a packet with a path that consists of a single
+// segment with the peering flag set.
+func SCMPInvalidPeering(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=3 Flags=ConsDir,Peer
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Peer:      true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	return paramProblemCase("SCMPInvalidPeering", artifactsDir, parentLink131,
+		scionL, sp, slayers.SCMPCodeInvalidPath,
+		slayers.CmnHdrLen+scionL.AddrHdrLen()+scion.MetaLen)
+}
+
+// SCMPNonLocalDelivery tests a packet whose path ends in the local AS, while
+// the destination is another AS.
+func SCMPNonLocalDelivery(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{2, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 2,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	return paramProblemCase("SCMPNonLocalDelivery", artifactsDir, parentLink131,
+		scionL, sp, slayers.SCMPCodeNonLocalDelivery, slayers.CmnHdrLen)
+}
+
+// SCMPInvalidDstAddr tests a packet to the local AS with a destination address
+// length that is not defined.
+func SCMPInvalidDstAddr(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4 DstLen=8
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:1 Dst=192.168.0.51
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{2, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 2,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:1", "192.168.0.51")
+	scionL.DstAddrLen = slayers.AddrLen8
+	// The pointer refers to the address types and lengths of the common header.
+	return paramProblemCase("SCMPInvalidDstAddr", artifactsDir, parentLink131,
+		scionL, sp, slayers.SCMPCodeInvalidDestinationAddress, 9)
+}
+
+// NoSCMPReplyForInvalidSrcAddr tests that a packet from a remote AS with a
+// source address length that is not defined is dropped, because the SCMP error
+// can not be addressed to the sender.
+func NoSCMPReplyForInvalidSrcAddr(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 SrcLen=8 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	scionL.SrcAddrLen = slayers.AddrLen8
+	return noReplyCase("NoSCMPReplyForInvalidSrcAddr", artifactsDir, parentLink131, scionL)
+}
+
+// NoSCMPReplyForInvalidCurrHF tests that a packet from a remote AS with a
+// current hop field beyond the end of the path is dropped, because the path can
+// not be reversed.
+func NoSCMPReplyForInvalidCurrHF(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 3,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	return noReplyCase("NoSCMPReplyForInvalidCurrHF", artifactsDir, parentLink131, scionL)
+}
+
+// NoSCMPReplyForInvalidSegLen tests that a packet from a remote AS whose
+// segment lengths have a hole is dropped, because the path can not be reversed.
+func NoSCMPReplyForInvalidSegLen(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=311
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 		IF_2: ISD=1 Hops=0
+	// 		IF_3: ISD=1 Hops=1 Flags=ConsDir
+	// 			HF_3: ConsIngress=411 ConsEgress=0
+	sp := invalidSegLenPath(mac, []*path.HopField{
+		{ConsIngress: 0, ConsEgress: 311},
+		{ConsIngress: 131, ConsEgress: 141},
+		{ConsIngress: 411, ConsEgress: 0},
+	}, 1)
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "174.16.4.1")
+	return noReplyCase("NoSCMPReplyForInvalidSegLen", artifactsDir, parentLink131, scionL)
+}
+
+// SCMPInvalidSegLenInternal tests a packet from a host in the local AS whose
+// segment lengths have a hole. The path can not be reversed, the SCMP error is
+// sent to the host with an empty path.
+func SCMPInvalidSegLenInternal(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:1 Src=192.168.0.71 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=141
+	// 			HF_2: ConsIngress=411 ConsEgress=412
+	// 		IF_2: ISD=1 Hops=0
+	// 		IF_3: ISD=1 Hops=1 Flags=ConsDir
+	// 			HF_3: ConsIngress=421 ConsEgress=0
+	sp := invalidSegLenPath(mac, []*path.HopField{
+		{ConsIngress: 0, ConsEgress: 141},
+		{ConsIngress: 411, ConsEgress: 412},
+		{ConsIngress: 421, ConsEgress: 0},
+	}, 0)
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:1", "192.168.0.71", "1-ff00:0:4", "174.16.4.1")
+	return paramProblemInternalCase("SCMPInvalidSegLenInternal", artifactsDir, scionL,
+		slayers.SCMPCodeInvalidPath, slayers.CmnHdrLen+scionL.AddrHdrLen())
+}
+
+// invalidSegLenPath returns a path with the segment lengths 2, 0 and 1 that
+// consists of the given hop fields. The MAC of the current hop field is valid.
+func invalidSegLenPath(mac hash.Hash, hops []*path.HopField, currHF uint8) *scion.Decoded {
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: currHF,
+				SegLen: [3]uint8{2, 0, 1},
+			},
+			NumINF:  3,
+			NumHops: 3,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			{
+				SegID:     0x222,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			{
+				SegID:     0x333,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: hops,
+	}
+	sp.HopFields[currHF].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[currHF])
+	return sp
+}
+
+// SCMPInvalidCurrHFInternal tests a packet from a host in the local AS with a
+// current hop field beyond the end of the path. The path can not be reversed,
+// the SCMP error is sent to the host with an empty path.
+func SCMPInvalidCurrHFInternal(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:1 Src=192.168.0.71 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=141
+	// 			HF_2: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 2,
+				SegLen: [3]uint8{2, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 2,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[0].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[0])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:1", "192.168.0.71", "1-ff00:0:4", "174.16.4.1")
+	return paramProblemInternalCase("SCMPInvalidCurrHFInternal", artifactsDir, scionL,
+		slayers.SCMPCodeInvalidPath, slayers.CmnHdrLen+scionL.AddrHdrLen())
+}
+
+// SCMPInvalidSrcAddrInternal tests a packet from a host in the local AS with a
+// source address length that is not defined. The SCMP error is sent to the
+// underlay address of the host with an empty path.
+func SCMPInvalidSrcAddrInternal(artifactsDir string, mac hash.Hash) runner.Case {
+	// 	SCION: NextHdr=UDP SrcType=IPv4 SrcLen=8 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:1 Src=192.168.0.71 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	// 		IF_1: ISD=1 Hops=2 Flags=ConsDir
+	// 			HF_1: ConsIngress=0 ConsEgress=141
+	// 			HF_2: ConsIngress=411 ConsEgress=0
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				SegLen: [3]uint8{2, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 2,
+		},
+		InfoFields: []*path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 0, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[0].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[0])
+
+	scionL := paramProblemSCION(sp, "1-ff00:0:1", "192.168.0.71", "1-ff00:0:4", "174.16.4.1")
+	scionL.SrcAddrLen = slayers.AddrLen8
+	// The pointer refers to the address types and lengths of the common header.
+	return paramProblemInternalCase("SCMPInvalidSrcAddrInternal", artifactsDir, scionL,
+		slayers.SCMPCodeInvalidSourceAddress, 9)
+}
+
+// SCMPReversedOneHopInternal tests a one-hop path in reverse construction
+// direction from a host in the local AS. The one-hop path can not be reversed,
+// the SCMP error is sent to the host with an empty path.
+func SCMPReversedOneHopInternal(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x01},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 0, 71},
+		DstIP:    net.IP{192, 168, 0, 11},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(30041),
+		DstPort: layers.UDPPort(30001),
+	}
+	udp.SetNetworkLayerForChecksum(ip)
+	ohp := &onehop.Path{
+		Info: path.InfoField{
+			ConsDir:   false,
+			SegID:     0x111,
+			Timestamp: util.TimeToSecs(time.Now()),
+		},
+		FirstHop: path.HopField{ConsIngress: 0, ConsEgress: 141},
+	}
+	ohp.FirstHop.Mac = path.MAC(mac, &ohp.Info, &ohp.FirstHop)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      common.L4UDP,
+		PathType:     slayers.PathTypeOneHop,
+		SrcIA:        xtest.MustParseIA("1-ff00:0:1"),
+		DstIA:        xtest.MustParseIA("1-ff00:0:4"),
+		Path:         ohp,
+	}
+	srcA := &net.IPAddr{IP: net.ParseIP("192.168.0.71").To4()}
+	if err := scionL.SetSrcAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(&net.IPAddr{IP: net.ParseIP("172.16.4.1")}); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = layers.UDPPort(2345)
+	scionudp.DstPort = layers.UDPPort(53)
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+	pointer := slayers.CmnHdrLen + scionL.AddrHdrLen()
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC, ethernet.DstMAC = ethernet.DstMAC, ethernet.SrcMAC
+	ip.SrcIP, ip.DstIP = ip.DstIP, ip.SrcIP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	scionL.DstIA = scionL.SrcIA
+	scionL.PathType = slayers.PathTypeEmpty
+	scionL.Path = &scion.Decoded{}
+	if err := scionL.SetDstAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetSrcAddr(&net.IPAddr{IP: net.IP{192, 168, 0, 11}}); err != nil {
+		panic(err)
+	}
+	scionL.NextHdr = common.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+			slayers.SCMPCodeInvalidPath),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+	scmpP := &slayers.SCMPParameterProblem{
+		Pointer: uint16(pointer),
+	}
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	quote := input.Bytes()[quoteStart:]
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "SCMPReversedOneHopInternal",
+		WriteTo:  "veth_int_host",
+		ReadFrom: "veth_int_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "SCMPReversedOneHopInternal"),
+	}
+}
+
+// paramProblemLink is an external link of the border router that the packets
+// of the parameter problem cases are sent on.
+type paramProblemLink struct {
+	veth string
+	// subnet is the third byte of the IPv4 addresses on the link.
+	subnet byte
+	// mac is the last byte of the MAC address of the border router on the
+	// link.
+	mac byte
+}
+
+var (
+	parentLink131 = paramProblemLink{veth: "veth_131_host", subnet: 13, mac: 0x13}
+	childLink141  = paramProblemLink{veth: "veth_141_host", subnet: 14, mac: 0x14}
+)
+
+// paramProblemSCION returns the SCION header of a test packet with the given
+// path and addresses.
+func paramProblemSCION(sp *scion.Decoded, srcIA, src, dstIA, dst string) *slayers.SCION {
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      common.L4UDP,
+		PathType:     slayers.PathTypeSCION,
+		SrcIA:        xtest.MustParseIA(srcIA),
+		DstIA:        xtest.MustParseIA(dstIA),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(&net.IPAddr{IP: net.ParseIP(src).To4()}); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(&net.IPAddr{IP: net.ParseIP(dst).To4()}); err != nil {
+		panic(err)
+	}
+	return scionL
+}
+
+// paramProblemInput returns the underlay layers and the raw input packet for a
+// packet with the given SCION header that is sent on the link.
+func paramProblemInput(link paramProblemLink,
+	scionL *slayers.SCION) (*layers.Ethernet, *layers.IPv4, *layers.UDP, []byte) {
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, link.mac},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, link.subnet, 3},
+		DstIP:    net.IP{192, 168, link.subnet, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	udp.SetNetworkLayerForChecksum(ip)
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = layers.UDPPort(40111)
+	scionudp.DstPort = layers.UDPPort(40222)
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	return ethernet, ip, udp, input.Bytes()
+}
+
+// paramProblemCase returns a test case for a packet that is sent on the link
+// and answered on the same link with an SCMP parameter problem with the given
+// code and pointer.
+func paramProblemCase(name, artifactsDir string, link paramProblemLink,
+	scionL *slayers.SCION, sp *scion.Decoded, code slayers.SCMPCode, pointer int) runner.Case {
+
+	ethernet, ip, udp, input := paramProblemInput(link, scionL)
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC, ethernet.DstMAC = ethernet.DstMAC, ethernet.SrcMAC
+	ip.SrcIP, ip.DstIP = ip.DstIP, ip.SrcIP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	srcA, err := scionL.SrcAddr()
+	if err != nil {
+		panic(err)
+	}
+	scionL.DstIA = scionL.SrcIA
+	scionL.SrcIA = xtest.MustParseIA("1-ff00:0:1")
+	if err := scionL.SetDstAddr(srcA); err != nil {
+		panic(err)
+	}
+	intlA := &net.IPAddr{IP: net.IP{192, 168, 0, 11}}
+	if err := scionL.SetSrcAddr(intlA); err != nil {
+		panic(err)
+	}
+
+	// The SCMP error is sent back on the reversed path, starting at the hop
+	// field of the neighbor.
+	if err := sp.Reverse(); err != nil {
+		panic(err)
+	}
+	if info := sp.InfoFields[sp.PathMeta.CurrINF]; info.ConsDir {
+		info.UpdateSegID(sp.HopFields[sp.PathMeta.CurrHF].Mac)
+	}
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	scionL.NextHdr = common.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+	scmpP := &slayers.SCMPParameterProblem{
+		Pointer: uint16(pointer),
+	}
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	quote := input[quoteStart:]
+	if err := gopacket.SerializeLayers(want,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		ethernet, ip, udp, scionL, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     name,
+		WriteTo:  link.veth,
+		ReadFrom: link.veth,
+		Input:    input,
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, name),
+	}
+}
+
+// noReplyCase returns a test case for a packet that is sent on the link and
+// dropped without an SCMP error.
+func noReplyCase(name, artifactsDir string, link paramProblemLink,
+	scionL *slayers.SCION) runner.Case {
+
+	_, _, _, input := paramProblemInput(link, scionL)
+	return runner.Case{
+		Name:     name,
+		WriteTo:  link.veth,
+		ReadFrom: "no_pkt_expected",
+		Input:    input,
+		Want:     nil,
+		StoreDir: filepath.Join(artifactsDir, name),
+	}
+}
+
+// paramProblemInternalCase returns a test case for a packet that is sent by a
+// host in the local AS and answered with an SCMP parameter problem with the
+// given code and pointer. The SCMP error is sent to the underlay address of the
+// host with an empty path.
+func paramProblemInternalCase(name, artifactsDir string, scionL *slayers.SCION,
+	code slayers.SCMPCode, pointer int) runner.Case {
+
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x01},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 0, 71},
+		DstIP:    net.IP{192, 168, 0, 11},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(30041),
+		DstPort: layers.UDPPort(30001),
+	}
+	udp.SetNetworkLayerForChecksum(ip)
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = layers.UDPPort(40111)
+	scionudp.DstPort = layers.UDPPort(40222)
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC, ethernet.DstMAC = ethernet.DstMAC, ethernet.SrcMAC
+	ip.SrcIP, ip.DstIP = ip.DstIP, ip.SrcIP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	scionL.DstIA = scionL.SrcIA
+	scionL.PathType = slayers.PathTypeEmpty
+	scionL.Path = &scion.Decoded{}
+	if err := scionL.SetDstAddr(&net.IPAddr{IP: ip.DstIP}); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetSrcAddr(&net.IPAddr{IP: net.IP{192, 168, 0, 11}}); err != nil {
+		panic(err)
+	}
+	scionL.NextHdr = common.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+	scmpP := &slayers.SCMPParameterProblem{
+		Pointer: uint16(pointer),
+	}
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	quote := input.Bytes()[quoteStart:]
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     name,
+		WriteTo:  "veth_int_host",
+		ReadFrom: "veth_int_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, name),
+	}
+}
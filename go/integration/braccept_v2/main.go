@@ -78,6 +78,8 @@ func realMain() int {
 	multi := []runner.Case{
 		cases.ParentToChild(artifactsDir, hfMAC),
 		cases.ChildToParent(artifactsDir, hfMAC),
+		cases.ParentToChildIPv6(artifactsDir, hfMAC),
+		cases.ChildToParentIPv6(artifactsDir, hfMAC),
 		cases.ChildToChildXover(artifactsDir, hfMAC),
 		cases.ChildToInternalHost(artifactsDir, hfMAC),
 		cases.ChildToInternalParent(artifactsDir, hfMAC),
@@ -96,6 +98,19 @@ func realMain() int {
 		cases.SCMPBadPktLen(artifactsDir, hfMAC),
 		cases.SCMPQuoteCut(artifactsDir, hfMAC),
 		cases.NoSCMPReplyForSCMPError(artifactsDir, hfMAC),
+		cases.SCMPInvalidSrcIAFromChild(artifactsDir, hfMAC),
+		cases.SCMPInvalidSrcISDFromChild(artifactsDir, hfMAC),
+		cases.SCMPInvalidCurrINF(artifactsDir, hfMAC),
+		cases.SCMPInvalidPeering(artifactsDir, hfMAC),
+		cases.SCMPNonLocalDelivery(artifactsDir, hfMAC),
+		cases.SCMPInvalidDstAddr(artifactsDir, hfMAC),
+		cases.NoSCMPReplyForInvalidSrcAddr(artifactsDir, hfMAC),
+		cases.NoSCMPReplyForInvalidCurrHF(artifactsDir, hfMAC),
+		cases.SCMPInvalidCurrHFInternal(artifactsDir, hfMAC),
+		cases.SCMPInvalidSrcAddrInternal(artifactsDir, hfMAC),
+		cases.NoSCMPReplyForInvalidSegLen(artifactsDir, hfMAC),
+		cases.SCMPInvalidSegLenInternal(artifactsDir, hfMAC),
+		cases.SCMPReversedOneHopInternal(artifactsDir, hfMAC),
 		cases.IncomingOneHop(artifactsDir, hfMAC),
 		cases.OutgoingOneHop(artifactsDir, hfMAC),
 		cases.SVC(artifactsDir, hfMAC),
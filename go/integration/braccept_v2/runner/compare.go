@@ -70,7 +70,12 @@ func comparePkts(got, want gopacket.Packet) error {
 			w.(*layers.IPv4).Checksum = 0
 			v.Id, v.Checksum = 0, 0
 			err = compareLayersString(v, w)
-			// TODO(karampok). Add IPv6
+		case *layers.IPv6:
+			// The kernel may pick a flow label for outgoing packets.
+			w := want.Layer(layers.LayerTypeIPv6)
+			w.(*layers.IPv6).FlowLabel = 0
+			v.FlowLabel = 0
+			err = compareLayersString(v, w)
 		case *layers.UDP:
 			w := want.Layer(layers.LayerTypeUDP)
 			w.(*layers.UDP).Checksum = 0
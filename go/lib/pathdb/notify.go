@@ -0,0 +1,136 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathdb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/pathdb/query"
+)
+
+// Notifier informs subscribers that the paths stored in a path DB changed.
+// Notifications are coalesced, a subscriber that did not yet consume a
+// notification does not receive a second one. The zero value is ready for
+// use.
+type Notifier struct {
+	mtx  sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// Subscribe returns a channel that receives a value whenever the paths
+// change. The returned function cancels the subscription.
+func (n *Notifier) Subscribe() (<-chan struct{}, func()) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.subs == nil {
+		n.subs = make(map[chan struct{}]struct{})
+	}
+	c := make(chan struct{}, 1)
+	n.subs[c] = struct{}{}
+	return c, func() {
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+		delete(n.subs, c)
+	}
+}
+
+// Notify informs all subscribers that the paths changed.
+func (n *Notifier) Notify() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for c := range n.subs {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WithNotifier wraps the given PathDB into one that notifies the notifier
+// whenever a segment is inserted or updated. Inserts in a transaction are
+// notified when the transaction is committed.
+func WithNotifier(pathDB PathDB, notifier *Notifier) PathDB {
+	return &notifyingPathDB{PathDB: pathDB, notifier: notifier}
+}
+
+type notifyingPathDB struct {
+	PathDB
+	notifier *Notifier
+}
+
+func (db *notifyingPathDB) Insert(ctx context.Context, meta *seg.Meta) (InsertStats, error) {
+	stats, err := db.PathDB.Insert(ctx, meta)
+	if err == nil && stats.Inserted+stats.Updated > 0 {
+		db.notifier.Notify()
+	}
+	return stats, err
+}
+
+func (db *notifyingPathDB) InsertWithHPCfgIDs(ctx context.Context, meta *seg.Meta,
+	hpCfgIDs []*query.HPCfgID) (InsertStats, error) {
+
+	stats, err := db.PathDB.InsertWithHPCfgIDs(ctx, meta, hpCfgIDs)
+	if err == nil && stats.Inserted+stats.Updated > 0 {
+		db.notifier.Notify()
+	}
+	return stats, err
+}
+
+func (db *notifyingPathDB) BeginTransaction(ctx context.Context,
+	opts *sql.TxOptions) (Transaction, error) {
+
+	tx, err := db.PathDB.BeginTransaction(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &notifyingTransaction{Transaction: tx, notifier: db.notifier}, nil
+}
+
+type notifyingTransaction struct {
+	Transaction
+	notifier *Notifier
+	changed  bool
+}
+
+func (tx *notifyingTransaction) Insert(ctx context.Context, meta *seg.Meta) (InsertStats, error) {
+	stats, err := tx.Transaction.Insert(ctx, meta)
+	if err == nil && stats.Inserted+stats.Updated > 0 {
+		tx.changed = true
+	}
+	return stats, err
+}
+
+func (tx *notifyingTransaction) InsertWithHPCfgIDs(ctx context.Context, meta *seg.Meta,
+	hpCfgIDs []*query.HPCfgID) (InsertStats, error) {
+
+	stats, err := tx.Transaction.InsertWithHPCfgIDs(ctx, meta, hpCfgIDs)
+	if err == nil && stats.Inserted+stats.Updated > 0 {
+		tx.changed = true
+	}
+	return stats, err
+}
+
+func (tx *notifyingTransaction) Commit() error {
+	if err := tx.Transaction.Commit(); err != nil {
+		return err
+	}
+	if tx.changed {
+		tx.notifier.Notify()
+	}
+	return nil
+}
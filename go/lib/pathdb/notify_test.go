@@ -0,0 +1,125 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/pathdb/mock_pathdb"
+	"github.com/scionproto/scion/go/lib/pathdb/pathdbtest"
+	"github.com/scionproto/scion/go/lib/pathdb/sqlite"
+)
+
+type notifyingTestPathDB struct {
+	pathdb.PathDB
+}
+
+func (b *notifyingTestPathDB) Prepare(t *testing.T, _ context.Context) {
+	db, err := sqlite.New("file::memory:")
+	require.NoError(t, err)
+	b.PathDB = pathdb.WithNotifier(db, &pathdb.Notifier{})
+}
+
+// TestNotifierWrapperFunctionality tests that the notifying wrapper succeeds
+// the normal path db test suite.
+func TestNotifierWrapperFunctionality(t *testing.T) {
+	pathdbtest.TestPathDB(t, &notifyingTestPathDB{})
+}
+
+func TestNotifier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	notifier := &pathdb.Notifier{}
+	updates, cancel := notifier.Subscribe()
+	defer cancel()
+	notified := func() bool {
+		select {
+		case <-updates:
+			return true
+		default:
+			return false
+		}
+	}
+
+	mockDB := mock_pathdb.NewMockPathDB(ctrl)
+	db := pathdb.WithNotifier(mockDB, notifier)
+
+	t.Run("insert", func(t *testing.T) {
+		mockDB.EXPECT().Insert(ctx, nil).Return(pathdb.InsertStats{Inserted: 1}, nil)
+		_, err := db.Insert(ctx, nil)
+		require.NoError(t, err)
+		assert.True(t, notified())
+	})
+	t.Run("insert unchanged", func(t *testing.T) {
+		mockDB.EXPECT().Insert(ctx, nil).Return(pathdb.InsertStats{}, nil)
+		_, err := db.Insert(ctx, nil)
+		require.NoError(t, err)
+		assert.False(t, notified())
+	})
+	t.Run("notifications are coalesced", func(t *testing.T) {
+		mockDB.EXPECT().InsertWithHPCfgIDs(ctx, nil, nil).
+			Return(pathdb.InsertStats{Updated: 1}, nil).Times(2)
+		_, err := db.InsertWithHPCfgIDs(ctx, nil, nil)
+		require.NoError(t, err)
+		_, err = db.InsertWithHPCfgIDs(ctx, nil, nil)
+		require.NoError(t, err)
+		assert.True(t, notified())
+		assert.False(t, notified())
+	})
+	t.Run("transaction", func(t *testing.T) {
+		mockTx := mock_pathdb.NewMockTransaction(ctrl)
+		mockDB.EXPECT().BeginTransaction(ctx, nil).Return(mockTx, nil)
+		mockTx.EXPECT().Insert(ctx, nil).Return(pathdb.InsertStats{Inserted: 1}, nil)
+		mockTx.EXPECT().Commit()
+		tx, err := db.BeginTransaction(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.Insert(ctx, nil)
+		require.NoError(t, err)
+		assert.False(t, notified())
+		require.NoError(t, tx.Commit())
+		assert.True(t, notified())
+	})
+	t.Run("rollback", func(t *testing.T) {
+		mockTx := mock_pathdb.NewMockTransaction(ctrl)
+		mockDB.EXPECT().BeginTransaction(ctx, nil).Return(mockTx, nil)
+		mockTx.EXPECT().Insert(ctx, nil).Return(pathdb.InsertStats{Inserted: 1}, nil)
+		mockTx.EXPECT().Rollback()
+		tx, err := db.BeginTransaction(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.Insert(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		assert.False(t, notified())
+	})
+	t.Run("cancel", func(t *testing.T) {
+		other, cancelOther := notifier.Subscribe()
+		cancelOther()
+		notifier.Notify()
+		assert.True(t, notified())
+		select {
+		case <-other:
+			t.Fatal("canceled subscription was notified")
+		default:
+		}
+	})
+}
@@ -33,6 +33,7 @@ package pathmgr
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
@@ -95,8 +96,10 @@ type Resolver interface {
 	// QueryFilter returns a set of paths between src and dst that satisfy
 	// policy. A nil policy will not delete any paths.
 	QueryFilter(ctx context.Context, src, dst addr.IA, policy Policy) spathmeta.AppPathSet
-	// Watch returns an object that periodically polls for paths between src
-	// and dst.
+	// Watch returns an object that keeps the paths between src and dst up to
+	// date. If the SCIOND connector implements sciond.PathWatcher, SCIOND
+	// pushes path updates to the watch; otherwise, the watch periodically
+	// polls for paths.
 	//
 	// The function blocks until the first answer from SCIOND is received. The
 	// amount of time is dictated by ctx. Note that the resolver might
@@ -125,6 +128,8 @@ type resolver struct {
 	sciondConn   sciond.Connector
 	timers       Timers
 	watchFactory *WatchFactory
+	// streams is the number of watches that receive updates pushed by SCIOND.
+	streams int32
 }
 
 // New creates a new path management context.
@@ -168,19 +173,22 @@ func (r *resolver) QueryFilter(ctx context.Context, src, dst addr.IA,
 func (r *resolver) WatchFilter(ctx context.Context, src, dst addr.IA,
 	filter Policy) (*SyncPaths, error) {
 
-	aps := r.Query(ctx, src, dst, sciond.PathReqFlags{})
-	if filter != nil {
-		aps = psToAps(filter.Filter(apsToPs(aps)))
-	}
-	sp := NewSyncPaths()
-	sp.Update(aps)
-
 	query := &queryConfig{
 		querier: Querier(r),
 		src:     src,
 		dst:     dst,
 		filter:  filter,
 	}
+	if watcher, ok := r.sciondConn.(sciond.PathWatcher); ok {
+		sp, err := r.watchStream(ctx, watcher, query)
+		if err == nil {
+			return sp, nil
+		}
+		r.logger(ctx).Info("Path stream not available, polling SCIOND for paths", "err", err)
+	}
+
+	sp := NewSyncPaths()
+	sp.Update(query.Do(ctx, sciond.PathReqFlags{}))
 	pp := NewPollingPolicy(filter != nil, r.timers)
 	w := r.watchFactory.New(sp, query, pp)
 	sp.setDestructor(w.Destroy)
@@ -197,7 +205,7 @@ func (r *resolver) Watch(ctx context.Context, src, dst addr.IA) (*SyncPaths, err
 }
 
 func (r *resolver) WatchCount() int {
-	return r.watchFactory.length()
+	return r.watchFactory.length() + int(atomic.LoadInt32(&r.streams))
 }
 
 func (r *resolver) RevokeRaw(ctx context.Context, rawSRevInfo common.RawBytes) {
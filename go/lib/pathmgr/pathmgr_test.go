@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/sciond/mock_sciond"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/xtest"
 )
 
@@ -345,3 +347,73 @@ func NewTestRev(t *testing.T, ia addr.IA, ifID common.IFIDType) *path_mgmt.Signe
 	require.NoError(t, err)
 	return signedRevInfo
 }
+
+func TestWatchStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	src := xtest.MustParseIA("1-ff00:0:111")
+	dst := xtest.MustParseIA("1-ff00:0:110")
+	sd := &streamingConnector{
+		MockConnector: mock_sciond.NewMockConnector(ctrl),
+		updates:       make(chan []snet.Path),
+	}
+	pr := pathmgr.New(sd, pathmgr.Timers{ErrorRefire: getDuration(1)})
+
+	go func() {
+		sd.updates <- buildSDAnswer(t, ctrl,
+			"1-ff00:0:111#104 1-ff00:0:120#5 1-ff00:0:120#6 1-ff00:0:110#1",
+		)
+	}()
+	sp, err := pr.Watch(context.Background(), src, dst)
+	require.NoError(t, err)
+	assert.Len(t, sp.Load().APS, 1, "the first update is available immediately")
+	assert.Equal(t, 1, pr.WatchCount())
+
+	sd.updates <- buildSDAnswer(t, ctrl,
+		"1-ff00:0:111#105 1-ff00:0:130#1002 1-ff00:0:130#1004 1-ff00:0:110#2",
+		"1-ff00:0:111#104 1-ff00:0:120#5 1-ff00:0:120#6 1-ff00:0:110#1",
+	)
+	assert.Eventually(t, func() bool { return len(sp.Load().APS) == 2 },
+		getDuration(10), getDuration(1), "pushed updates are applied")
+
+	t.Log("When the stream ends, the watch falls back to polling")
+	sd.MockConnector.EXPECT().Paths(gomock.Any(), dst, src, gomock.Any()).Return(
+		buildSDAnswer(t, ctrl,
+			"1-ff00:0:111#105 1-ff00:0:130#1002 1-ff00:0:130#1004 1-ff00:0:110#2",
+		), nil,
+	).MinTimes(1)
+	close(sd.updates)
+	assert.Eventually(t, func() bool { return len(sp.Load().APS) == 1 },
+		getDuration(10), getDuration(1), "polled paths are applied")
+	assert.Equal(t, 1, pr.WatchCount())
+
+	sp.Destroy()
+	assert.Equal(t, 0, pr.WatchCount())
+	assert.True(t, sd.canceled(), "the stream context is canceled")
+}
+
+// streamingConnector is a SCIOND connector that supports streaming path
+// updates.
+type streamingConnector struct {
+	*mock_sciond.MockConnector
+	updates chan []snet.Path
+
+	mtx sync.Mutex
+	ctx context.Context
+}
+
+func (c *streamingConnector) WatchPaths(ctx context.Context, _, _ addr.IA,
+	_ sciond.PathReqFlags) (<-chan []snet.Path, error) {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.ctx = ctx
+	return c.updates, nil
+}
+
+func (c *streamingConnector) canceled() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.ctx.Err() != nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathmgr
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+// watchStream creates a watch that receives path updates pushed by SCIOND. It
+// blocks until the first update is received. If the stream ends before the
+// watch is destroyed, e.g., because SCIOND restarted, the watch falls back to
+// polling.
+func (r *resolver) watchStream(ctx context.Context, watcher sciond.PathWatcher,
+	query *queryConfig) (*SyncPaths, error) {
+
+	streamCtx, cancelF := context.WithCancel(context.Background())
+	updates, err := watcher.WatchPaths(streamCtx, query.dst, query.src, sciond.PathReqFlags{})
+	if err != nil {
+		cancelF()
+		return nil, err
+	}
+	var paths []snet.Path
+	select {
+	case p, ok := <-updates:
+		if !ok {
+			cancelF()
+			return nil, serrors.New("path stream closed")
+		}
+		paths = p
+	case <-ctx.Done():
+		cancelF()
+		return nil, ctx.Err()
+	}
+
+	sp := NewSyncPaths()
+	sp.Update(query.apply(spathmeta.NewAppPathSet(paths)))
+	w := &streamRunner{
+		resolver: r,
+		sp:       sp,
+		query:    query,
+		updates:  updates,
+		cancel:   cancelF,
+	}
+	atomic.AddInt32(&r.streams, 1)
+	sp.setDestructor(w.Destroy)
+	go func() {
+		defer log.HandlePanic()
+		w.Run()
+	}()
+	return sp, nil
+}
+
+// streamRunner updates a concurrency-safe store of paths with the updates
+// pushed by SCIOND.
+type streamRunner struct {
+	resolver *resolver
+	sp       *SyncPaths
+	query    *queryConfig
+	updates  <-chan []snet.Path
+	cancel   context.CancelFunc
+
+	mtx      sync.Mutex
+	stopped  bool
+	fallback *WatchReference
+}
+
+func (w *streamRunner) Run() {
+	for paths := range w.updates {
+		w.sp.Update(w.query.apply(spathmeta.NewAppPathSet(paths)))
+	}
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.stopped {
+		return
+	}
+	log.Info("Path stream ended, polling SCIOND for paths",
+		"src", w.query.src, "dst", w.query.dst)
+	atomic.AddInt32(&w.resolver.streams, -1)
+	pp := NewPollingPolicy(w.query.filter != nil, w.resolver.timers)
+	// The paths might be outdated already, poll immediately.
+	pp.PollNow()
+	w.fallback = w.resolver.watchFactory.New(w.sp, w.query, pp)
+	go func() {
+		defer log.HandlePanic()
+		w.fallback.Run()
+	}()
+}
+
+// Destroy stops the stream, or the polling loop if the watch fell back to
+// polling. It is safe to call Destroy multiple times.
+func (w *streamRunner) Destroy() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	w.cancel()
+	if w.fallback != nil {
+		w.fallback.Destroy()
+		return
+	}
+	atomic.AddInt32(&w.resolver.streams, -1)
+}
@@ -39,6 +39,8 @@ func NewWatchFactory(timers Timers) *WatchFactory {
 }
 
 func (factory *WatchFactory) New(sp *SyncPaths, bq *queryConfig, pp PollingPolicy) *WatchReference {
+	factory.mtx.Lock()
+	defer factory.mtx.Unlock()
 	ref := &WatchReference{parent: factory}
 	factory.instances[ref] = &WatchRunner{
 		sp:      sp,
@@ -150,7 +152,11 @@ type queryConfig struct {
 }
 
 func (bq *queryConfig) Do(ctx context.Context, flags sciond.PathReqFlags) spathmeta.AppPathSet {
-	aps := bq.querier.Query(ctx, bq.src, bq.dst, flags)
+	return bq.apply(bq.querier.Query(ctx, bq.src, bq.dst, flags))
+}
+
+// apply filters aps with the filter of the query, if any.
+func (bq *queryConfig) apply(aps spathmeta.AppPathSet) spathmeta.AppPathSet {
 	if bq.filter != nil {
 		aps = psToAps(bq.filter.Filter(apsToPs(aps)))
 	}
@@ -64,6 +64,14 @@ func (a *ACL) UnmarshalJSON(b []byte) error {
 	return json.Unmarshal(b, &a.Entries)
 }
 
+func (a *ACL) MarshalYAML() (interface{}, error) {
+	return a.Entries, nil
+}
+
+func (a *ACL) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&a.Entries)
+}
+
 func (a *ACL) evalPath(path Path) ACLAction {
 	for i, iface := range path.Interfaces() {
 		if a.evalInterface(iface, i%2 != 0) == Deny {
@@ -128,6 +136,18 @@ func (ae *ACLEntry) UnmarshalJSON(b []byte) error {
 	return ae.LoadFromString(str)
 }
 
+func (ae *ACLEntry) MarshalYAML() (interface{}, error) {
+	return ae.String(), nil
+}
+
+func (ae *ACLEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	return ae.LoadFromString(str)
+}
+
 func getAction(symbol string) (ACLAction, error) {
 	if symbol == allowSymbol {
 		return true, nil
@@ -0,0 +1,398 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// Severity is the severity of an issue found by PolicyMap.Lint.
+type Severity int
+
+const (
+	// SeverityWarning indicates that the policy can be used, but likely does
+	// not behave as intended.
+	SeverityWarning Severity = iota
+	// SeverityError indicates that the policy can not be used.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("UNKNOWN (%d)", int(s))
+	}
+}
+
+// Issue is a problem in a policy found by PolicyMap.Lint.
+type Issue struct {
+	// Policy is the name of the policy that contains the problem.
+	Policy string
+	// Severity is the severity of the problem.
+	Severity Severity
+	// Msg describes the problem.
+	Msg string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Policy, i.Msg)
+}
+
+// Lint validates the policies in the map. It reports policies that extend
+// unknown policies or that extend themselves, ACLs without a default rule,
+// sequences that can never match a path, and options that are never
+// evaluated because an option with a higher weight takes precedence. The
+// issues are ordered by policy name.
+func (m PolicyMap) Lint() []Issue {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var issues []Issue
+	for _, name := range names {
+		l := linter{name: name}
+		ext := m[name]
+		if ext == nil {
+			continue
+		}
+		for _, extended := range ext.Extends {
+			if _, ok := m[extended]; !ok {
+				l.errorf("extends unknown policy %q", extended)
+			}
+		}
+		if cycle := m.extendsCycle(name); cycle != nil {
+			l.errorf("circular extension %s", strings.Join(cycle, " -> "))
+		}
+		l.lintPolicy("", ext.Policy)
+		issues = append(issues, l.issues...)
+	}
+	return issues
+}
+
+// extendsCycle returns the chain of extended policies that leads from the
+// named policy back to itself, or nil if there is no such chain.
+func (m PolicyMap) extendsCycle(name string) []string {
+	visited := make(map[string]bool)
+	var visit func(chain []string) []string
+	visit = func(chain []string) []string {
+		ext := m[chain[len(chain)-1]]
+		if ext == nil {
+			return nil
+		}
+		for _, extended := range ext.Extends {
+			next := append(chain[:len(chain):len(chain)], extended)
+			if extended == name {
+				return next
+			}
+			if visited[extended] {
+				continue
+			}
+			visited[extended] = true
+			if cycle := visit(next); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit([]string{name})
+}
+
+type linter struct {
+	name   string
+	issues []Issue
+}
+
+func (l *linter) errorf(format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{
+		Policy:   l.name,
+		Severity: SeverityError,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) warnf(format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{
+		Policy:   l.name,
+		Severity: SeverityWarning,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// lintPolicy checks the policy and its options. The location prefixes the
+// messages to identify nested options.
+func (l *linter) lintPolicy(location string, p *Policy) {
+	if p == nil {
+		return
+	}
+	if p.ACL != nil && len(p.ACL.Entries) > 0 &&
+		!p.ACL.Entries[len(p.ACL.Entries)-1].Rule.matchesAll() {
+
+		l.errorf("%sACL has no default rule, the last entry must match all hops", location)
+	}
+	if p.Sequence != nil && p.Sequence.matchesNothing() {
+		l.warnf("%ssequence %q can never match a path", location, p.Sequence)
+	}
+	l.lintOptions(location, p.Options)
+	for i, option := range p.Options {
+		if option.Policy != nil {
+			l.lintPolicy(fmt.Sprintf("%soptions[%d]: ", location, i), option.Policy.Policy)
+		}
+	}
+}
+
+// lintOptions reports options that are never evaluated. An option is never
+// evaluated if an option with a higher weight either matches all paths or
+// has the same policy.
+func (l *linter) lintOptions(location string, options []Option) {
+	for i, option := range options {
+		for j, other := range options {
+			if other.Weight <= option.Weight {
+				continue
+			}
+			if !restricts(other.Policy) {
+				l.warnf("%soptions[%d] is unreachable, options[%d] with higher weight "+
+					"matches all paths", location, i, j)
+				break
+			}
+			if samePolicy(option.Policy, other.Policy) {
+				l.warnf("%soptions[%d] is unreachable, options[%d] with higher weight "+
+					"has the same policy", location, i, j)
+				break
+			}
+		}
+	}
+}
+
+// restricts returns whether the policy filters any paths.
+func restricts(ext *ExtPolicy) bool {
+	if ext == nil || ext.Policy == nil {
+		return false
+	}
+	p := ext.Policy
+	return (p.ACL != nil && len(p.ACL.Entries) > 0) ||
+		(p.Sequence != nil && p.Sequence.String() != "") ||
+		len(p.Options) > 0 || p.MaxLatency != nil || p.MinBandwidth != 0 ||
+		len(p.LinkTypes) > 0 || len(p.DenyCountries) > 0 || len(p.Geofence) > 0
+}
+
+func samePolicy(a, b *ExtPolicy) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
+
+// matchesNothing returns whether the sequence can never match the string
+// representation of a path, see Sequence.Eval. The regular expression of the
+// sequence is evaluated against an automaton that accepts all well formed
+// path strings, i.e., at least two hops, where the first hop has ingress
+// interface 0 and the last hop has egress interface 0.
+func (s *Sequence) matchesNothing() bool {
+	if s == nil || s.re == nil {
+		return false
+	}
+	re, err := syntax.Parse(s.restr, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return false
+	}
+	type state struct {
+		pc  uint32
+		hop hopState
+	}
+	var queue []state
+	seen := make(map[state]bool)
+	// push adds the states that are reachable from pc without consuming any
+	// input. It returns whether the sequence matches at this point.
+	push := func(pc uint32, hop hopState, start bool) bool {
+		for _, next := range progClosure(prog, pc, start) {
+			if next.match {
+				if hop.accepting() {
+					return true
+				}
+				continue
+			}
+			st := state{pc: next.pc, hop: hop}
+			if !seen[st] {
+				seen[st] = true
+				queue = append(queue, st)
+			}
+		}
+		return false
+	}
+	if push(uint32(prog.Start), hopState{}, true) {
+		return false
+	}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+		inst := &prog.Inst[st.pc]
+		for _, r := range pathAlphabet {
+			if !inst.MatchRune(r) {
+				continue
+			}
+			for _, hop := range st.hop.next(r) {
+				if push(inst.Out, hop, false) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// pathAlphabet contains all characters that appear in path strings.
+const pathAlphabet = "0123456789abcdef:-#, "
+
+type closureState struct {
+	pc    uint32
+	match bool
+}
+
+// progClosure returns the instructions that consume input and that are
+// reachable from pc without consuming input. A match instruction is returned
+// with match set. Empty width assertions other than the beginning and end of
+// text are assumed to hold.
+func progClosure(prog *syntax.Prog, pc uint32, start bool) []closureState {
+	var result []closureState
+	seen := make(map[uint32]bool)
+	var visit func(pc uint32, end bool)
+	visit = func(pc uint32, end bool) {
+		if seen[pc] {
+			return
+		}
+		seen[pc] = true
+		inst := &prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(inst.Out, end)
+			visit(inst.Arg, end)
+		case syntax.InstCapture, syntax.InstNop:
+			visit(inst.Out, end)
+		case syntax.InstEmptyWidth:
+			op := syntax.EmptyOp(inst.Arg)
+			if op&(syntax.EmptyBeginText|syntax.EmptyBeginLine) != 0 && !start {
+				return
+			}
+			visit(inst.Out, end || op&(syntax.EmptyEndText|syntax.EmptyEndLine) != 0)
+		case syntax.InstMatch:
+			result = append(result, closureState{pc: pc, match: true})
+		case syntax.InstFail:
+		default:
+			// After the end of text assertion no more input can be consumed.
+			if !end {
+				result = append(result, closureState{pc: pc})
+			}
+		}
+	}
+	visit(pc, false)
+	return result
+}
+
+// hopRole is the position of a hop in a path.
+type hopRole uint8
+
+const (
+	hopFirst hopRole = iota
+	hopMiddle
+	hopLast
+)
+
+// hopField is the part of a hop that is currently read.
+type hopField uint8
+
+const (
+	fieldISD hopField = iota
+	fieldAS
+	fieldIngress
+	fieldEgress
+	fieldDone
+)
+
+// hopState is the state of an automaton that accepts well formed path
+// strings. Each hop has the form <ISD>-<AS>#<ingress>,<egress> followed by a
+// space. The automaton loosely accepts any ISD and AS number, but enforces
+// the interface numbers.
+type hopState struct {
+	role  hopRole
+	field hopField
+	// read indicates that at least one character of the current field was
+	// read.
+	read bool
+	// zero indicates that the current interface field is 0.
+	zero bool
+}
+
+func (h hopState) accepting() bool {
+	return h.role == hopLast && h.field == fieldDone
+}
+
+// next returns the states that are reachable by reading r.
+func (h hopState) next(r rune) []hopState {
+	digit := r >= '0' && r <= '9'
+	switch h.field {
+	case fieldISD:
+		switch {
+		case digit:
+			return []hopState{{role: h.role, field: fieldISD, read: true}}
+		case r == '-' && h.read:
+			return []hopState{{role: h.role, field: fieldAS}}
+		}
+	case fieldAS:
+		switch {
+		case digit || (r >= 'a' && r <= 'f') || r == ':':
+			return []hopState{{role: h.role, field: fieldAS, read: true}}
+		case r == '#' && h.read:
+			return []hopState{{role: h.role, field: fieldIngress}}
+		}
+	case fieldIngress, fieldEgress:
+		// The ingress interface of the first hop and the egress interface
+		// of the last hop are 0, all other interfaces are not.
+		mustZero := (h.field == fieldIngress && h.role == hopFirst) ||
+			(h.field == fieldEgress && h.role == hopLast)
+		switch {
+		case digit && !h.read && (r == '0') == mustZero:
+			return []hopState{{role: h.role, field: h.field, read: true, zero: r == '0'}}
+		case digit && h.read && !h.zero:
+			return []hopState{{role: h.role, field: h.field, read: true}}
+		case r == ',' && h.field == fieldIngress && h.read:
+			return []hopState{{role: h.role, field: fieldEgress}}
+		case r == ' ' && h.field == fieldEgress && h.read:
+			return []hopState{{role: h.role, field: fieldDone}}
+		}
+	case fieldDone:
+		if h.role == hopLast {
+			return nil
+		}
+		// A first or middle hop is followed by a middle or the last hop.
+		var next []hopState
+		for _, role := range []hopRole{hopMiddle, hopLast} {
+			next = append(next, hopState{role: role, field: fieldISD}.next(r)...)
+		}
+		return next
+	}
+	return nil
+}
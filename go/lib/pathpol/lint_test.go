@@ -0,0 +1,147 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyMapLint(t *testing.T) {
+	tests := map[string]struct {
+		Policies PolicyMap
+		Expected []Issue
+	}{
+		"valid": {
+			Policies: PolicyMap{
+				"base": &ExtPolicy{Policy: &Policy{
+					ACL: &ACL{Entries: []*ACLEntry{
+						{Action: Deny, Rule: mustHopPredicate(t, "1-ff00:0:133#0")},
+						{Action: Allow},
+					}},
+					Sequence: newSequence(t, "1-ff00:0:110#0 0* 1-ff00:0:111#0"),
+				}},
+				"extending": &ExtPolicy{
+					Extends: []string{"base"},
+					Policy: &Policy{Options: []Option{
+						{Weight: 1, Policy: &ExtPolicy{Policy: &Policy{
+							Sequence: newSequence(t, "0* 1-ff00:0:112 0*"),
+						}}},
+						{Weight: 0, Policy: &ExtPolicy{Policy: &Policy{}}},
+					}},
+				},
+			},
+		},
+		"unknown extends": {
+			Policies: PolicyMap{
+				"policy": &ExtPolicy{Extends: []string{"missing"}},
+			},
+			Expected: []Issue{
+				{Policy: "policy", Severity: SeverityError,
+					Msg: `extends unknown policy "missing"`},
+			},
+		},
+		"circular extends": {
+			Policies: PolicyMap{
+				"a":     &ExtPolicy{Extends: []string{"b"}},
+				"b":     &ExtPolicy{Extends: []string{"a"}},
+				"c":     &ExtPolicy{Extends: []string{"c"}},
+				"outer": &ExtPolicy{Extends: []string{"a"}},
+			},
+			Expected: []Issue{
+				{Policy: "a", Severity: SeverityError, Msg: "circular extension a -> b -> a"},
+				{Policy: "b", Severity: SeverityError, Msg: "circular extension b -> a -> b"},
+				{Policy: "c", Severity: SeverityError, Msg: "circular extension c -> c"},
+			},
+		},
+		"ACL without default": {
+			Policies: PolicyMap{
+				"policy": &ExtPolicy{Policy: &Policy{
+					ACL: &ACL{Entries: []*ACLEntry{
+						{Action: Allow, Rule: mustHopPredicate(t, "1-ff00:0:133#0")},
+					}},
+				}},
+			},
+			Expected: []Issue{
+				{Policy: "policy", Severity: SeverityError,
+					Msg: "ACL has no default rule, the last entry must match all hops"},
+			},
+		},
+		"unreachable options": {
+			Policies: PolicyMap{
+				"policy": &ExtPolicy{Policy: &Policy{Options: []Option{
+					{Weight: 3, Policy: &ExtPolicy{Policy: &Policy{
+						Sequence: newSequence(t, "0* 1-ff00:0:112 0*"),
+					}}},
+					{Weight: 2, Policy: &ExtPolicy{Policy: &Policy{
+						Sequence: newSequence(t, "0* 1-ff00:0:112 0*"),
+					}}},
+					{Weight: 1, Policy: &ExtPolicy{}},
+					{Weight: 0, Policy: &ExtPolicy{Policy: &Policy{MinBandwidth: 10}}},
+				}}},
+			},
+			Expected: []Issue{
+				{Policy: "policy", Severity: SeverityWarning, Msg: "options[1] is " +
+					"unreachable, options[0] with higher weight has the same policy"},
+				{Policy: "policy", Severity: SeverityWarning, Msg: "options[3] is " +
+					"unreachable, options[2] with higher weight matches all paths"},
+			},
+		},
+		"sequence in option": {
+			Policies: PolicyMap{
+				"policy": &ExtPolicy{Policy: &Policy{Options: []Option{
+					{Weight: 0, Policy: &ExtPolicy{Policy: &Policy{
+						Sequence: newSequence(t, "1-ff00:0:110"),
+					}}},
+				}}},
+			},
+			Expected: []Issue{
+				{Policy: "policy", Severity: SeverityWarning,
+					Msg: `options[0]: sequence "1-ff00:0:110" can never match a path`},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Policies.Lint())
+		})
+	}
+}
+
+func TestSequenceMatchesNothing(t *testing.T) {
+	tests := map[string]bool{
+		"":                                  false,
+		"0*":                                false,
+		"0+":                                false,
+		"0 0":                               false,
+		"1-ff00:0:110#0,1 1-ff00:0:111#2,0": false,
+		"1-ff00:0:110#1 0* 1-ff00:0:111#2":  false,
+		"1-ff00:0:110 (0 | 1-ff00:0:112)?":  false,
+		"1-ff00:0:110 1-ff00:0:112? 0":      false,
+		"1-ff00:0:110":                      true,
+		"0 0?":                              false,
+		"(1-ff00:0:110 | 2)":                true,
+		"1-ff00:0:110#1,2 0*":               true,
+		"0* 1-ff00:0:111#1,2":               true,
+		"1-FF00:0:110 0*":                   true,
+		"(1-ff00:0:110#1,2 0) | (0 1-ff00:0:111#1,2)": true,
+	}
+	for seq, expected := range tests {
+		t.Run(seq, func(t *testing.T) {
+			assert.Equal(t, expected, newSequence(t, seq).matchesNothing())
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// PathMetadata contains the static properties of a path as they are
+// advertised in the StaticInfo beacon extension. It is used to evaluate the
+// metadata attributes of a policy, e.g., max_latency. Values that are not
+// known are zero.
+type PathMetadata struct {
+	// Latency is the end-to-end propagation delay of the path.
+	Latency time.Duration
+	// Bandwidth is the bottleneck bandwidth of the path in Kbit/s.
+	Bandwidth uint64
+	// LinkTypes are the types of the inter-AS links on the path.
+	LinkTypes []LinkType
+	// Geo are the locations of the routers on the path.
+	Geo []GeoCoordinates
+	// InternalHops is the number of AS internal hops on the path.
+	InternalHops uint32
+}
+
+// MetadataPath is a path that carries static metadata. Paths that neither
+// implement this interface nor carry per hop metadata in their snet
+// metadata do not match any of the metadata attributes of a policy.
+type MetadataPath interface {
+	Path
+	// PathMetadata returns the static metadata of the path.
+	PathMetadata() *PathMetadata
+}
+
+func metadata(path Path) *PathMetadata {
+	switch p := path.(type) {
+	case MetadataPath:
+		return p.PathMetadata()
+	case interface{ Metadata() snet.PathMetadata }:
+		if md := p.Metadata(); md != nil {
+			return MetadataFromHops(md.Hops())
+		}
+	}
+	return nil
+}
+
+// MetadataFromHops aggregates the per hop metadata of a path. The latency is
+// the sum of the advertised latencies, the bandwidth is the smallest
+// advertised bandwidth. It returns nil if there are no hops.
+//
+// The hops do not indicate whether a value is known, unknown values are zero.
+// Hops that do not advertise a latency thus add nothing to the latency, and the
+// latency of a path that is only partially known is a lower bound.
+func MetadataFromHops(hops []snet.HopMetadata) *PathMetadata {
+	if len(hops) == 0 {
+		return nil
+	}
+	md := &PathMetadata{}
+	for _, hop := range hops {
+		md.Latency += hop.InternalLatency + hop.LinkLatency + hop.PeeringLatency
+		for _, bw := range []uint64{hop.InternalBandwidth, hop.LinkBandwidth} {
+			if bw != 0 && (md.Bandwidth == 0 || bw < md.Bandwidth) {
+				md.Bandwidth = bw
+			}
+		}
+		for _, lt := range []snet.LinkType{hop.LinkType, hop.PeeringLinkType} {
+			if t, ok := snetLinkTypes[lt]; ok {
+				md.LinkTypes = append(md.LinkTypes, t)
+			}
+		}
+		for _, loc := range hop.Geo {
+			md.Geo = append(md.Geo, GeoCoordinates{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Address:   loc.Address,
+			})
+		}
+		md.InternalHops += hop.InternalHops
+	}
+	return md
+}
+
+// LinkType is the type of an inter-AS link.
+type LinkType uint16
+
+// The link types as they are defined in the StaticInfo beacon extension.
+const (
+	// LinkTypeDirect is a direct physical connection.
+	LinkTypeDirect LinkType = iota
+	// LinkTypeMultiHop is a connection over multiple hops of a local network.
+	LinkTypeMultiHop
+	// LinkTypeOpenNet is a connection over the public Internet.
+	LinkTypeOpenNet
+)
+
+var snetLinkTypes = map[snet.LinkType]LinkType{
+	snet.LinkTypeDirect:   LinkTypeDirect,
+	snet.LinkTypeMultihop: LinkTypeMultiHop,
+	snet.LinkTypeOpennet:  LinkTypeOpenNet,
+}
+
+var linkTypeNames = map[LinkType]string{
+	LinkTypeDirect:   "direct",
+	LinkTypeMultiHop: "multihop",
+	LinkTypeOpenNet:  "opennet",
+}
+
+func (t LinkType) String() string {
+	if name, ok := linkTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func (t LinkType) MarshalText() ([]byte, error) {
+	name, ok := linkTypeNames[t]
+	if !ok {
+		return nil, serrors.New("unknown link type", "type", uint16(t))
+	}
+	return []byte(name), nil
+}
+
+func (t *LinkType) UnmarshalText(b []byte) error {
+	for lt, name := range linkTypeNames {
+		if strings.EqualFold(name, string(b)) {
+			*t = lt
+			return nil
+		}
+	}
+	return serrors.New("unknown link type", "type", string(b))
+}
+
+// GeoCoordinates is the location of a router on a path.
+type GeoCoordinates struct {
+	Latitude  float32
+	Longitude float32
+	// Address is the civic address of the location. To be matched by the
+	// deny_countries attribute of a policy, the address must end with the
+	// ISO 3166-1 alpha-2 code of the country as last comma separated element,
+	// e.g., "Zürich, CH".
+	Address string
+}
+
+// Country returns the upper case ISO 3166-1 alpha-2 country code of the
+// location. It returns an empty string if the address does not end with a
+// country code.
+func (g GeoCoordinates) Country() string {
+	parts := strings.Split(g.Address, ",")
+	code := strings.TrimSpace(parts[len(parts)-1])
+	if len(code) != 2 || !isASCIILetter(code[0]) || !isASCIILetter(code[1]) {
+		return ""
+	}
+	return strings.ToUpper(code)
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// GeoBox is a geographic area delimited by a minimum and a maximum latitude
+// and longitude, in degrees.
+type GeoBox struct {
+	MinLatitude  float32 `json:"min_latitude" yaml:"min_latitude"`
+	MinLongitude float32 `json:"min_longitude" yaml:"min_longitude"`
+	MaxLatitude  float32 `json:"max_latitude" yaml:"max_latitude"`
+	MaxLongitude float32 `json:"max_longitude" yaml:"max_longitude"`
+}
+
+// Contains returns whether the location lies in the box.
+func (b GeoBox) Contains(g GeoCoordinates) bool {
+	return g.Latitude >= b.MinLatitude && g.Latitude <= b.MaxLatitude &&
+		g.Longitude >= b.MinLongitude && g.Longitude <= b.MaxLongitude
+}
+
+// SortKey is the property of a path by which a policy orders paths.
+type SortKey string
+
+const (
+	// SortByLatency orders paths by ascending latency.
+	SortByLatency SortKey = "latency"
+	// SortByBandwidth orders paths by descending bandwidth.
+	SortByBandwidth SortKey = "bandwidth"
+	// SortByHops orders paths by ascending number of AS hops.
+	SortByHops SortKey = "hops"
+	// SortByMTU orders paths by descending MTU.
+	SortByMTU SortKey = "mtu"
+	// SortByExpiry orders paths by descending expiration time.
+	SortByExpiry SortKey = "expiry"
+)
+
+func (k *SortKey) UnmarshalText(b []byte) error {
+	switch key := SortKey(b); key {
+	case SortByLatency, SortByBandwidth, SortByHops, SortByMTU, SortByExpiry:
+		*k = key
+		return nil
+	default:
+		return serrors.New("unknown sort key", "key", string(b))
+	}
+}
+
+// evalMetadata returns the set of paths that match the metadata attributes of
+// the policy.
+func (p *Policy) evalMetadata(inputSet PathSet) PathSet {
+	if p.MaxLatency == nil && p.MinBandwidth == 0 && len(p.LinkTypes) == 0 &&
+		len(p.DenyCountries) == 0 && len(p.Geofence) == 0 {
+
+		return inputSet
+	}
+	resultSet := make(PathSet)
+	for key, path := range inputSet {
+		if p.matchMetadata(metadata(path)) {
+			resultSet[key] = path
+		}
+	}
+	return resultSet
+}
+
+func (p *Policy) matchMetadata(md *PathMetadata) bool {
+	if md == nil {
+		return false
+	}
+	if p.MaxLatency != nil && (md.Latency == 0 || md.Latency > p.MaxLatency.Duration) {
+		return false
+	}
+	if p.MinBandwidth != 0 && md.Bandwidth < p.MinBandwidth {
+		return false
+	}
+	if len(p.LinkTypes) != 0 {
+		for _, lt := range md.LinkTypes {
+			if !containsLinkType(p.LinkTypes, lt) {
+				return false
+			}
+		}
+	}
+	for _, loc := range md.Geo {
+		if len(p.DenyCountries) != 0 {
+			// A location without a country code might be in one of the
+			// denied countries, so it does not match.
+			country := loc.Country()
+			if country == "" {
+				return false
+			}
+			for _, denied := range p.DenyCountries {
+				if strings.EqualFold(country, denied) {
+					return false
+				}
+			}
+		}
+		if len(p.Geofence) != 0 && !inGeofence(p.Geofence, loc) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsLinkType(types []LinkType, lt LinkType) bool {
+	for _, t := range types {
+		if t == lt {
+			return true
+		}
+	}
+	return false
+}
+
+func inGeofence(fence []GeoBox, loc GeoCoordinates) bool {
+	for _, box := range fence {
+		if box.Contains(loc) {
+			return true
+		}
+	}
+	return false
+}
+
+// Less reports whether path a is preferred over path b according to the sort
+// attribute of the policy. Paths with unknown metadata are ordered after paths
+// with known metadata. The MTU and the expiration time are only known for
+// paths that carry snet metadata. If the policy has no sort attribute, no path
+// is preferred.
+func (p *Policy) Less(a, b Path) bool {
+	if p == nil {
+		return false
+	}
+	switch p.Sort {
+	case SortByHops:
+		return len(a.Interfaces()) < len(b.Interfaces())
+	case SortByLatency:
+		la, lb := latency(a), latency(b)
+		return la != 0 && (lb == 0 || la < lb)
+	case SortByBandwidth:
+		return bandwidth(a) > bandwidth(b)
+	case SortByMTU:
+		return mtu(a) > mtu(b)
+	case SortByExpiry:
+		return expiry(a).After(expiry(b))
+	default:
+		return false
+	}
+}
+
+// SortedPaths returns the paths of the set ordered according to the sort
+// attribute of the policy. Paths that are ranked equally are ordered by their
+// fingerprint, so that the result is deterministic.
+func (p *Policy) SortedPaths(paths PathSet) []Path {
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	result := make([]Path, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, paths[snet.PathFingerprint(key)])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return p.Less(result[i], result[j])
+	})
+	return result
+}
+
+func latency(path Path) time.Duration {
+	if md := metadata(path); md != nil {
+		return md.Latency
+	}
+	return 0
+}
+
+func bandwidth(path Path) uint64 {
+	if md := metadata(path); md != nil {
+		return md.Bandwidth
+	}
+	return 0
+}
+
+func mtu(path Path) uint16 {
+	if p, ok := path.(interface{ Metadata() snet.PathMetadata }); ok {
+		if md := p.Metadata(); md != nil {
+			return md.MTU()
+		}
+	}
+	return 0
+}
+
+func expiry(path Path) time.Time {
+	if p, ok := path.(interface{ Metadata() snet.PathMetadata }); ok {
+		if md := p.Metadata(); md != nil {
+			return md.Expiry()
+		}
+	}
+	return time.Time{}
+}
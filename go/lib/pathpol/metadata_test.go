@@ -0,0 +1,383 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestMetadataEval(t *testing.T) {
+	paths := metadataPaths()
+	tests := map[string]struct {
+		Policy   *Policy
+		Expected []string
+	}{
+		"no metadata attributes": {
+			Policy:   &Policy{},
+			Expected: []string{"fast", "slow", "wide", "unknown"},
+		},
+		"max latency": {
+			Policy:   &Policy{MaxLatency: &util.DurWrap{Duration: 50 * time.Millisecond}},
+			Expected: []string{"fast", "wide"},
+		},
+		"min bandwidth": {
+			Policy:   &Policy{MinBandwidth: 10000},
+			Expected: []string{"slow", "wide"},
+		},
+		"allowed link types": {
+			Policy:   &Policy{LinkTypes: []LinkType{LinkTypeDirect, LinkTypeMultiHop}},
+			Expected: []string{"fast", "slow"},
+		},
+		"deny countries": {
+			Policy:   &Policy{DenyCountries: []string{"de"}},
+			Expected: []string{"fast", "slow"},
+		},
+		"geofence": {
+			Policy: &Policy{Geofence: []GeoBox{
+				{MinLatitude: 45, MinLongitude: 5, MaxLatitude: 48, MaxLongitude: 11},
+			}},
+			Expected: []string{"fast", "slow"},
+		},
+		"combined": {
+			Policy: &Policy{
+				MaxLatency:   &util.DurWrap{Duration: 100 * time.Millisecond},
+				MinBandwidth: 5000,
+			},
+			Expected: []string{"slow", "wide"},
+		},
+		"extended": {
+			Policy: mustPolicyFromExt(t, &ExtPolicy{
+				Extends: []string{"low_latency"},
+				Policy:  &Policy{DenyCountries: []string{"DE"}},
+			}, []*ExtPolicy{{
+				Policy: &Policy{
+					Name:       "low_latency",
+					MaxLatency: &util.DurWrap{Duration: 50 * time.Millisecond},
+				},
+			}}),
+			Expected: []string{"fast"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var result []string
+			for key := range test.Policy.Filter(paths) {
+				result = append(result, string(key))
+			}
+			assert.ElementsMatch(t, test.Expected, result)
+		})
+	}
+}
+
+func TestMetadataSort(t *testing.T) {
+	paths := metadataPaths()
+	tests := map[SortKey][]string{
+		"":              {"fast", "slow", "unknown", "wide"},
+		SortByLatency:   {"fast", "wide", "slow", "unknown"},
+		SortByBandwidth: {"wide", "slow", "fast", "unknown"},
+		SortByHops:      {"fast", "wide", "unknown", "slow"},
+		SortByMTU:       {"slow", "fast", "wide", "unknown"},
+		SortByExpiry:    {"wide", "fast", "slow", "unknown"},
+	}
+	for key, expected := range tests {
+		t.Run(string(key), func(t *testing.T) {
+			policy := &Policy{Sort: key}
+			var result []string
+			for _, path := range policy.SortedPaths(paths) {
+				result = append(result, path.(*testMetaPath).name)
+			}
+			assert.Equal(t, expected, result)
+		})
+	}
+	t.Run("less", func(t *testing.T) {
+		policy := &Policy{Sort: SortByLatency}
+		sorted := []Path{paths["unknown"], paths["slow"], paths["fast"]}
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return policy.Less(sorted[i], sorted[j])
+		})
+		assert.Equal(t, []Path{paths["fast"], paths["slow"], paths["unknown"]}, sorted)
+	})
+}
+
+func TestMetadataConversion(t *testing.T) {
+	policies := PolicyMap{
+		"low_latency": &ExtPolicy{
+			Policy: &Policy{
+				MaxLatency:   &util.DurWrap{Duration: 50 * time.Millisecond},
+				MinBandwidth: 10000,
+				LinkTypes:    []LinkType{LinkTypeDirect, LinkTypeOpenNet},
+				Sort:         SortByLatency,
+			},
+		},
+		"geofenced": &ExtPolicy{
+			Extends: []string{"low_latency"},
+			Policy: &Policy{
+				ACL: &ACL{Entries: []*ACLEntry{
+					{Action: Deny, Rule: mustHopPredicate(t, "1-ff00:0:133#0")},
+					{Action: Allow, Rule: mustHopPredicate(t, "0")},
+				}},
+				Sequence:      newSequence(t, "1-ff00:0:133#0 0*"),
+				DenyCountries: []string{"DE"},
+				Geofence: []GeoBox{
+					{MinLatitude: 45, MinLongitude: 5, MaxLatitude: 48, MaxLongitude: 11},
+				},
+			},
+		},
+		"extends_only": &ExtPolicy{
+			Extends: []string{"geofenced"},
+		},
+	}
+	t.Run("json", func(t *testing.T) {
+		raw, err := json.Marshal(policies)
+		require.NoError(t, err)
+		var parsed PolicyMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, policies, parsed)
+	})
+	t.Run("yaml", func(t *testing.T) {
+		raw, err := yaml.Marshal(policies)
+		require.NoError(t, err)
+		var parsed PolicyMap
+		require.NoError(t, yaml.Unmarshal(raw, &parsed))
+		assert.Equal(t, policies, parsed)
+	})
+	t.Run("yaml input", func(t *testing.T) {
+		raw := `
+max_latency: 120ms
+min_bandwidth: 1000
+allowed_link_types: [direct, multihop]
+deny_countries: [DE, FR]
+geofence:
+- {min_latitude: 45, min_longitude: 5, max_latitude: 48, max_longitude: 11}
+sort: bandwidth
+`
+		var parsed ExtPolicy
+		require.NoError(t, yaml.Unmarshal([]byte(raw), &parsed))
+		expected := &Policy{
+			MaxLatency:    &util.DurWrap{Duration: 120 * time.Millisecond},
+			MinBandwidth:  1000,
+			LinkTypes:     []LinkType{LinkTypeDirect, LinkTypeMultiHop},
+			DenyCountries: []string{"DE", "FR"},
+			Geofence: []GeoBox{
+				{MinLatitude: 45, MinLongitude: 5, MaxLatitude: 48, MaxLongitude: 11},
+			},
+			Sort: SortByBandwidth,
+		}
+		assert.Equal(t, expected, parsed.Policy)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var parsed Policy
+		assert.Error(t, json.Unmarshal([]byte(`{"allowed_link_types":["fiber"]}`), &parsed))
+		assert.Error(t, json.Unmarshal([]byte(`{"sort":"cost"}`), &parsed))
+		assert.Error(t, yaml.Unmarshal([]byte(`sort: cost`), &parsed))
+		assert.Error(t, yaml.Unmarshal([]byte(`max_latency: fast`), &parsed))
+	})
+}
+
+func TestMetadataFromHops(t *testing.T) {
+	assert.Nil(t, MetadataFromHops(nil))
+	hops := []snet.HopMetadata{
+		{
+			IA:              xtest.MustParseIA("1-ff00:0:110"),
+			InternalLatency: 2 * time.Millisecond,
+			LinkLatency:     10 * time.Millisecond,
+			LinkBandwidth:   1000,
+			LinkType:        snet.LinkTypeDirect,
+			Geo:             []snet.GeoCoordinates{{Latitude: 47.37, Address: "Zürich, CH"}},
+			InternalHops:    2,
+		},
+		{
+			IA:                xtest.MustParseIA("1-ff00:0:111"),
+			PeeringLatency:    5 * time.Millisecond,
+			InternalBandwidth: 500,
+			PeeringLinkType:   snet.LinkTypeOpennet,
+			InternalHops:      1,
+		},
+	}
+	expected := &PathMetadata{
+		Latency:      17 * time.Millisecond,
+		Bandwidth:    500,
+		LinkTypes:    []LinkType{LinkTypeDirect, LinkTypeOpenNet},
+		Geo:          []GeoCoordinates{{Latitude: 47.37, Address: "Zürich, CH"}},
+		InternalHops: 3,
+	}
+	assert.Equal(t, expected, MetadataFromHops(hops))
+
+	path := testSnetPath{hops: hops}
+	policy := &Policy{MaxLatency: &util.DurWrap{Duration: 20 * time.Millisecond}}
+	assert.Len(t, policy.Filter(PathSet{"snet": path}), 1)
+	policy = &Policy{LinkTypes: []LinkType{LinkTypeDirect}}
+	assert.Len(t, policy.Filter(PathSet{"snet": path}), 0)
+}
+
+func TestGeoCoordinatesCountry(t *testing.T) {
+	testCases := map[string]string{
+		"Universitätstrasse 6, Zürich, CH": "CH",
+		"Zürich, ch ":                      "CH",
+		"CH":                               "CH",
+		"":                                 "",
+		"Zürich":                           "",
+		"Zürich, Switzerland":              "",
+		"Zürich, C1":                       "",
+		"Zürich, CH,":                      "",
+	}
+	for address, expected := range testCases {
+		assert.Equal(t, expected, GeoCoordinates{Address: address}.Country(), address)
+	}
+}
+
+func TestDenyCountriesUnknownCountry(t *testing.T) {
+	md := &PathMetadata{Geo: []GeoCoordinates{
+		{Latitude: 47.37, Address: "Zürich, CH"},
+		{Latitude: 50.11, Address: "Frankfurt, Germany"},
+	}}
+	// The country of Frankfurt can not be determined, the path might traverse
+	// a denied country.
+	assert.False(t, (&Policy{DenyCountries: []string{"DE"}}).matchMetadata(md))
+	assert.True(t, (&Policy{}).matchMetadata(md))
+}
+
+// metadataPaths returns a set of paths with different metadata. The paths are
+// keyed by their name.
+func metadataPaths() PathSet {
+	zurich := GeoCoordinates{Latitude: 47.37, Longitude: 8.54, Address: "Zürich, CH"}
+	geneva := GeoCoordinates{Latitude: 46.2, Longitude: 6.14, Address: "Geneva, CH"}
+	frankfurt := GeoCoordinates{Latitude: 50.11, Longitude: 8.68, Address: "Frankfurt, DE"}
+	now := time.Unix(1600000000, 0)
+	paths := []*testMetaPath{
+		{
+			name: "fast",
+			hops: 2,
+			md: &PathMetadata{
+				Latency:   10 * time.Millisecond,
+				Bandwidth: 1000,
+				LinkTypes: []LinkType{LinkTypeDirect},
+				Geo:       []GeoCoordinates{zurich, geneva},
+			},
+			mtu:    1400,
+			expiry: now.Add(2 * time.Hour),
+		},
+		{
+			name: "slow",
+			hops: 4,
+			md: &PathMetadata{
+				Latency:   80 * time.Millisecond,
+				Bandwidth: 10000,
+				LinkTypes: []LinkType{LinkTypeDirect, LinkTypeMultiHop},
+				Geo:       []GeoCoordinates{zurich},
+			},
+			mtu:    1472,
+			expiry: now.Add(time.Hour),
+		},
+		{
+			name: "wide",
+			hops: 2,
+			md: &PathMetadata{
+				Latency:   40 * time.Millisecond,
+				Bandwidth: 100000,
+				LinkTypes: []LinkType{LinkTypeOpenNet},
+				Geo:       []GeoCoordinates{zurich, frankfurt},
+			},
+			mtu:    1280,
+			expiry: now.Add(3 * time.Hour),
+		},
+		{
+			name: "unknown",
+			hops: 3,
+		},
+	}
+	set := make(PathSet)
+	for _, path := range paths {
+		set[snet.PathFingerprint(path.name)] = path
+	}
+	return set
+}
+
+type testMetaPath struct {
+	name   string
+	hops   int
+	md     *PathMetadata
+	mtu    uint16
+	expiry time.Time
+}
+
+func (p *testMetaPath) Interfaces() []snet.PathInterface {
+	ifaces := make([]snet.PathInterface, 2*(p.hops-1))
+	for i := range ifaces {
+		ifaces[i] = testPathIntf{ia: xtest.MustParseIA("1-ff00:0:110")}
+	}
+	return ifaces
+}
+
+func (p *testMetaPath) PathMetadata() *PathMetadata {
+	return p.md
+}
+
+func (p *testMetaPath) Metadata() snet.PathMetadata {
+	return p
+}
+
+func (p *testMetaPath) MTU() uint16 {
+	return p.mtu
+}
+
+func (p *testMetaPath) Expiry() time.Time {
+	return p.expiry
+}
+
+func (p *testMetaPath) Hops() []snet.HopMetadata {
+	return nil
+}
+
+func mustPolicyFromExt(t *testing.T, ext *ExtPolicy, extended []*ExtPolicy) *Policy {
+	policy, err := PolicyFromExtPolicy(ext, extended)
+	require.NoError(t, err)
+	return policy
+}
+
+type testSnetPath struct {
+	hops []snet.HopMetadata
+}
+
+func (p testSnetPath) Interfaces() []snet.PathInterface {
+	return nil
+}
+
+func (p testSnetPath) Metadata() snet.PathMetadata {
+	return p
+}
+
+func (p testSnetPath) MTU() uint16 {
+	return 1472
+}
+
+func (p testSnetPath) Expiry() time.Time {
+	return time.Time{}
+}
+
+func (p testSnetPath) Hops() []snet.HopMetadata {
+	return p.hops
+}
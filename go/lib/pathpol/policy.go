@@ -14,15 +14,32 @@
 // limitations under the License.
 
 // Package pathpol implements path policies, documentation in doc/PathPolicy.md
-// Currently implemented: ACL, Sequence, Extends and Options.
+// Currently implemented: ACL, Sequence, Extends, Options, the metadata
+// attributes max_latency, min_bandwidth, allowed_link_types, deny_countries
+// and geofence, and Sort.
 //
 // A policy has an Act() method that takes an AppPathSet and returns a filtered AppPathSet
 package pathpol
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+var (
+	// ErrCircularExtends indicates that a policy directly or indirectly
+	// extends itself.
+	ErrCircularExtends = serrors.New("circular policy extension")
 )
 
 // ExtPolicy is an extending policy, it may have a list of policies it extends
@@ -31,11 +48,116 @@ type ExtPolicy struct {
 	*Policy
 }
 
+// extPolicyYAML is the YAML representation of an ExtPolicy. The YAML encoder
+// can not inline the embedded policy pointer.
+type extPolicyYAML struct {
+	Extends []string `yaml:"extends,omitempty"`
+	Policy  `yaml:",inline"`
+}
+
+func (p *ExtPolicy) MarshalYAML() (interface{}, error) {
+	y := extPolicyYAML{Extends: p.Extends}
+	if p.Policy != nil {
+		y.Policy = *p.Policy
+	}
+	return y, nil
+}
+
+func (p *ExtPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y extPolicyYAML
+	if err := unmarshal(&y); err != nil {
+		return err
+	}
+	p.Extends = y.Extends
+	// Like the JSON decoder, only allocate the policy if it has attributes.
+	if !reflect.DeepEqual(y.Policy, Policy{}) {
+		p.Policy = &y.Policy
+	}
+	return nil
+}
+
 // PolicyMap is a container for Policies, keyed by their unique name. PolicyMap
-// can be used to marshal Policies to JSON. Unmarshaling back to PolicyMap is
-// guaranteed to yield an object that is identical to the initial one.
+// can be used to marshal Policies to JSON or YAML. Unmarshaling back to
+// PolicyMap is guaranteed to yield an object that is identical to the initial
+// one.
 type PolicyMap map[string]*ExtPolicy
 
+// LoadPolicyMap loads a map of named policies from a file. Files with the
+// extension .yml or .yaml are parsed as YAML, all other files as JSON.
+func LoadPolicyMap(file string) (PolicyMap, error) {
+	var policies PolicyMap
+	if err := loadFile(file, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// LoadPolicy loads a single policy from a file, the supported formats are the
+// same as for LoadPolicyMap. The policy must not extend other policies.
+func LoadPolicy(file string) (*Policy, error) {
+	var ext ExtPolicy
+	if err := loadFile(file, &ext); err != nil {
+		return nil, err
+	}
+	policy, err := PolicyFromExtPolicy(&ext, nil)
+	if err != nil {
+		return nil, serrors.WrapStr("compiling policy", err, "file", file)
+	}
+	return policy, nil
+}
+
+func loadFile(file string, v interface{}) error {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return serrors.WrapStr("reading policy file", err, "file", file)
+	}
+	switch filepath.Ext(file) {
+	case ".yml", ".yaml":
+		err = yaml.UnmarshalStrict(raw, v)
+	default:
+		err = json.Unmarshal(raw, v)
+	}
+	if err != nil {
+		return serrors.WrapStr("parsing policy file", err, "file", file)
+	}
+	return nil
+}
+
+// Policy compiles the policy with the given name. The policies it extends are
+// looked up in the map.
+func (m PolicyMap) Policy(name string) (*Policy, error) {
+	ext, ok := m[name]
+	if !ok {
+		return nil, serrors.New("policy not found", "name", name)
+	}
+	return m.Compile(named(name, ext))
+}
+
+// Compile compiles the extending policy. The policies it extends are looked up
+// in the map. Neither ext nor the policies in the map are modified.
+func (m PolicyMap) Compile(ext *ExtPolicy) (*Policy, error) {
+	extended := make([]*ExtPolicy, 0, len(m))
+	for name, p := range m {
+		extended = append(extended, named(name, p))
+	}
+	var name string
+	if ext.Policy != nil {
+		name = ext.Policy.Name
+	}
+	return PolicyFromExtPolicy(named(name, ext), extended)
+}
+
+// named returns a copy of the extending policy with the given name. The copy
+// can be compiled without modifying the original policy.
+func named(name string, ext *ExtPolicy) *ExtPolicy {
+	policy := &Policy{}
+	if ext.Policy != nil {
+		*policy = *ext.Policy
+	}
+	policy.Name = name
+	return &ExtPolicy{Extends: ext.Extends, Policy: policy}
+}
+
 // FilterOptions contains options for filtering.
 type FilterOptions struct {
 	// IgnoreSequence can be used to ignore the sequence part of policies.
@@ -44,10 +166,22 @@ type FilterOptions struct {
 
 // Policy is a compiled path policy object, all extended policies have been merged.
 type Policy struct {
-	Name     string    `json:"-"`
-	ACL      *ACL      `json:"acl,omitempty"`
-	Sequence *Sequence `json:"sequence,omitempty"`
-	Options  []Option  `json:"options,omitempty"`
+	Name     string    `json:"-" yaml:"-"`
+	ACL      *ACL      `json:"acl,omitempty" yaml:"acl,omitempty"`
+	Sequence *Sequence `json:"sequence,omitempty" yaml:"sequence,omitempty"`
+	Options  []Option  `json:"options,omitempty" yaml:"options,omitempty"`
+	// MaxLatency is the maximum end-to-end latency of a path.
+	MaxLatency *util.DurWrap `json:"max_latency,omitempty" yaml:"max_latency,omitempty"`
+	// MinBandwidth is the minimum bottleneck bandwidth of a path in Kbit/s.
+	MinBandwidth uint64 `json:"min_bandwidth,omitempty" yaml:"min_bandwidth,omitempty"`
+	// LinkTypes are the types of inter-AS links a path may use.
+	LinkTypes []LinkType `json:"allowed_link_types,omitempty" yaml:"allowed_link_types,omitempty"`
+	// DenyCountries are the countries a path must not traverse.
+	DenyCountries []string `json:"deny_countries,omitempty" yaml:"deny_countries,omitempty"`
+	// Geofence are the areas a path must stay in.
+	Geofence []GeoBox `json:"geofence,omitempty" yaml:"geofence,omitempty"`
+	// Sort is the property by which paths are ordered, see SortedPaths.
+	Sort SortKey `json:"sort,omitempty" yaml:"sort,omitempty"`
 }
 
 // NewPolicy creates a Policy and sorts its Options
@@ -75,6 +209,7 @@ func (p *Policy) FilterOpt(paths PathSet, opts FilterOptions) PathSet {
 	if p.Sequence != nil && !opts.IgnoreSequence {
 		resultSet = p.Sequence.Eval(resultSet)
 	}
+	resultSet = p.evalMetadata(resultSet)
 	// Filter on sub policies
 	if len(p.Options) > 0 {
 		resultSet = p.evalOptions(resultSet, opts)
@@ -84,12 +219,21 @@ func (p *Policy) FilterOpt(paths PathSet, opts FilterOptions) PathSet {
 
 // PolicyFromExtPolicy creates a Policy from an extending Policy and the extended policies
 func PolicyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy) (*Policy, error) {
+	return policyFromExtPolicy(extPolicy, extended, nil)
+}
+
+// policyFromExtPolicy creates a Policy from an extending Policy. The chain
+// contains the names of the policies that are currently being extended, it is
+// used to detect circular extensions.
+func policyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy,
+	chain []string) (*Policy, error) {
+
 	policy := extPolicy.Policy
 	if policy == nil {
 		policy = &Policy{}
 	}
 	// Apply all extended policies
-	if err := policy.applyExtended(extPolicy.Extends, extended); err != nil {
+	if err := policy.applyExtended(extPolicy.Extends, extended, chain); err != nil {
 		return nil, err
 	}
 	return policy, nil
@@ -97,16 +241,25 @@ func PolicyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy) (*Policy,
 
 // applyExtended adds attributes of extended policies to the extending policy if they are not
 // already set
-func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy) error {
-	// TODO(worxli): Prevent circular policies.
+func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy, chain []string) error {
+	if p.Name != "" {
+		chain = append(chain[:len(chain):len(chain)], p.Name)
+	}
 	// traverse in reverse s.t. last entry of the list has precedence
 	for i := len(extends) - 1; i >= 0; i-- {
+		for _, name := range chain {
+			if name == extends[i] {
+				return serrors.WithCtx(ErrCircularExtends,
+					"chain", strings.Join(append(chain[:len(chain):len(chain)], extends[i]), " -> "))
+			}
+		}
 		var policy *Policy
 		// Find extended policy
 		for _, exPol := range exPolicies {
 			if exPol.Name == extends[i] {
 				var err error
-				if policy, err = PolicyFromExtPolicy(exPol, exPolicies); err != nil {
+				policy, err = policyFromExtPolicy(exPol, exPolicies, chain)
+				if err != nil {
 					return err
 				}
 			}
@@ -127,6 +280,26 @@ func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy) error
 		if p.Sequence == nil {
 			p.Sequence = policy.Sequence
 		}
+		// Replace metadata attributes
+		if p.MaxLatency == nil {
+			p.MaxLatency = policy.MaxLatency
+		}
+		if p.MinBandwidth == 0 {
+			p.MinBandwidth = policy.MinBandwidth
+		}
+		if len(p.LinkTypes) == 0 {
+			p.LinkTypes = policy.LinkTypes
+		}
+		if len(p.DenyCountries) == 0 {
+			p.DenyCountries = policy.DenyCountries
+		}
+		if len(p.Geofence) == 0 {
+			p.Geofence = policy.Geofence
+		}
+		// Replace Sort
+		if p.Sort == "" {
+			p.Sort = policy.Sort
+		}
 	}
 	return nil
 }
@@ -152,6 +325,6 @@ func (p *Policy) evalOptions(inputSet PathSet, opts FilterOptions) PathSet {
 
 // Option contains a weight and a policy and is used as a list item in Policy.Options
 type Option struct {
-	Weight int        `json:"weight"`
-	Policy *ExtPolicy `json:"policy"`
+	Weight int        `json:"weight" yaml:"weight"`
+	Policy *ExtPolicy `json:"policy" yaml:"policy"`
 }
@@ -17,6 +17,7 @@ package pathpol
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -547,6 +548,49 @@ func TestExtends(t *testing.T) {
 		_, err := PolicyFromExtPolicy(extPolicy, extended)
 		assert.Error(t, err)
 	})
+
+	t.Run("TestPolicy circular extends", func(t *testing.T) {
+		tests := map[string]struct {
+			Policy   *ExtPolicy
+			Extended []*ExtPolicy
+		}{
+			"self": {
+				Policy: &ExtPolicy{Policy: &Policy{Name: "policy1"}, Extends: []string{"policy1"}},
+			},
+			"indirect": {
+				Policy: &ExtPolicy{Extends: []string{"policy1"}},
+				Extended: []*ExtPolicy{
+					{Policy: &Policy{Name: "policy1"}, Extends: []string{"policy2"}},
+					{Policy: &Policy{Name: "policy2"}, Extends: []string{"policy3"}},
+					{Policy: &Policy{Name: "policy3"}, Extends: []string{"policy1"}},
+				},
+			},
+		}
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				extended := append(test.Extended, test.Policy)
+				_, err := PolicyFromExtPolicy(test.Policy, extended)
+				assert.True(t, errors.Is(err, ErrCircularExtends), "err: %v", err)
+			})
+		}
+	})
+
+	t.Run("TestPolicy diamond extends", func(t *testing.T) {
+		extPolicy := &ExtPolicy{Extends: []string{"policy1", "policy2"}}
+		extended := []*ExtPolicy{
+			{Policy: &Policy{Name: "policy1"}, Extends: []string{"policy3"}},
+			{Policy: &Policy{Name: "policy2"}, Extends: []string{"policy3"}},
+			{
+				Policy: &Policy{
+					Name:     "policy3",
+					Sequence: newSequence(t, "1-ff00:0:133#1011 1-ff00:0:132#1911"),
+				},
+			},
+		}
+		pol, err := PolicyFromExtPolicy(extPolicy, extended)
+		require.NoError(t, err)
+		assert.Equal(t, extended[2].Sequence, pol.Sequence)
+	})
 }
 
 func TestFilterOpt(t *testing.T) {
@@ -586,6 +630,49 @@ func TestFilterOpt(t *testing.T) {
 	}
 }
 
+func TestPolicyMapCompile(t *testing.T) {
+	seq := newSequence(t, "1-ff00:0:133#1011 1-ff00:0:132#1911")
+	policies := PolicyMap{
+		"base":  &ExtPolicy{Policy: &Policy{Sequence: seq, Sort: SortByHops}},
+		"fast":  &ExtPolicy{Extends: []string{"base"}, Policy: &Policy{Sort: SortByLatency}},
+		"loop":  &ExtPolicy{Extends: []string{"loop"}},
+		"empty": &ExtPolicy{Extends: []string{"base"}},
+	}
+
+	t.Run("named", func(t *testing.T) {
+		pol, err := policies.Policy("fast")
+		require.NoError(t, err)
+		assert.Equal(t, "fast", pol.Name)
+		assert.Equal(t, seq, pol.Sequence)
+		assert.Equal(t, SortByLatency, pol.Sort)
+		assert.Nil(t, policies["fast"].Sequence, "the map must not be modified")
+
+		pol, err = policies.Policy("empty")
+		require.NoError(t, err)
+		assert.Equal(t, seq, pol.Sequence)
+		assert.Nil(t, policies["empty"].Policy, "the map must not be modified")
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := policies.Policy("unknown")
+		assert.Error(t, err)
+	})
+	t.Run("circular", func(t *testing.T) {
+		_, err := policies.Policy("loop")
+		assert.True(t, errors.Is(err, ErrCircularExtends), "err: %v", err)
+	})
+	t.Run("compile", func(t *testing.T) {
+		ext := &ExtPolicy{Extends: []string{"base"}, Policy: &Policy{MinBandwidth: 10}}
+		pol, err := policies.Compile(ext)
+		require.NoError(t, err)
+		assert.Equal(t, seq, pol.Sequence)
+		assert.Equal(t, uint64(10), pol.MinBandwidth)
+		assert.Nil(t, ext.Sequence, "the policy must not be modified")
+
+		_, err = policies.Compile(&ExtPolicy{Extends: []string{"unknown"}})
+		assert.Error(t, err)
+	})
+}
+
 func TestPolicyJsonConversion(t *testing.T) {
 	policy := NewPolicy("", nil, nil, []Option{
 		{
@@ -112,10 +112,29 @@ type Path struct {
 	spath      *spath.Path
 	mtu        uint16
 	expiry     time.Time
+	hops       []snet.HopMetadata
 	dst        addr.IA
+	health     *PathHealth
 }
 
-func pathReplyToPaths(pathReply *PathReply, dst addr.IA) ([]snet.Path, error) {
+// PathHealth is the health of a path as measured by the probes of SCIOND.
+type PathHealth struct {
+	// RTT is the smoothed round trip time of the probes. It is zero if no
+	// probe reply was received yet.
+	RTT time.Duration
+	// Loss is the smoothed ratio of lost probes, between 0 and 1.
+	Loss float64
+	// LastAlive is the last time a probe reply was received over the path.
+	LastAlive time.Time
+	// LastProbed is the last time the path was probed.
+	LastProbed time.Time
+	// Failed indicates that the last probe over the path was lost.
+	Failed bool
+}
+
+// PathReplyToPaths converts the entries of a path reply to paths to the given
+// destination.
+func PathReplyToPaths(pathReply *PathReply, dst addr.IA) ([]snet.Path, error) {
 	if pathReply.ErrorCode != ErrorOk {
 		return nil, serrors.New("Path lookup had an error", "err_code", pathReply.ErrorCode)
 	}
@@ -156,6 +175,7 @@ func pathReplyEntryToPath(pe PathReplyEntry, dst addr.IA) (Path, error) {
 		spath:      sp,
 		mtu:        pe.Path.Mtu,
 		expiry:     pe.Path.Expiry(),
+		hops:       copyHops(pe.Path.Hops),
 	}
 	for _, intf := range pe.Path.Interfaces {
 		p.interfaces = append(p.interfaces, pathInterface{ia: intf.IA(), id: intf.ID()})
@@ -211,6 +231,19 @@ func (p Path) Expiry() time.Time {
 	return p.expiry
 }
 
+func (p Path) Hops() []snet.HopMetadata {
+	return copyHops(p.hops)
+}
+
+// Health returns the health of the path as reported by SCIOND. The second
+// return value is false if SCIOND did not probe the path.
+func (p Path) Health() (PathHealth, bool) {
+	if p.health == nil {
+		return PathHealth{}, false
+	}
+	return *p.health, true
+}
+
 func (p Path) Copy() snet.Path {
 	return Path{
 		interfaces: append(p.interfaces[:0:0], p.interfaces...),
@@ -218,6 +251,9 @@ func (p Path) Copy() snet.Path {
 		spath:      p.Path(),            // creates copy
 		mtu:        p.mtu,
 		expiry:     p.expiry,
+		hops:       copyHops(p.hops),
+		dst:        p.dst,
+		health:     p.health,
 	}
 }
 
@@ -160,6 +160,10 @@ func (m pathMetadata) Expiry() time.Time {
 	return m.expirationTime
 }
 
+func (m pathMetadata) Hops() []snet.HopMetadata {
+	return nil
+}
+
 // UDPAddr decorates net.UDPAddr with custom JSON marshaling logic.
 type UDPAddr net.UDPAddr
 
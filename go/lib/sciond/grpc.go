@@ -16,15 +16,20 @@ package sciond
 
 import (
 	"context"
+	"encoding/json"
 	"net"
 	"strings"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"google.golang.org/grpc"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath"
@@ -73,13 +78,13 @@ func (c grpcConn) LocalIA(ctx context.Context) (addr.IA, error) {
 func (c grpcConn) Paths(ctx context.Context, dst, src addr.IA,
 	f PathReqFlags) ([]snet.Path, error) {
 
+	req, err := pathsRequest(dst, src, f)
+	if err != nil {
+		c.metrics.incPaths(err)
+		return nil, err
+	}
 	client := sdpb.NewDaemonServiceClient(c.conn)
-	response, err := client.Paths(ctx, &sdpb.PathsRequest{
-		SourceIsdAs:      uint64(src.IAInt()),
-		DestinationIsdAs: uint64(dst.IAInt()),
-		Hidden:           f.Hidden,
-		Refresh:          f.Refresh,
-	})
+	response, err := client.Paths(ctx, req)
 	if err != nil {
 		c.metrics.incPaths(err)
 		return nil, err
@@ -89,6 +94,56 @@ func (c grpcConn) Paths(ctx context.Context, dst, src addr.IA,
 	return paths, err
 }
 
+func (c grpcConn) WatchPaths(ctx context.Context, dst, src addr.IA,
+	f PathReqFlags) (<-chan []snet.Path, error) {
+
+	req, err := pathsRequest(dst, src, f)
+	if err != nil {
+		c.metrics.incPaths(err)
+		return nil, err
+	}
+	client := sdpb.NewDaemonServiceClient(c.conn)
+	stream, err := client.WatchPaths(ctx, &sdpb.WatchPathsRequest{
+		SourceIsdAs:      req.SourceIsdAs,
+		DestinationIsdAs: req.DestinationIsdAs,
+		Hidden:           req.Hidden,
+		Policy:           req.Policy,
+		PolicyName:       req.PolicyName,
+		MaxPaths:         req.MaxPaths,
+		Sort:             req.Sort,
+	})
+	if err != nil {
+		c.metrics.incPaths(err)
+		return nil, err
+	}
+	updates := make(chan []snet.Path)
+	go func() {
+		defer log.HandlePanic()
+		defer close(updates)
+		for {
+			response, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.FromCtx(ctx).Debug("Path watch ended", "err", err)
+				}
+				return
+			}
+			paths, err := pathResponseToPaths(response.Paths, dst)
+			c.metrics.incPaths(err)
+			if err != nil {
+				log.FromCtx(ctx).Info("Ignoring invalid path update", "err", err)
+				continue
+			}
+			select {
+			case updates <- paths:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
 func (c grpcConn) ASInfo(ctx context.Context, ia addr.IA) (ASInfo, error) {
 	client := sdpb.NewDaemonServiceClient(c.conn)
 	response, err := client.AS(ctx, &sdpb.ASRequest{IsdAs: uint64(ia.IAInt())})
@@ -174,6 +229,43 @@ func (c grpcConn) Close(_ context.Context) error {
 	return c.conn.Close()
 }
 
+// pathsRequest creates the request for the paths from src to dst.
+func pathsRequest(dst, src addr.IA, f PathReqFlags) (*sdpb.PathsRequest, error) {
+	var policy []byte
+	if f.Policy != nil {
+		var err error
+		if policy, err = json.Marshal(f.Policy); err != nil {
+			return nil, serrors.WrapStr("encoding path policy", err)
+		}
+	}
+	sortKey, ok := sortKeys[f.Sort]
+	if !ok {
+		return nil, serrors.New("unsupported sort key", "key", f.Sort)
+	}
+	if f.MaxPaths < 0 {
+		return nil, serrors.New("negative path limit", "max_paths", f.MaxPaths)
+	}
+	return &sdpb.PathsRequest{
+		SourceIsdAs:      uint64(src.IAInt()),
+		DestinationIsdAs: uint64(dst.IAInt()),
+		Hidden:           f.Hidden,
+		Refresh:          f.Refresh,
+		Policy:           policy,
+		PolicyName:       f.PolicyName,
+		MaxPaths:         uint32(f.MaxPaths),
+		Sort:             sortKey,
+	}, nil
+}
+
+var sortKeys = map[pathpol.SortKey]sdpb.PathSortKey{
+	"":                      sdpb.PathSortKey_PATH_SORT_KEY_UNSPECIFIED,
+	pathpol.SortByHops:      sdpb.PathSortKey_PATH_SORT_KEY_HOPS,
+	pathpol.SortByMTU:       sdpb.PathSortKey_PATH_SORT_KEY_MTU,
+	pathpol.SortByExpiry:    sdpb.PathSortKey_PATH_SORT_KEY_EXPIRY,
+	pathpol.SortByLatency:   sdpb.PathSortKey_PATH_SORT_KEY_LATENCY,
+	pathpol.SortByBandwidth: sdpb.PathSortKey_PATH_SORT_KEY_BANDWIDTH,
+}
+
 func pathResponseToPaths(paths []*sdpb.Path, dst addr.IA) ([]snet.Path, error) {
 	result := make([]snet.Path, 0, len(paths))
 	for _, p := range paths {
@@ -222,6 +314,76 @@ func convertPath(path *sdpb.Path, dst addr.IA) (Path, error) {
 		spath:      sp,
 		mtu:        uint16(path.Mtu),
 		expiry:     expiry,
+		hops:       convertHops(path.Hops),
 		dst:        dst,
+		health:     convertHealth(path.Health),
 	}, nil
 }
+
+func convertHops(pbHops []*sdpb.HopMetadata) []snet.HopMetadata {
+	var hops []snet.HopMetadata
+	for _, pbHop := range pbHops {
+		var geo []snet.GeoCoordinates
+		for _, loc := range pbHop.Geo {
+			geo = append(geo, snet.GeoCoordinates{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Address:   loc.Address,
+			})
+		}
+		hops = append(hops, snet.HopMetadata{
+			IA:                addr.IAInt(pbHop.IsdAs).IA(),
+			InternalLatency:   convertDuration(pbHop.InternalLatency),
+			LinkLatency:       convertDuration(pbHop.LinkLatency),
+			PeeringLatency:    convertDuration(pbHop.PeeringLatency),
+			InternalBandwidth: pbHop.InternalBandwidth,
+			LinkBandwidth:     pbHop.LinkBandwidth,
+			LinkType:          convertLinkType(pbHop.LinkType),
+			PeeringLinkType:   convertLinkType(pbHop.PeeringLinkType),
+			InternalHops:      pbHop.InternalHops,
+			Geo:               geo,
+			Note:              pbHop.Note,
+		})
+	}
+	return hops
+}
+
+func convertHealth(h *sdpb.PathHealth) *PathHealth {
+	if h == nil {
+		return nil
+	}
+	return &PathHealth{
+		RTT:        convertDuration(h.Rtt),
+		Loss:       h.Loss,
+		LastAlive:  convertTimestamp(h.LastAlive),
+		LastProbed: convertTimestamp(h.LastProbed),
+		Failed:     h.Failed,
+	}
+}
+
+func convertTimestamp(ts *timestamp.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos))
+}
+
+func convertDuration(d *duration.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(d.Seconds)*time.Second + time.Duration(d.Nanos)
+}
+
+func convertLinkType(t sdpb.LinkType) snet.LinkType {
+	switch t {
+	case sdpb.LinkType_LINK_TYPE_DIRECT:
+		return snet.LinkTypeDirect
+	case sdpb.LinkType_LINK_TYPE_MULTI_HOP:
+		return snet.LinkTypeMultihop
+	case sdpb.LinkType_LINK_TYPE_OPEN_NET:
+		return snet.LinkTypeOpennet
+	default:
+		return snet.LinkTypeUnset
+	}
+}
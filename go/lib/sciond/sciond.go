@@ -97,3 +97,13 @@ type Connector interface {
 	// Close shuts down the connection to a SCIOND server.
 	Close(ctx context.Context) error
 }
+
+// PathWatcher is implemented by connectors that can stream path updates from
+// SCIOND.
+type PathWatcher interface {
+	// WatchPaths subscribes to the set of end to end paths between the source
+	// and destination. The current paths are delivered on the returned
+	// channel immediately, and again whenever they change. The channel is
+	// closed when ctx is canceled or the subscription fails.
+	WatchPaths(ctx context.Context, dst, src addr.IA, f PathReqFlags) (<-chan []snet.Path, error)
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/util"
 )
 
@@ -70,6 +72,17 @@ func (pathReq *PathReq) String() string {
 type PathReqFlags struct {
 	Refresh bool
 	Hidden  bool
+	// Policy is a path policy that SCIOND applies to the paths.
+	Policy *pathpol.Policy
+	// PolicyName is the name of a path policy configured in SCIOND that is
+	// applied to the paths. If Policy is set as well, Policy extends the named
+	// policy.
+	PolicyName string
+	// MaxPaths is the maximum number of paths returned. Zero means no limit.
+	MaxPaths int
+	// Sort is the property by which SCIOND orders the paths. If it is empty,
+	// the sort attribute of the policy is used.
+	Sort pathpol.SortKey
 }
 
 type PathReply struct {
@@ -109,6 +122,9 @@ type FwdPathMeta struct {
 	Mtu        uint16
 	Interfaces []PathInterface
 	ExpTime    uint32
+	// Hops contains the static metadata of the ASes on the path, in path
+	// order.
+	Hops []snet.HopMetadata
 
 	HeaderV2 bool
 }
@@ -143,6 +159,19 @@ func (fpm *FwdPathMeta) Copy() *FwdPathMeta {
 		res.Interfaces = make([]PathInterface, len(fpm.Interfaces))
 		copy(res.Interfaces, fpm.Interfaces)
 	}
+	res.Hops = copyHops(fpm.Hops)
+	return res
+}
+
+func copyHops(hops []snet.HopMetadata) []snet.HopMetadata {
+	if hops == nil {
+		return nil
+	}
+	res := make([]snet.HopMetadata, len(hops))
+	for i, hop := range hops {
+		res[i] = hop
+		res[i].Geo = append(hop.Geo[:0:0], hop.Geo...)
+	}
 	return res
 }
 
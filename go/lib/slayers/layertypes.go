@@ -57,6 +57,13 @@ var (
 			Decoder: gopacket.DecodeFunc(decodeSCMPExternalInterfaceDown),
 		},
 	)
+	LayerTypeSCMPRevocation = gopacket.RegisterLayerType(
+		1009,
+		gopacket.LayerTypeMetadata{
+			Name:    "SCMPRevocation",
+			Decoder: gopacket.DecodeFunc(decodeSCMPRevocation),
+		},
+	)
 	LayerTypeSCMPInternalConnectivityDown = gopacket.RegisterLayerType(
 		1006,
 		gopacket.LayerTypeMetadata{
@@ -16,6 +16,7 @@ package slayers
 
 import (
 	"encoding/binary"
+	"math"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -103,6 +104,86 @@ func decodeSCMPExternalInterfaceDown(data []byte, pb gopacket.PacketBuilder) err
 	return pb.NextDecoder(gopacket.LayerTypePayload)
 }
 
+// SCMPRevocation is the signed revocation of an interface. It follows the
+// SCMPExternalInterfaceDown message if the code is SCMPCodeRevokedInterface,
+// and is itself followed by the quote of the offending packet. The format is
+// as follows:
+//
+//   0                   1                   2                   3
+//   0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |            Length             |                               |
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+                               +
+//  |               Signed revocation (variable length)             |
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+type SCMPRevocation struct {
+	layers.BaseLayer
+	// SignedRevInfo is the raw signed revocation.
+	SignedRevInfo []byte
+}
+
+// LayerType returns LayerTypeSCMPRevocation.
+func (*SCMPRevocation) LayerType() gopacket.LayerType {
+	return LayerTypeSCMPRevocation
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (*SCMPRevocation) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypePayload
+}
+
+// Len returns the length of the serialized revocation.
+func (i *SCMPRevocation) Len() int {
+	return 2 + len(i.SignedRevInfo)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (i *SCMPRevocation) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	minLength := 2
+	if size := len(data); size < minLength {
+		df.SetTruncated()
+		return serrors.New("buffer too short", "min", minLength, "actual", size)
+	}
+	length := 2 + int(binary.BigEndian.Uint16(data[0:2]))
+	if size := len(data); size < length {
+		df.SetTruncated()
+		return serrors.New("buffer too short", "min", length, "actual", size)
+	}
+	i.SignedRevInfo = data[2:length]
+	i.BaseLayer = layers.BaseLayer{
+		Contents: data[:length],
+		Payload:  data[length:],
+	}
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (i *SCMPRevocation) SerializeTo(b gopacket.SerializeBuffer,
+	opts gopacket.SerializeOptions) error {
+
+	if len(i.SignedRevInfo) > math.MaxUint16 {
+		return serrors.New("signed revocation too long", "len", len(i.SignedRevInfo))
+	}
+	buf, err := b.PrependBytes(i.Len())
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint16(buf[0:2], uint16(len(i.SignedRevInfo)))
+	copy(buf[2:], i.SignedRevInfo)
+	return nil
+}
+
+func decodeSCMPRevocation(data []byte, pb gopacket.PacketBuilder) error {
+	s := &SCMPRevocation{}
+	if err := s.DecodeFromBytes(data, pb); err != nil {
+		return err
+	}
+	pb.AddLayer(s)
+	return pb.NextDecoder(s.NextLayerType())
+}
+
 // SCMPInternalConnectivityDown indicates the AS internal connection between 2
 // routers is down. The format is as follows:
 //
@@ -109,6 +109,96 @@ func TestSCMPExternalInterfaceDownSerializeTo(t *testing.T) {
 	}
 }
 
+func TestSCMPRevocationDecodeFromBytes(t *testing.T) {
+	testCases := map[string]struct {
+		raw        []byte
+		decoded    *slayers.SCMPRevocation
+		assertFunc assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			raw: append([]byte{
+				0x0, 0x3, 0x1, 0x2,
+				0x3,
+			}, bytes.Repeat([]byte{0xff}, 10)...),
+			decoded: &slayers.SCMPRevocation{
+				SignedRevInfo: []byte{0x1, 0x2, 0x3},
+			},
+			assertFunc: assert.NoError,
+		},
+		"empty": {
+			raw:        []byte{0x0, 0x0},
+			decoded:    &slayers.SCMPRevocation{SignedRevInfo: []byte{}},
+			assertFunc: assert.NoError,
+		},
+		"invalid": {
+			raw:        []byte{0x0},
+			decoded:    &slayers.SCMPRevocation{},
+			assertFunc: assert.Error,
+		},
+		"truncated": {
+			raw:        []byte{0x0, 0x3, 0x1, 0x2},
+			decoded:    &slayers.SCMPRevocation{},
+			assertFunc: assert.Error,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := &slayers.SCMPRevocation{}
+			err := got.DecodeFromBytes(tc.raw, gopacket.NilDecodeFeedback)
+			tc.assertFunc(t, err)
+			if err != nil {
+				return
+			}
+			length := 2 + len(tc.decoded.SignedRevInfo)
+			tc.decoded.Contents = tc.raw[:length]
+			tc.decoded.Payload = tc.raw[length:]
+			assert.Equal(t, tc.decoded, got)
+		})
+	}
+}
+
+func TestSCMPRevocationSerializeTo(t *testing.T) {
+	testCases := map[string]struct {
+		raw        []byte
+		decoded    *slayers.SCMPRevocation
+		assertFunc assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			raw: []byte{
+				0x0, 0x3, 0x1, 0x2,
+				0x3,
+			},
+			decoded: &slayers.SCMPRevocation{
+				SignedRevInfo: []byte{0x1, 0x2, 0x3},
+			},
+			assertFunc: assert.NoError,
+		},
+		"too long": {
+			decoded: &slayers.SCMPRevocation{
+				SignedRevInfo: make([]byte, 1<<16),
+			},
+			assertFunc: assert.Error,
+		},
+	}
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			buffer := gopacket.NewSerializeBuffer()
+			err := tc.decoded.SerializeTo(buffer, gopacket.SerializeOptions{})
+			tc.assertFunc(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.raw, buffer.Bytes())
+		})
+	}
+}
+
 func TestSCMPInternalConnectivityDownDecodeFromBytes(t *testing.T) {
 	testCases := map[string]struct {
 		raw        []byte
@@ -74,6 +74,13 @@ const (
 	SCMPCodeUnknownEndToEndOption  SCMPCode = 66
 )
 
+// ExternalInterfaceDown
+const (
+	// SCMPCodeRevokedInterface indicates that the interface is revoked by the
+	// control plane. The message carries the signed revocation.
+	SCMPCodeRevokedInterface SCMPCode = 1
+)
+
 // SCMP informational messages.
 const (
 	SCMPTypeEchoRequest       SCMPType = 128
@@ -106,10 +113,10 @@ func (a SCMPTypeCode) String() string {
 	if !ok {
 		return fmt.Sprintf("%d(%d)", t, c)
 	}
-	if info.codes == nil && c == 0 {
+	code, ok := info.codes[c]
+	if !ok && c == 0 {
 		return info.name
 	}
-	code, ok := info.codes[c]
 	if !ok {
 		return fmt.Sprintf("%s(Code: %d)", info.name, c)
 	}
@@ -131,7 +138,11 @@ var scmpTypeCodeInfo = map[SCMPType]struct {
 	codes map[SCMPCode]string
 }{
 	SCMPTypeDestinationUnreachable:   {name: "DestinationUnreachable"},
-	SCMPTypeExternalInterfaceDown:    {name: "ExternalInterfaceDown"},
+	SCMPTypeExternalInterfaceDown: {
+		"ExternalInterfaceDown", map[SCMPCode]string{
+			SCMPCodeRevokedInterface: "RevokedInterface",
+		},
+	},
 	SCMPTypeInternalConnectivityDown: {name: "InternalConnectivityDown"},
 	SCMPTypePacketTooBig:             {name: "PacketTooBig"},
 	SCMPTypeEchoRequest:              {name: "EchoRequest"},
@@ -43,6 +43,10 @@ func TestTypeSCMPCodeString(t *testing.T) {
 			c:    slayers.CreateSCMPTypeCode(5, 0),
 			want: "ExternalInterfaceDown",
 		},
+		"known type with optional code": {
+			c:    slayers.CreateSCMPTypeCode(5, 1),
+			want: "ExternalInterfaceDown(RevokedInterface)",
+		},
 		"known type unknown code no mapping": {
 			c:    slayers.CreateSCMPTypeCode(128, 1),
 			want: "EchoRequest(Code: 1)",
@@ -112,6 +112,9 @@ func (h DefaultSCMPHandler) Handle(pkt *Packet) error {
 	switch scmp.Type() {
 	case slayers.SCMPTypeExternalInterfaceDown:
 		msg := pkt.PayloadV2.(SCMPExternalInterfaceDown)
+		if msg.SignedRevInfo != nil {
+			return h.handleSignedSCMPRev(typeCode, msg.SignedRevInfo)
+		}
 		return h.handleSCMPRev(typeCode, &path_mgmt.RevInfo{
 			IfID:         common.IFIDType(msg.Interface),
 			RawIsdas:     msg.IA.IAInt(),
@@ -150,6 +153,25 @@ func (h *DefaultSCMPHandler) handleSCMPRev(typeCode slayers.SCMPTypeCode,
 	return &OpError{typeCode: typeCode, revInfo: revInfo}
 }
 
+// handleSignedSCMPRev forwards the signed revocation that is carried in the SCMP
+// message to the revocation handler.
+func (h *DefaultSCMPHandler) handleSignedSCMPRev(typeCode slayers.SCMPTypeCode,
+	raw []byte) error {
+
+	sRev, err := path_mgmt.NewSignedRevInfoFromRaw(raw)
+	if err != nil {
+		return serrors.WrapStr("parsing signed rev info", err)
+	}
+	revInfo, err := sRev.RevInfo()
+	if err != nil {
+		return serrors.WrapStr("parsing rev info", err)
+	}
+	if h.RevocationHandler != nil {
+		h.RevocationHandler.RevokeRaw(context.TODO(), raw)
+	}
+	return &OpError{typeCode: typeCode, revInfo: revInfo}
+}
+
 type nullSigner struct{}
 
 func (nullSigner) SignLegacy(context.Context, []byte) (*proto.SignS, error) {
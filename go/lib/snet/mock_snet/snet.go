@@ -410,6 +410,20 @@ func (mr *MockPathMetadataMockRecorder) Expiry() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expiry", reflect.TypeOf((*MockPathMetadata)(nil).Expiry))
 }
 
+// Hops mocks base method
+func (m *MockPathMetadata) Hops() []snet.HopMetadata {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hops")
+	ret0, _ := ret[0].([]snet.HopMetadata)
+	return ret0
+}
+
+// Hops indicates an expected call of Hops
+func (mr *MockPathMetadataMockRecorder) Hops() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hops", reflect.TypeOf((*MockPathMetadata)(nil).Hops))
+}
+
 // MTU mocks base method
 func (m *MockPathMetadata) MTU() uint16 {
 	m.ctrl.T.Helper()
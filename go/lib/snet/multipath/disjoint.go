@@ -0,0 +1,87 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath
+
+import (
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+type ifKey struct {
+	ia   addr.IA
+	ifid common.IFIDType
+}
+
+// Overlap returns the number of interfaces that both paths traverse.
+func Overlap(a, b snet.Path) int {
+	set := interfaceSet(a)
+	overlap := 0
+	for _, intf := range b.Interfaces() {
+		if set[ifKey{ia: intf.IA(), ifid: intf.ID()}] {
+			overlap++
+		}
+	}
+	return overlap
+}
+
+// DisjointPaths chooses up to n paths that share as few interfaces as
+// possible. Paths with the same fingerprint are considered only once. The
+// first path is always chosen, every further path is the one that shares the
+// fewest interfaces with the paths chosen so far. Ties are broken by the order
+// of the paths, so the paths should be ordered by preference, e.g., with
+// pathpol.Policy.SortedPaths.
+func DisjointPaths(paths []snet.Path, n int) []snet.Path {
+	var candidates []snet.Path
+	seen := make(map[snet.PathFingerprint]bool)
+	for _, path := range paths {
+		fingerprint := snet.Fingerprint(path)
+		if !seen[fingerprint] {
+			seen[fingerprint] = true
+			candidates = append(candidates, path)
+		}
+	}
+	used := make(map[ifKey]bool)
+	var chosen []snet.Path
+	for len(chosen) < n && len(candidates) > 0 {
+		best, bestOverlap := 0, -1
+		for i, path := range candidates {
+			overlap := 0
+			for _, intf := range path.Interfaces() {
+				if used[ifKey{ia: intf.IA(), ifid: intf.ID()}] {
+					overlap++
+				}
+			}
+			if bestOverlap == -1 || overlap < bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+		}
+		path := candidates[best]
+		chosen = append(chosen, path)
+		for key := range interfaceSet(path) {
+			used[key] = true
+		}
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	return chosen
+}
+
+func interfaceSet(path snet.Path) map[ifKey]bool {
+	set := make(map[ifKey]bool)
+	for _, intf := range path.Interfaces() {
+		set[ifKey{ia: intf.IA(), ifid: intf.ID()}] = true
+	}
+	return set
+}
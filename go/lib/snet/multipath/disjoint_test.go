@@ -0,0 +1,67 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+)
+
+func TestDisjointPaths(t *testing.T) {
+	// Paths from 110 to 113.
+	direct := newTestPath(1, ia110, 1, ia113, 1)
+	via111 := newTestPath(2, ia110, 2, ia111, 1, ia111, 2, ia113, 2)
+	via111Alt := newTestPath(3, ia110, 2, ia111, 1, ia111, 3, ia113, 3)
+	via112 := newTestPath(4, ia110, 3, ia112, 1, ia112, 2, ia113, 4)
+	duplicate := newTestPath(5, ia110, 1, ia113, 1)
+
+	assert.Equal(t, 0, multipath.Overlap(direct, via111))
+	assert.Equal(t, 2, multipath.Overlap(via111, via111Alt))
+
+	tests := map[string]struct {
+		Paths    []snet.Path
+		N        int
+		Expected []snet.Path
+	}{
+		"disjoint first": {
+			Paths:    []snet.Path{via111, via111Alt, direct, via112},
+			N:        3,
+			Expected: []snet.Path{via111, direct, via112},
+		},
+		"overlap if necessary": {
+			Paths:    []snet.Path{via111, via111Alt, direct, via112},
+			N:        4,
+			Expected: []snet.Path{via111, direct, via112, via111Alt},
+		},
+		"duplicates are skipped": {
+			Paths:    []snet.Path{direct, duplicate, via111},
+			N:        3,
+			Expected: []snet.Path{direct, via111},
+		},
+		"none": {
+			Paths: []snet.Path{direct},
+			N:     0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, multipath.DisjointPaths(test.Paths, test.N))
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multipath spreads the datagrams of a single flow over multiple SCION
+// paths.
+//
+// A Sender writes each datagram over one of its paths. The path is chosen by a
+// Scheduler, based on the statistics that the sender keeps per path. The round
+// trip time and the loss rate of the paths are measured with probes, e.g.,
+// SCMP echo requests sent by the EchoProber. DisjointPaths can be used to
+// choose a set of paths that share as few interfaces as possible.
+//
+// Example:
+//
+//   paths := multipath.DisjointPaths(candidates, 3)
+//   sender := multipath.NewSender(conn, remote, &multipath.WeightedRTT{})
+//   sender.SetPaths(paths)
+//   task := &multipath.ProbeTask{Sender: sender, Prober: prober}
+//   runner := periodic.Start(task, time.Second, time.Second)
+//   defer runner.Stop()
+//   sender.Write(data)
+package multipath
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+const (
+	// DownThreshold is the number of consecutive lost probes after which a
+	// path is considered down.
+	DownThreshold = 3
+)
+
+// ErrNoPath indicates that the sender has no paths.
+var ErrNoPath = serrors.New("no path")
+
+// PathStats contains the statistics of a path of a sender.
+type PathStats struct {
+	// Path is the path.
+	Path snet.Path
+	// RTT is the smoothed round trip time of the probes. It is zero if no
+	// probe reply was received yet.
+	RTT time.Duration
+	// Loss is the smoothed ratio of lost probes, between 0 and 1.
+	Loss float64
+	// ConsecutiveLost is the number of probes lost since the last reply.
+	ConsecutiveLost int
+	// ProbesSent is the number of probes sent over the path.
+	ProbesSent int
+	// ProbesReceived is the number of probe replies received over the path.
+	ProbesReceived int
+	// Packets is the number of datagrams written over the path.
+	Packets int
+	// Bytes is the number of payload bytes written over the path.
+	Bytes int
+}
+
+// Down returns whether the path is considered down, i.e., the last
+// DownThreshold probes were lost.
+func (s PathStats) Down() bool {
+	return s.ConsecutiveLost >= DownThreshold
+}
+
+// update adds the result of a probe to the statistics. The RTT and loss are
+// smoothed with the same factor as the smoothed RTT in RFC 6298.
+func (s *PathStats) update(result ProbeResult) {
+	s.ProbesSent++
+	lost := 0.0
+	if result.Err != nil {
+		lost = 1
+		s.ConsecutiveLost++
+	} else {
+		s.ProbesReceived++
+		s.ConsecutiveLost = 0
+		if s.RTT == 0 {
+			s.RTT = result.RTT
+		} else {
+			s.RTT = s.RTT - s.RTT/8 + result.RTT/8
+		}
+	}
+	if s.ProbesSent == 1 {
+		s.Loss = lost
+	} else {
+		s.Loss = s.Loss*7/8 + lost/8
+	}
+}
+
+// Sender writes datagrams to a remote over multiple paths. It is safe for
+// concurrent use.
+type Sender struct {
+	conn      *snet.Conn
+	remote    *snet.UDPAddr
+	scheduler Scheduler
+
+	mtx   sync.Mutex
+	paths []PathStats
+}
+
+// NewSender creates a sender that writes to remote over conn. The scheduler
+// chooses the path of every datagram. The sender has no paths until SetPaths
+// is called.
+func NewSender(conn *snet.Conn, remote *snet.UDPAddr, scheduler Scheduler) *Sender {
+	return &Sender{
+		conn:      conn,
+		remote:    remote.Copy(),
+		scheduler: scheduler,
+	}
+}
+
+// SetPaths sets the paths of the sender. The statistics of paths that the
+// sender already uses are kept, paths are identified by their fingerprint.
+func (s *Sender) SetPaths(paths []snet.Path) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	old := make(map[snet.PathFingerprint]PathStats, len(s.paths))
+	for _, stats := range s.paths {
+		old[snet.Fingerprint(stats.Path)] = stats
+	}
+	s.paths = make([]PathStats, 0, len(paths))
+	for _, path := range paths {
+		stats := old[snet.Fingerprint(path)]
+		stats.Path = path
+		s.paths = append(s.paths, stats)
+	}
+}
+
+// Stats returns the statistics of the paths of the sender.
+func (s *Sender) Stats() []PathStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]PathStats(nil), s.paths...)
+}
+
+// Write writes b to the remote over the path chosen by the scheduler.
+func (s *Sender) Write(b []byte) (int, error) {
+	s.mtx.Lock()
+	if len(s.paths) == 0 {
+		s.mtx.Unlock()
+		return 0, ErrNoPath
+	}
+	i := s.scheduler.Select(append([]PathStats(nil), s.paths...))
+	if i < 0 || i >= len(s.paths) {
+		s.mtx.Unlock()
+		return 0, serrors.New("scheduler selected invalid path", "index", i,
+			"paths", len(s.paths))
+	}
+	path := s.paths[i].Path
+	s.mtx.Unlock()
+
+	remote := s.remote.Copy()
+	remote.Path = path.Path()
+	remote.NextHop = path.UnderlayNextHop()
+	n, err := s.conn.WriteTo(b, remote)
+	if err != nil {
+		return n, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if stats := s.find(snet.Fingerprint(path)); stats != nil {
+		stats.Packets++
+		stats.Bytes += n
+	}
+	return n, nil
+}
+
+// Probe probes all paths of the sender once and updates their statistics.
+func (s *Sender) Probe(ctx context.Context, prober Prober) error {
+	s.mtx.Lock()
+	paths := make([]snet.Path, 0, len(s.paths))
+	for _, stats := range s.paths {
+		paths = append(paths, stats.Path)
+	}
+	s.mtx.Unlock()
+	if len(paths) == 0 {
+		return nil
+	}
+
+	results, err := prober.Probe(ctx, s.remote, paths)
+	if err != nil {
+		return err
+	}
+	if len(results) != len(paths) {
+		return serrors.New("prober returned wrong number of results",
+			"expected", len(paths), "actual", len(results))
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, path := range paths {
+		// The paths might have changed while probing.
+		if stats := s.find(snet.Fingerprint(path)); stats != nil {
+			stats.update(results[i])
+		}
+	}
+	return nil
+}
+
+func (s *Sender) find(fingerprint snet.PathFingerprint) *PathStats {
+	for i := range s.paths {
+		if snet.Fingerprint(s.paths[i].Path) == fingerprint {
+			return &s.paths[i]
+		}
+	}
+	return nil
+}
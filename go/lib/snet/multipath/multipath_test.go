@@ -0,0 +1,187 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	ia110 = xtest.MustParseIA("1-ff00:0:110")
+	ia111 = xtest.MustParseIA("1-ff00:0:111")
+	ia112 = xtest.MustParseIA("1-ff00:0:112")
+	ia113 = xtest.MustParseIA("1-ff00:0:113")
+)
+
+func TestSenderWrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listen := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 40000}
+	remote := &snet.UDPAddr{
+		IA:   ia112,
+		Host: &net.UDPAddr{IP: net.IP{127, 0, 0, 3}, Port: 40001},
+	}
+	a := newTestPath(1, ia110, 1, ia112, 1)
+	b := newTestPath(2, ia110, 2, ia112, 2)
+
+	packetConn := mock_snet.NewMockPacketConn(ctrl)
+	dispatcher := mock_snet.NewMockPacketDispatcherService(ctrl)
+	dispatcher.EXPECT().Register(gomock.Any(), ia110, listen, addr.SvcNone).
+		Return(packetConn, uint16(listen.Port), nil)
+	network := &snet.SCIONNetwork{LocalIA: ia110, Dispatcher: dispatcher, Version2: true}
+	conn, err := network.Listen(context.Background(), "udp", listen, addr.SvcNone)
+	require.NoError(t, err)
+
+	sender := multipath.NewSender(conn, remote, &multipath.RoundRobin{})
+	_, err = sender.Write([]byte("hello"))
+	assert.Equal(t, multipath.ErrNoPath, err)
+
+	sender.SetPaths([]snet.Path{a, b})
+	for _, path := range []*testPath{a, b, a} {
+		path := path
+		packetConn.EXPECT().WriteTo(gomock.Any(), path.nextHop).DoAndReturn(
+			func(pkt *snet.Packet, _ *net.UDPAddr) error {
+				assert.Equal(t, path.Path(), pkt.Path)
+				return nil
+			},
+		)
+	}
+	for i := 0; i < 3; i++ {
+		n, err := sender.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+	}
+	stats := sender.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, 2, stats[0].Packets)
+	assert.Equal(t, 10, stats[0].Bytes)
+	assert.Equal(t, 1, stats[1].Packets)
+
+	// Statistics are kept for paths that remain.
+	sender.SetPaths([]snet.Path{b})
+	stats = sender.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Packets)
+}
+
+func TestSenderProbe(t *testing.T) {
+	a := newTestPath(1, ia110, 1, ia112, 1)
+	b := newTestPath(2, ia110, 2, ia112, 2)
+	sender := multipath.NewSender(nil, &snet.UDPAddr{IA: ia112}, &multipath.RoundRobin{})
+	sender.SetPaths([]snet.Path{a, b})
+
+	prober := &fakeProber{results: [][]multipath.ProbeResult{
+		{{RTT: 80 * time.Millisecond}, {Err: multipath.ErrProbeTimeout}},
+		{{RTT: 160 * time.Millisecond}, {Err: multipath.ErrProbeTimeout}},
+		{{RTT: 80 * time.Millisecond}, {Err: multipath.ErrProbeTimeout}},
+	}}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sender.Probe(context.Background(), prober))
+	}
+	stats := sender.Stats()
+	assert.Equal(t, 3, stats[0].ProbesSent)
+	assert.Equal(t, 3, stats[0].ProbesReceived)
+	assert.Equal(t, 88750*time.Microsecond, stats[0].RTT)
+	assert.Equal(t, 0.0, stats[0].Loss)
+	assert.False(t, stats[0].Down())
+	assert.Equal(t, 0, stats[1].ProbesReceived)
+	assert.Equal(t, 1.0, stats[1].Loss)
+	assert.Equal(t, time.Duration(0), stats[1].RTT)
+	assert.True(t, stats[1].Down())
+
+	prober.results = [][]multipath.ProbeResult{{{}}}
+	assert.Error(t, sender.Probe(context.Background(), prober))
+	prober.err = serrors.New("test")
+	assert.Error(t, sender.Probe(context.Background(), prober))
+}
+
+type fakeProber struct {
+	results [][]multipath.ProbeResult
+	err     error
+}
+
+func (p *fakeProber) Probe(_ context.Context, _ *snet.UDPAddr,
+	_ []snet.Path) ([]multipath.ProbeResult, error) {
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	results := p.results[0]
+	p.results = p.results[1:]
+	return results, nil
+}
+
+// testPath is a path with the given interfaces. The interfaces are given as
+// pairs of ISD-AS and interface ID.
+type testPath struct {
+	interfaces []snet.PathInterface
+	nextHop    *net.UDPAddr
+}
+
+func newTestPath(id byte, interfaces ...interface{}) *testPath {
+	p := &testPath{
+		nextHop: &net.UDPAddr{IP: net.IP{127, 0, 0, id}, Port: 30041},
+	}
+	for i := 0; i < len(interfaces); i += 2 {
+		p.interfaces = append(p.interfaces, sciond.PathInterface{
+			RawIsdas: interfaces[i].(addr.IA).IAInt(),
+			IfID:     common.IFIDType(interfaces[i+1].(int)),
+		})
+	}
+	return p
+}
+
+func (p *testPath) UnderlayNextHop() *net.UDPAddr {
+	return p.nextHop
+}
+
+func (p *testPath) Path() *spath.Path {
+	return &spath.Path{Raw: common.RawBytes(p.nextHop.IP)}
+}
+
+func (p *testPath) Interfaces() []snet.PathInterface {
+	return p.interfaces
+}
+
+func (p *testPath) Destination() addr.IA {
+	return p.interfaces[len(p.interfaces)-1].IA()
+}
+
+func (p *testPath) Metadata() snet.PathMetadata {
+	return nil
+}
+
+func (p *testPath) Copy() snet.Path {
+	c := *p
+	return &c
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/lib/topology/underlay"
+)
+
+const (
+	// DefaultProbeTimeout is the default time to wait for probe replies.
+	DefaultProbeTimeout = time.Second
+)
+
+// ErrProbeTimeout indicates that no probe reply was received in time.
+var ErrProbeTimeout = serrors.New("probe timed out")
+
+// ProbeResult is the result of probing a path.
+type ProbeResult struct {
+	// RTT is the round trip time of the probe.
+	RTT time.Duration
+	// Err is set if the probe was lost.
+	Err error
+}
+
+// Prober measures the round trip time of paths.
+type Prober interface {
+	// Probe sends one probe over each of the paths to the remote. It returns
+	// one result per path, in the order of the paths.
+	Probe(ctx context.Context, remote *snet.UDPAddr, paths []snet.Path) ([]ProbeResult, error)
+}
+
+// EchoProber probes paths with SCMP echo requests. The probes are sent with
+// the SCION header v2.
+type EchoProber struct {
+	// Dispatcher is used to register the connection for the probes.
+	Dispatcher reliable.Dispatcher
+	// Local is the local address. The port is ignored.
+	Local *snet.UDPAddr
+	// Timeout is the time to wait for the replies. If it is zero,
+	// DefaultProbeTimeout is used.
+	Timeout time.Duration
+	// Host is the destination host of the probes. If it is nil, the host of
+	// the remote is used. Probing a service address, e.g., addr.SvcCS, does
+	// not require knowing a host in the remote AS.
+	Host addr.HostAddr
+}
+
+// Probe sends an SCMP echo request over each path and waits for the replies
+// until the timeout expires.
+func (p *EchoProber) Probe(ctx context.Context, remote *snet.UDPAddr,
+	paths []snet.Path) ([]ProbeResult, error) {
+
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultProbeTimeout
+	}
+	dstHost := p.Host
+	if dstHost == nil {
+		dstHost = addr.HostFromIP(remote.Host.IP)
+	}
+	id := uint16(rand.Uint32())
+	replies := make(chan echoReply, len(paths))
+	svc := snet.DefaultPacketDispatcherService{
+		Dispatcher:  p.Dispatcher,
+		SCMPHandler: echoHandler{id: id, replies: replies},
+		Version2:    true,
+	}
+	local := p.Local.Copy()
+	local.Host.Port = 0
+	conn, port, err := svc.Register(ctx, local.IA, local.Host, addr.SvcNone)
+	if err != nil {
+		return nil, serrors.WrapStr("registering probe connection", err)
+	}
+	done := make(chan struct{})
+	defer conn.Close()
+	defer close(done)
+	local.Host.Port = int(port)
+	go func() {
+		defer log.HandlePanic()
+		readReplies(conn, done)
+	}()
+
+	results := make([]ProbeResult, len(paths))
+	sent := make([]time.Time, len(paths))
+	pending := make(map[uint16]bool, len(paths))
+	for i, path := range paths {
+		pkt := &snet.Packet{
+			PacketInfo: snet.PacketInfo{
+				Destination: snet.SCIONAddress{
+					IA:   remote.IA,
+					Host: dstHost,
+				},
+				Source: snet.SCIONAddress{
+					IA:   local.IA,
+					Host: addr.HostFromIP(local.Host.IP),
+				},
+				Path: path.Path(),
+				PayloadV2: snet.SCMPEchoRequest{
+					Identifier: id,
+					SeqNumber:  uint16(i),
+				},
+			},
+		}
+		nextHop := path.UnderlayNextHop()
+		if nextHop == nil && local.IA.Equal(remote.IA) && remote.Host != nil {
+			nextHop = &net.UDPAddr{
+				IP:   remote.Host.IP,
+				Port: underlay.EndhostPort,
+				Zone: remote.Host.Zone,
+			}
+		}
+		sent[i] = time.Now()
+		if err := conn.WriteTo(pkt, nextHop); err != nil {
+			results[i].Err = serrors.WrapStr("sending probe", err)
+			continue
+		}
+		pending[uint16(i)] = true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case reply := <-replies:
+			if !pending[reply.seq] {
+				continue
+			}
+			delete(pending, reply.seq)
+			results[reply.seq].RTT = reply.received.Sub(sent[reply.seq])
+		case <-timer.C:
+			for seq := range pending {
+				results[seq].Err = ErrProbeTimeout
+			}
+			return results, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+// readReplies reads from the connection until the probing is done. The replies
+// are passed to the SCMP handler of the connection. Reading also stops on
+// errors other than timeouts, e.g., if the connection to the dispatcher is
+// lost. The pending probes then time out.
+func readReplies(conn snet.PacketConn, done <-chan struct{}) {
+	for {
+		var pkt snet.Packet
+		var ov net.UDPAddr
+		err := conn.ReadFrom(&pkt, &ov)
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if err != nil && !serrors.IsTimeout(err) {
+			log.Debug("Stopped reading probe replies", "err", err)
+			return
+		}
+	}
+}
+
+type echoReply struct {
+	seq      uint16
+	received time.Time
+}
+
+type echoHandler struct {
+	id      uint16
+	replies chan<- echoReply
+}
+
+func (h echoHandler) Handle(pkt *snet.Packet) error {
+	r, ok := pkt.PayloadV2.(snet.SCMPEchoReply)
+	if !ok || r.Identifier != h.id {
+		return nil
+	}
+	select {
+	case h.replies <- echoReply{seq: r.SeqNumber, received: time.Now()}:
+	default:
+	}
+	return nil
+}
+
+var _ periodic.Task = (*ProbeTask)(nil)
+
+// ProbeTask periodically probes the paths of a sender.
+type ProbeTask struct {
+	Sender *Sender
+	Prober Prober
+}
+
+// Name returns the name of the task.
+func (t *ProbeTask) Name() string {
+	return "multipath_prober"
+}
+
+// Run probes the paths of the sender once.
+func (t *ProbeTask) Run(ctx context.Context) {
+	if err := t.Sender.Probe(ctx, t.Prober); err != nil {
+		log.FromCtx(ctx).Info("Failed to probe paths", "err", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+)
+
+func TestReadReplies(t *testing.T) {
+	t.Run("stops on persistent error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		conn := mock_snet.NewMockPacketConn(ctrl)
+		gomock.InOrder(
+			conn.EXPECT().ReadFrom(gomock.Any(), gomock.Any()),
+			conn.EXPECT().ReadFrom(gomock.Any(), gomock.Any()).Return(timeoutErr{}),
+			conn.EXPECT().ReadFrom(gomock.Any(), gomock.Any()).
+				Return(serrors.New("connection closed")),
+		)
+		runReadReplies(t, conn, make(chan struct{}))
+	})
+	t.Run("stops when done", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		conn := mock_snet.NewMockPacketConn(ctrl)
+		done := make(chan struct{})
+		conn.EXPECT().ReadFrom(gomock.Any(), gomock.Any()).Do(
+			func(_ ...interface{}) { close(done) },
+		)
+		runReadReplies(t, conn, done)
+	})
+}
+
+// runReadReplies runs ReadReplies and fails the test if it does not return
+// in time.
+func runReadReplies(t *testing.T, conn *mock_snet.MockPacketConn, done chan struct{}) {
+	t.Helper()
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		multipath.ReadReplies(conn, done)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("ReadReplies did not return")
+	}
+}
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "timeout" }
+func (timeoutErr) Timeout() bool { return true }
@@ -0,0 +1,122 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath
+
+import (
+	"time"
+
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// Scheduler chooses the path of a datagram.
+type Scheduler interface {
+	// Select returns the index of the path over which the next datagram is
+	// sent. The paths are never empty. The sender serializes the calls.
+	Select(paths []PathStats) int
+}
+
+var (
+	_ Scheduler = (*RoundRobin)(nil)
+	_ Scheduler = (*WeightedRTT)(nil)
+	_ Scheduler = LowestLatency{}
+)
+
+// RoundRobin uses the paths in turn. Paths that are down are skipped, unless
+// all paths are down.
+type RoundRobin struct {
+	next int
+}
+
+func (s *RoundRobin) Select(paths []PathStats) int {
+	candidates := usable(paths)
+	i := candidates[s.next%len(candidates)]
+	s.next = (s.next + 1) % len(candidates)
+	return i
+}
+
+// WeightedRTT uses the paths in proportion to the inverse of their RTT, i.e.,
+// a path with half the RTT is used twice as often. Paths without a measured
+// RTT are weighted like the slowest measured path. Paths that are down are
+// skipped, unless all paths are down.
+//
+// The datagrams are interleaved with the smooth weighted round robin algorithm,
+// such that the paths are used evenly over time.
+type WeightedRTT struct {
+	current map[snet.PathFingerprint]float64
+}
+
+func (s *WeightedRTT) Select(paths []PathStats) int {
+	candidates := usable(paths)
+	var slowest time.Duration
+	for _, i := range candidates {
+		if paths[i].RTT > slowest {
+			slowest = paths[i].RTT
+		}
+	}
+	current := make(map[snet.PathFingerprint]float64, len(candidates))
+	best, bestWeight, total := -1, 0.0, 0.0
+	for _, i := range candidates {
+		weight := 1.0
+		if slowest != 0 {
+			rtt := paths[i].RTT
+			if rtt == 0 {
+				rtt = slowest
+			}
+			weight = float64(slowest) / float64(rtt)
+		}
+		key := snet.Fingerprint(paths[i].Path)
+		current[key] = s.current[key] + weight
+		total += weight
+		if best == -1 || current[key] > bestWeight {
+			best, bestWeight = i, current[key]
+		}
+	}
+	current[snet.Fingerprint(paths[best].Path)] -= total
+	s.current = current
+	return best
+}
+
+// LowestLatency uses the path with the lowest RTT. Paths without a measured
+// RTT are only used if no path has a measured RTT. Paths that are down are
+// skipped, unless all paths are down.
+type LowestLatency struct{}
+
+func (LowestLatency) Select(paths []PathStats) int {
+	candidates := usable(paths)
+	best := candidates[0]
+	for _, i := range candidates[1:] {
+		rtt := paths[i].RTT
+		if rtt != 0 && (paths[best].RTT == 0 || rtt < paths[best].RTT) {
+			best = i
+		}
+	}
+	return best
+}
+
+// usable returns the indices of the paths that are not down. If all paths are
+// down, all indices are returned.
+func usable(paths []PathStats) []int {
+	var up, all []int
+	for i, stats := range paths {
+		all = append(all, i)
+		if !stats.Down() {
+			up = append(up, i)
+		}
+	}
+	if len(up) == 0 {
+		return all
+	}
+	return up
+}
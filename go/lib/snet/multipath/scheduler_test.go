@@ -0,0 +1,93 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipath_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+)
+
+func TestSchedulers(t *testing.T) {
+	fast := multipath.PathStats{
+		Path: newTestPath(1, ia110, 1, ia112, 1),
+		RTT:  10 * time.Millisecond,
+	}
+	slow := multipath.PathStats{
+		Path: newTestPath(2, ia110, 2, ia112, 2),
+		RTT:  30 * time.Millisecond,
+	}
+	unknown := multipath.PathStats{Path: newTestPath(3, ia110, 3, ia112, 3)}
+	unknownAlt := multipath.PathStats{Path: newTestPath(5, ia110, 5, ia112, 5)}
+	down := multipath.PathStats{
+		Path:            newTestPath(4, ia110, 4, ia112, 4),
+		RTT:             time.Millisecond,
+		ConsecutiveLost: multipath.DownThreshold,
+	}
+
+	tests := map[string]struct {
+		Scheduler multipath.Scheduler
+		Paths     []multipath.PathStats
+		Expected  []int
+	}{
+		"round robin": {
+			Scheduler: &multipath.RoundRobin{},
+			Paths:     []multipath.PathStats{fast, down, slow},
+			Expected:  []int{0, 2, 0, 2},
+		},
+		"round robin all down": {
+			Scheduler: &multipath.RoundRobin{},
+			Paths:     []multipath.PathStats{down, down},
+			Expected:  []int{0, 1, 0},
+		},
+		"weighted rtt": {
+			Scheduler: &multipath.WeightedRTT{},
+			Paths:     []multipath.PathStats{slow, fast, down},
+			Expected:  []int{1, 0, 1, 1, 1, 0, 1, 1},
+		},
+		"weighted rtt unknown": {
+			Scheduler: &multipath.WeightedRTT{},
+			Paths:     []multipath.PathStats{unknown, fast, slow},
+			Expected:  []int{1, 0, 1, 2, 1, 1, 0, 1, 2, 1},
+		},
+		"weighted rtt no measurements": {
+			Scheduler: &multipath.WeightedRTT{},
+			Paths:     []multipath.PathStats{unknown, unknownAlt},
+			Expected:  []int{0, 1, 0, 1},
+		},
+		"lowest latency": {
+			Scheduler: multipath.LowestLatency{},
+			Paths:     []multipath.PathStats{unknown, slow, down, fast},
+			Expected:  []int{3, 3},
+		},
+		"lowest latency no measurements": {
+			Scheduler: multipath.LowestLatency{},
+			Paths:     []multipath.PathStats{unknown, unknownAlt},
+			Expected:  []int{0, 0},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var selected []int
+			for range test.Expected {
+				selected = append(selected, test.Scheduler.Select(test.Paths))
+			}
+			assert.Equal(t, test.Expected, selected)
+		})
+	}
+}
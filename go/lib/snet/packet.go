@@ -69,17 +69,27 @@ type SCMPPayload interface {
 type SCMPExternalInterfaceDown struct {
 	IA        addr.IA
 	Interface uint64
-	Payload   []byte
+	// SignedRevInfo is the raw signed revocation of the interface. It is only
+	// set if the interface is revoked by the control plane.
+	SignedRevInfo []byte
+	Payload       []byte
 }
 
 func (m SCMPExternalInterfaceDown) toLayers(scn *slayers.SCION) []gopacket.SerializableLayer {
-	return toLayers(m, scn,
+	l := toLayers(m, scn,
 		&slayers.SCMPExternalInterfaceDown{
 			IA:   m.IA,
 			IfID: m.Interface,
 		},
-		m.Payload,
+		nil,
 	)
+	if m.SignedRevInfo != nil {
+		l = append(l, &slayers.SCMPRevocation{SignedRevInfo: m.SignedRevInfo})
+	}
+	if m.Payload != nil {
+		l = append(l, gopacket.Payload(m.Payload))
+	}
+	return l
 }
 
 // Type returns the SCMP type.
@@ -88,7 +98,12 @@ func (SCMPExternalInterfaceDown) Type() slayers.SCMPType {
 }
 
 // Code returns the SCMP code.
-func (SCMPExternalInterfaceDown) Code() slayers.SCMPCode { return 0 }
+func (m SCMPExternalInterfaceDown) Code() slayers.SCMPCode {
+	if m.SignedRevInfo != nil {
+		return slayers.SCMPCodeRevokedInterface
+	}
+	return 0
+}
 
 // SCMPInternalConnectivityDown is the message that an internal interface is
 // down.
@@ -315,11 +330,20 @@ func (p *Packet) Decode() error {
 					"scmp.type", scmpLayer.TypeCode,
 					"payload.type", common.TypeOf(layer))
 			}
-			p.PayloadV2 = SCMPExternalInterfaceDown{
+			msg := SCMPExternalInterfaceDown{
 				IA:        v.IA,
 				Interface: v.IfID,
 				Payload:   v.Payload,
 			}
+			if scmpLayer.TypeCode.Code() == slayers.SCMPCodeRevokedInterface {
+				var rev slayers.SCMPRevocation
+				err := rev.DecodeFromBytes(v.Payload, gopacket.NilDecodeFeedback)
+				if err != nil {
+					return serrors.WrapStr("decoding SCMP revocation", err)
+				}
+				msg.SignedRevInfo, msg.Payload = rev.SignedRevInfo, rev.Payload
+			}
+			p.PayloadV2 = msg
 		case slayers.SCMPTypeInternalConnectivityDown:
 			v, ok := layer.(*slayers.SCMPInternalConnectivityDown)
 			if !ok {
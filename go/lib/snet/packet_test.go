@@ -139,6 +139,25 @@ func TestPacketSerializeDecodeLoop(t *testing.T) {
 				},
 			},
 		},
+		"SCMP ExternalInterfaceDown revoked": {
+			PacketInfo: snet.PacketInfo{
+				Destination: snet.SCIONAddress{
+					IA:   xtest.MustParseIA("1-ff00:0:110"),
+					Host: addr.SvcCS,
+				},
+				Source: snet.SCIONAddress{
+					IA:   xtest.MustParseIA("1-ff00:0:112"),
+					Host: addr.HostIPv4(net.ParseIP("127.0.0.1").To4()),
+				},
+				Path: spath.NewV2(rawSP, false),
+				PayloadV2: snet.SCMPExternalInterfaceDown{
+					IA:            xtest.MustParseIA("1-ff00:0:111"),
+					Interface:     13,
+					SignedRevInfo: []byte("signed revocation"),
+					Payload:       []byte("scmp quote"),
+				},
+			},
+		},
 		"SCMP InternalConnectivityDown": {
 			PacketInfo: snet.PacketInfo{
 				Destination: snet.SCIONAddress{
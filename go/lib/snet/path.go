@@ -71,6 +71,84 @@ type PathMetadata interface {
 	MTU() uint16
 	// Expiry returns the expiration time of the path.
 	Expiry() time.Time
+	// Hops returns the static metadata of the ASes on the path, in path order.
+	// ASes that do not advertise any metadata are omitted. The result is nil
+	// if no metadata is available.
+	Hops() []HopMetadata
+}
+
+// HopMetadata contains the static metadata that an AS advertises for the part
+// of a path that crosses it. Values that are not advertised are zero.
+type HopMetadata struct {
+	// IA is the ISD-AS of the AS.
+	IA addr.IA
+	// InternalLatency is the propagation delay between the ingress and egress
+	// interface of the AS.
+	InternalLatency time.Duration
+	// LinkLatency is the propagation delay of the inter-AS link of the AS on
+	// the path.
+	LinkLatency time.Duration
+	// PeeringLatency is the propagation delay of the peering link, if the
+	// path crosses one in the AS.
+	PeeringLatency time.Duration
+	// InternalBandwidth is the bandwidth between the ingress and egress
+	// interface of the AS in Kbit/s.
+	InternalBandwidth uint64
+	// LinkBandwidth is the bandwidth of the inter-AS link of the AS on the
+	// path in Kbit/s.
+	LinkBandwidth uint64
+	// LinkType is the type of the inter-AS link of the AS on the path.
+	LinkType LinkType
+	// PeeringLinkType is the type of the peering link, if the path crosses
+	// one in the AS.
+	PeeringLinkType LinkType
+	// InternalHops is the number of AS internal hops between the ingress and
+	// egress interface.
+	InternalHops uint32
+	// Geo are the locations of the routers of the AS.
+	Geo []GeoCoordinates
+	// Note is a free form note of the AS operator.
+	Note string
+}
+
+// LinkType is the type of an inter-AS link.
+type LinkType uint8
+
+const (
+	// LinkTypeUnset indicates that the link type is not known.
+	LinkTypeUnset LinkType = iota
+	// LinkTypeDirect is a direct physical connection.
+	LinkTypeDirect
+	// LinkTypeMultihop is a connection with local routing/switching.
+	LinkTypeMultihop
+	// LinkTypeOpennet is a connection overlayed over the publicly routed
+	// Internet.
+	LinkTypeOpennet
+)
+
+func (t LinkType) String() string {
+	switch t {
+	case LinkTypeUnset:
+		return "unset"
+	case LinkTypeDirect:
+		return "direct"
+	case LinkTypeMultihop:
+		return "multihop"
+	case LinkTypeOpennet:
+		return "opennet"
+	default:
+		return fmt.Sprintf("UNKNOWN (%d)", t)
+	}
+}
+
+// GeoCoordinates is the location of a router.
+type GeoCoordinates struct {
+	// Latitude of the location in degrees.
+	Latitude float32
+	// Longitude of the location in degrees.
+	Longitude float32
+	// Address is the civic address of the location.
+	Address string
 }
 
 type PathFingerprint string
@@ -0,0 +1,262 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pathaware provides connections that choose the path to the remote
+// themselves.
+//
+// The snet connections require the caller to set a path on every remote
+// address. A connection created by the Dialer instead keeps the set of paths
+// to the remote up to date with a path manager, and selects a path according
+// to a path policy. If the active path traverses a revoked interface, is no
+// longer returned by the path manager, or is about to expire, the connection
+// transparently switches to another path.
+//
+// Revocations are learned from SCMP messages that are received on the
+// connection. Applications must therefore read from the connection, even if
+// they do not expect any data, in order to react to interface failures.
+package pathaware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/metrics"
+	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+const (
+	// DefaultExpiryMargin is the default time before the expiration of a path
+	// at which the path is no longer used.
+	DefaultExpiryMargin = 10 * time.Second
+)
+
+// ErrNoPath indicates that there is no usable path to the remote.
+var ErrNoPath = serrors.New("no usable path")
+
+// Reason is the reason for a change of the active path.
+type Reason string
+
+const (
+	// ReasonInitial indicates that the first path was selected.
+	ReasonInitial Reason = "initial"
+	// ReasonRevoked indicates that the active path traverses a revoked
+	// interface.
+	ReasonRevoked Reason = "revoked"
+	// ReasonExpired indicates that the active path is about to expire.
+	ReasonExpired Reason = "expired"
+	// ReasonRemoved indicates that the path manager no longer returns the
+	// active path.
+	ReasonRemoved Reason = "removed"
+)
+
+// PathChange describes a change of the active path of a connection.
+type PathChange struct {
+	// Remote is the remote address of the connection.
+	Remote *snet.UDPAddr
+	// Old is the previously active path. It is nil if no path was active.
+	Old snet.Path
+	// New is the newly active path. It is nil if there is no usable path.
+	New snet.Path
+	// Reason is the reason for the change.
+	Reason Reason
+}
+
+// Metrics can be used to inject metrics counters into the dialer. Each
+// counter may be set or unset.
+type Metrics struct {
+	// PathChanges counts the changes of the active path. It is labeled with
+	// the reason of the change.
+	PathChanges metrics.Counter
+	// Revocations counts the revocations received on the connections. It is
+	// labeled with whether the active path was affected.
+	Revocations metrics.Counter
+}
+
+func (m Metrics) incPathChanges(reason Reason) {
+	if m.PathChanges == nil {
+		return
+	}
+	m.PathChanges.With("reason", string(reason)).Add(1)
+}
+
+func (m Metrics) incRevocations(active bool) {
+	if m.Revocations == nil {
+		return
+	}
+	label := "false"
+	if active {
+		label = "true"
+	}
+	m.Revocations.With("active", label).Add(1)
+}
+
+// Dialer creates connections that select the path to the remote themselves.
+type Dialer struct {
+	// Network is used to open the underlying connections.
+	Network snet.Network
+	// LocalIA is the ISD-AS of the local host.
+	LocalIA addr.IA
+	// Resolver keeps the paths to the remote up to date.
+	Resolver pathmgr.Resolver
+	// Policy filters and orders the paths to the remote. If it is nil, all
+	// paths are used.
+	Policy *pathpol.Policy
+	// ExpiryMargin is the time before the expiration of a path at which the
+	// path is no longer used. If it is zero, DefaultExpiryMargin is used.
+	ExpiryMargin time.Duration
+	// OnPathChange, if set, is called whenever the active path of a connection
+	// changes. It is called synchronously from Write, and must not block. It
+	// is called without internal locks held, so it may use the connection.
+	OnPathChange func(PathChange)
+	// Metrics are the metrics of the connections.
+	Metrics Metrics
+}
+
+// Dial opens a connection to the remote. The path set on the remote address
+// is ignored. Dial fails if there is no usable path to the remote.
+func (d *Dialer) Dial(ctx context.Context, listen *net.UDPAddr,
+	remote *snet.UDPAddr) (*Conn, error) {
+
+	if remote == nil {
+		return nil, serrors.New("nil remote")
+	}
+	var filter pathmgr.Policy
+	if d.Policy != nil {
+		filter = d.Policy
+	}
+	paths, err := d.Resolver.WatchFilter(ctx, d.LocalIA, remote.IA, filter)
+	if err != nil {
+		return nil, serrors.WrapStr("watching paths", err, "remote", remote.IA)
+	}
+	conn, err := d.Network.Listen(ctx, "udp", listen, addr.SvcNone)
+	if err != nil {
+		paths.Destroy()
+		return nil, err
+	}
+	c := newConn(d, conn, remote, paths)
+	if _, err := c.selector.Path(); err != nil {
+		c.Close()
+		return nil, serrors.WithCtx(err, "remote", remote.IA)
+	}
+	return c, nil
+}
+
+var _ net.Conn = (*Conn)(nil)
+
+// Conn is a connection to a fixed remote that selects the path itself. It is
+// safe for concurrent use.
+type Conn struct {
+	conn     *snet.Conn
+	remote   *snet.UDPAddr
+	paths    *pathmgr.SyncPaths
+	selector *selector
+	metrics  Metrics
+}
+
+func newConn(d *Dialer, conn *snet.Conn, remote *snet.UDPAddr,
+	paths *pathmgr.SyncPaths) *Conn {
+
+	margin := d.ExpiryMargin
+	if margin == 0 {
+		margin = DefaultExpiryMargin
+	}
+	c := &Conn{
+		conn:    conn,
+		remote:  remote.Copy(),
+		paths:   paths,
+		metrics: d.Metrics,
+	}
+	c.selector = &selector{
+		paths:        func() spathmeta.AppPathSet { return paths.Load().APS },
+		policy:       d.Policy,
+		expiryMargin: margin,
+		now:          time.Now,
+		notify: func(change PathChange) {
+			change.Remote = c.remote.Copy()
+			c.metrics.incPathChanges(change.Reason)
+			if d.OnPathChange != nil {
+				d.OnPathChange(change)
+			}
+		},
+	}
+	return c
+}
+
+// Path returns the active path of the connection.
+func (c *Conn) Path() snet.Path {
+	return c.selector.Active()
+}
+
+// Write sends b to the remote over the active path. If the active path is no
+// longer usable, another path is selected first.
+func (c *Conn) Write(b []byte) (int, error) {
+	path, err := c.selector.Path()
+	if err != nil {
+		return 0, err
+	}
+	remote := c.remote.Copy()
+	remote.Path = path.Path()
+	remote.NextHop = path.UnderlayNextHop()
+	return c.conn.WriteTo(b, remote)
+}
+
+// Read reads data from the connection. Revocations that are received on the
+// connection are consumed by Read and are not returned to the caller. All
+// other errors are returned.
+func (c *Conn) Read(b []byte) (int, error) {
+	for {
+		n, err := c.conn.Read(b)
+		var opErr *snet.OpError
+		if errors.As(err, &opErr) && opErr.RevInfo() != nil {
+			c.metrics.incRevocations(c.selector.Revoke(opErr.RevInfo()))
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close closes the connection and stops watching the paths to the remote.
+func (c *Conn) Close() error {
+	c.paths.Destroy()
+	return c.conn.Close()
+}
+
+// LocalAddr returns the local address of the connection.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote address of the connection.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remote.Copy()
+}
+
+func (c *Conn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
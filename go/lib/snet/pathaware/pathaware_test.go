@@ -0,0 +1,144 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathaware
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/pathmgr/mock_pathmgr"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+func TestDialerDial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	short := newTestPath(now.Add(time.Hour), ia110, 1, ia112, 1)
+	long := newTestPath(now.Add(time.Hour), ia110, 2, ia111, 1, ia111, 2, ia112, 2)
+	long.nextHop = &net.UDPAddr{IP: net.IP{127, 0, 0, 2}, Port: 30041}
+	listen := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 40000}
+	remote := &snet.UDPAddr{
+		IA:   ia112,
+		Host: &net.UDPAddr{IP: net.IP{127, 0, 0, 3}, Port: 40001},
+	}
+	policy := &pathpol.Policy{Sort: pathpol.SortByHops}
+
+	newDialer := func(paths ...snet.Path) (*Dialer, *mock_snet.MockPacketConn) {
+		sp := pathmgr.NewSyncPaths()
+		sp.Update(spathmeta.NewAppPathSet(paths))
+		resolver := mock_pathmgr.NewMockResolver(ctrl)
+		resolver.EXPECT().WatchFilter(gomock.Any(), ia110, ia112, policy).Return(sp, nil)
+		packetConn := mock_snet.NewMockPacketConn(ctrl)
+		dispatcher := mock_snet.NewMockPacketDispatcherService(ctrl)
+		dispatcher.EXPECT().Register(gomock.Any(), ia110, listen, addr.SvcNone).
+			Return(packetConn, uint16(listen.Port), nil)
+		d := &Dialer{
+			Network:  &snet.SCIONNetwork{LocalIA: ia110, Dispatcher: dispatcher, Version2: true},
+			LocalIA:  ia110,
+			Resolver: resolver,
+			Policy:   policy,
+		}
+		return d, packetConn
+	}
+
+	t.Run("write uses the active path", func(t *testing.T) {
+		d, packetConn := newDialer(short, long)
+		var changes []PathChange
+		d.OnPathChange = func(change PathChange) { changes = append(changes, change) }
+		conn, err := d.Dial(context.Background(), listen, remote)
+		require.NoError(t, err)
+		assert.Equal(t, short, conn.Path())
+		assert.Equal(t, remote, conn.RemoteAddr())
+
+		packetConn.EXPECT().WriteTo(gomock.Any(), short.nextHop).DoAndReturn(
+			func(pkt *snet.Packet, _ *net.UDPAddr) error {
+				assert.Equal(t, short.Path(), pkt.Path)
+				assert.Equal(t, remote.IA, pkt.Destination.IA)
+				return nil
+			},
+		)
+		n, err := conn.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+
+		// After a revocation of an interface on the active path, the next
+		// write switches to the other path.
+		conn.selector.Revoke(revInfo(ia112, 1, time.Now()))
+		packetConn.EXPECT().WriteTo(gomock.Any(), long.nextHop).DoAndReturn(
+			func(pkt *snet.Packet, _ *net.UDPAddr) error {
+				assert.Equal(t, long.Path(), pkt.Path)
+				return nil
+			},
+		)
+		_, err = conn.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, long, conn.Path())
+		require.Len(t, changes, 2)
+		assert.Equal(t, ReasonRevoked, changes[1].Reason)
+		assert.Equal(t, remote, changes[1].Remote)
+
+		packetConn.EXPECT().Close()
+		assert.NoError(t, conn.Close())
+	})
+	t.Run("path change callback can access the connection", func(t *testing.T) {
+		d, packetConn := newDialer(short, long)
+		var conn *Conn
+		var active []snet.Path
+		d.OnPathChange = func(change PathChange) {
+			if conn != nil {
+				active = append(active, conn.Path())
+			}
+		}
+		conn, err := d.Dial(context.Background(), listen, remote)
+		require.NoError(t, err)
+
+		conn.selector.Revoke(revInfo(ia112, 1, time.Now()))
+		packetConn.EXPECT().WriteTo(gomock.Any(), long.nextHop)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := conn.Write([]byte("hello"))
+			assert.NoError(t, err)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("write did not return, callback deadlocked")
+		}
+		assert.Equal(t, []snet.Path{long}, active)
+
+		packetConn.EXPECT().Close()
+		assert.NoError(t, conn.Close())
+	})
+	t.Run("no path", func(t *testing.T) {
+		d, packetConn := newDialer()
+		packetConn.EXPECT().Close()
+		_, err := d.Dial(context.Background(), listen, remote)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathaware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+// ifKey identifies an interface of an AS.
+type ifKey struct {
+	ia   addr.IA
+	ifid common.IFIDType
+}
+
+// selector chooses the active path from a path set that is kept up to date
+// externally. The active path is kept as long as it is usable, i.e., it is
+// still part of the path set, it does not traverse a revoked interface, and it
+// does not expire within the expiry margin. Otherwise, the best usable path
+// according to the policy becomes the active path.
+type selector struct {
+	// paths returns the current path set.
+	paths        func() spathmeta.AppPathSet
+	policy       *pathpol.Policy
+	expiryMargin time.Duration
+	// notify is called whenever the active path changes. It is called without
+	// the lock held, so it may call back into the selector.
+	notify func(PathChange)
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	active  snet.Path
+	revoked map[ifKey]time.Time
+}
+
+// Path returns the active path. If the active path is no longer usable, a new
+// path is selected. If there is no usable path, ErrNoPath is returned.
+func (s *selector) Path() (snet.Path, error) {
+	s.mtx.Lock()
+	path, change, err := s.path()
+	s.mtx.Unlock()
+
+	if change != nil {
+		s.notify(*change)
+	}
+	return path, err
+}
+
+// path returns the active path and the path change, if any. It must be called
+// with the lock held.
+func (s *selector) path() (snet.Path, *PathChange, error) {
+	now := s.now()
+	for key, expiry := range s.revoked {
+		if !now.Before(expiry) {
+			delete(s.revoked, key)
+		}
+	}
+	aps := s.paths()
+	if s.active == nil {
+		return s.selectPath(aps, now, ReasonInitial)
+	}
+	// The path set contains the most recent version of the path, with an
+	// updated expiry.
+	current, ok := aps[snet.Fingerprint(s.active)]
+	if !ok {
+		return s.selectPath(aps, now, ReasonRemoved)
+	}
+	if reason := s.unusable(current, now); reason != "" {
+		return s.selectPath(aps, now, reason)
+	}
+	s.active = current
+	return s.active, nil, nil
+}
+
+// Active returns the active path without checking whether it is still usable.
+// It returns nil if no path has been selected yet.
+func (s *selector) Active() snet.Path {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.active
+}
+
+// Revoke excludes the revoked interface from path selection until the
+// revocation expires. It returns whether the active path traverses the
+// interface. If so, a new path is selected with the next call to Path.
+func (s *selector) Revoke(revInfo *path_mgmt.RevInfo) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	expiry := revInfo.Expiration()
+	if !s.now().Before(expiry) {
+		return false
+	}
+	key := ifKey{ia: revInfo.IA(), ifid: revInfo.IfID}
+	if s.revoked == nil {
+		s.revoked = make(map[ifKey]time.Time)
+	}
+	if expiry.After(s.revoked[key]) {
+		s.revoked[key] = expiry
+	}
+	return s.active != nil && s.traversesRevoked(s.active)
+}
+
+// selectPath selects the best usable path and returns the resulting path
+// change. It must be called with the lock held.
+func (s *selector) selectPath(aps spathmeta.AppPathSet, now time.Time,
+	reason Reason) (snet.Path, *PathChange, error) {
+
+	usable := make(pathpol.PathSet)
+	for key, path := range aps {
+		if s.unusable(path, now) == "" {
+			usable[key] = path
+		}
+	}
+	old := s.active
+	s.active = nil
+	if sorted := s.policy.SortedPaths(usable); len(sorted) > 0 {
+		s.active = sorted[0].(snet.Path)
+	}
+	var change *PathChange
+	if old != nil || s.active != nil {
+		change = &PathChange{Old: old, New: s.active, Reason: reason}
+	}
+	if s.active == nil {
+		return nil, change, ErrNoPath
+	}
+	return s.active, change, nil
+}
+
+// unusable returns the reason why the path must not be used, or an empty
+// reason if the path can be used.
+func (s *selector) unusable(path snet.Path, now time.Time) Reason {
+	if s.traversesRevoked(path) {
+		return ReasonRevoked
+	}
+	if md := path.Metadata(); md != nil && !md.Expiry().IsZero() &&
+		!now.Add(s.expiryMargin).Before(md.Expiry()) {
+
+		return ReasonExpired
+	}
+	return ""
+}
+
+func (s *selector) traversesRevoked(path snet.Path) bool {
+	if len(s.revoked) == 0 {
+		return false
+	}
+	for _, intf := range path.Interfaces() {
+		if _, ok := s.revoked[ifKey{ia: intf.IA(), ifid: intf.ID()}]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathaware
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	ia110 = xtest.MustParseIA("1-ff00:0:110")
+	ia111 = xtest.MustParseIA("1-ff00:0:111")
+	ia112 = xtest.MustParseIA("1-ff00:0:112")
+)
+
+func TestSelectorPath(t *testing.T) {
+	now := time.Now()
+	// Both paths go from 110 to 112. The short path is preferred by hop
+	// count.
+	short := newTestPath(now.Add(time.Hour), ia110, 1, ia112, 1)
+	long := newTestPath(now.Add(time.Hour), ia110, 2, ia111, 1, ia111, 2, ia112, 2)
+	policy := &pathpol.Policy{Sort: pathpol.SortByHops}
+
+	t.Run("initial path is the best path", func(t *testing.T) {
+		s, changes := newTestSelector(policy, now, short, long)
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, short, path)
+		assert.Equal(t, []PathChange{{New: short, Reason: ReasonInitial}}, *changes)
+	})
+	t.Run("active path is kept", func(t *testing.T) {
+		s, changes := newTestSelector(nil, now, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		// A better path appearing does not replace a usable active path.
+		s.paths = staticPaths(short, long)
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, long, path)
+		assert.Len(t, *changes, 1)
+	})
+	t.Run("revoked", func(t *testing.T) {
+		s, changes := newTestSelector(policy, now, short, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		assert.False(t, s.Revoke(revInfo(ia111, 3, now)))
+		assert.True(t, s.Revoke(revInfo(ia112, 1, now)))
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, long, path)
+		assert.Equal(t, PathChange{Old: short, New: long, Reason: ReasonRevoked},
+			(*changes)[1])
+		// Both paths are revoked.
+		assert.True(t, s.Revoke(revInfo(ia111, 1, now)))
+		_, err = s.Path()
+		assert.Equal(t, ErrNoPath, err)
+		assert.Equal(t, PathChange{Old: long, Reason: ReasonRevoked}, (*changes)[2])
+		// The revocations expire.
+		s.now = func() time.Time { return now.Add(time.Minute) }
+		path, err = s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, short, path)
+		assert.Equal(t, PathChange{New: short, Reason: ReasonInitial}, (*changes)[3])
+	})
+	t.Run("expired revocation is ignored", func(t *testing.T) {
+		s, _ := newTestSelector(policy, now, short, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		assert.False(t, s.Revoke(revInfo(ia112, 1, now.Add(-time.Minute))))
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, short, path)
+	})
+	t.Run("expired", func(t *testing.T) {
+		expiring := newTestPath(now.Add(5*time.Second), ia110, 1, ia112, 1)
+		s, changes := newTestSelector(policy, now.Add(-time.Minute), expiring, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		s.now = func() time.Time { return now }
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, long, path)
+		assert.Equal(t, PathChange{Old: expiring, New: long, Reason: ReasonExpired},
+			(*changes)[1])
+	})
+	t.Run("refreshed path is used", func(t *testing.T) {
+		expiring := newTestPath(now.Add(5*time.Second), ia110, 1, ia112, 1)
+		s, changes := newTestSelector(policy, now.Add(-time.Minute), expiring, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		s.now = func() time.Time { return now }
+		s.paths = staticPaths(short, long)
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, short, path)
+		assert.Len(t, *changes, 1)
+	})
+	t.Run("removed", func(t *testing.T) {
+		s, changes := newTestSelector(policy, now, short, long)
+		_, err := s.Path()
+		require.NoError(t, err)
+		s.paths = staticPaths(long)
+		path, err := s.Path()
+		require.NoError(t, err)
+		assert.Equal(t, long, path)
+		assert.Equal(t, PathChange{Old: short, New: long, Reason: ReasonRemoved},
+			(*changes)[1])
+	})
+	t.Run("no paths", func(t *testing.T) {
+		s, changes := newTestSelector(policy, now)
+		_, err := s.Path()
+		assert.Equal(t, ErrNoPath, err)
+		assert.Nil(t, s.Active())
+		assert.Empty(t, *changes)
+	})
+}
+
+// newTestSelector creates a selector for a static path set. The returned
+// slice collects the path changes.
+func newTestSelector(policy *pathpol.Policy, now time.Time,
+	paths ...snet.Path) (*selector, *[]PathChange) {
+
+	var changes []PathChange
+	s := &selector{
+		paths:        staticPaths(paths...),
+		policy:       policy,
+		expiryMargin: DefaultExpiryMargin,
+		now:          func() time.Time { return now },
+		notify:       func(change PathChange) { changes = append(changes, change) },
+	}
+	return s, &changes
+}
+
+func staticPaths(paths ...snet.Path) func() spathmeta.AppPathSet {
+	aps := spathmeta.NewAppPathSet(paths)
+	return func() spathmeta.AppPathSet { return aps }
+}
+
+func revInfo(ia addr.IA, ifid common.IFIDType, timestamp time.Time) *path_mgmt.RevInfo {
+	return &path_mgmt.RevInfo{
+		IfID:         ifid,
+		RawIsdas:     ia.IAInt(),
+		RawTimestamp: util.TimeToSecs(timestamp),
+		RawTTL:       10,
+	}
+}
+
+// testPath is a path with the given interfaces. The interfaces are given as
+// pairs of ISD-AS and interface ID.
+type testPath struct {
+	interfaces []snet.PathInterface
+	expiry     time.Time
+	nextHop    *net.UDPAddr
+}
+
+func newTestPath(expiry time.Time, interfaces ...interface{}) *testPath {
+	p := &testPath{
+		expiry:  expiry,
+		nextHop: &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041},
+	}
+	for i := 0; i < len(interfaces); i += 2 {
+		p.interfaces = append(p.interfaces, sciond.PathInterface{
+			RawIsdas: interfaces[i].(addr.IA).IAInt(),
+			IfID:     common.IFIDType(interfaces[i+1].(int)),
+		})
+	}
+	return p
+}
+
+func (p *testPath) UnderlayNextHop() *net.UDPAddr {
+	return p.nextHop
+}
+
+func (p *testPath) Path() *spath.Path {
+	return &spath.Path{Raw: common.RawBytes{byte(len(p.interfaces))}}
+}
+
+func (p *testPath) Interfaces() []snet.PathInterface {
+	return p.interfaces
+}
+
+func (p *testPath) Destination() addr.IA {
+	return p.interfaces[len(p.interfaces)-1].IA()
+}
+
+func (p *testPath) Metadata() snet.PathMetadata {
+	return p
+}
+
+func (p *testPath) MTU() uint16 {
+	return 1472
+}
+
+func (p *testPath) Expiry() time.Time {
+	return p.expiry
+}
+
+func (p *testPath) Hops() []snet.HopMetadata {
+	return nil
+}
+
+func (p *testPath) Copy() snet.Path {
+	c := *p
+	return &c
+}
@@ -48,7 +48,8 @@ var sizeIgnore = flag.Bool("overlay.conn.sizeIgnore", true,
 	"Ignore failing to set the receive buffer size on a socket.")
 
 // Messages is a list of ipX.Messages. It is necessary to hide the type alias
-// between ipv4.Message, ipv6.Message and socket.Message.
+// between ipv4.Message, ipv6.Message and socket.Message. The messages are used
+// for both IPv4 and IPv6 sockets.
 type Messages []ipv4.Message
 
 // Conn describes the API for an underlay socket with additional metadata on
@@ -96,6 +97,12 @@ func New(listen, remote *net.UDPAddr, cfg *Config) (Conn, error) {
 	if assert.On {
 		assert.Must(listen != nil || remote != nil, "Either listen or remote must be set")
 	}
+	if listen != nil && remote != nil && !listen.IP.IsUnspecified() &&
+		(listen.IP.To4() == nil) != (remote.IP.To4() == nil) {
+
+		return nil, serrors.New("listen and remote address family differ",
+			"listen", listen, "remote", remote)
+	}
 	if a.IP.To4() != nil {
 		return newConnUDPIPv4(listen, remote, cfg)
 	}
@@ -395,8 +402,8 @@ func (m *ReadMeta) setSrc(a *net.UDPAddr, raddr *net.UDPAddr, ot underlay.Type)
 	}
 }
 
-// NewReadMessages allocates memory for reading IPv4 Linux network stack
-// messages.
+// NewReadMessages allocates memory for reading IPv4 and IPv6 Linux network
+// stack messages.
 func NewReadMessages(n int) Messages {
 	m := make(Messages, n)
 	for i := range m {
@@ -407,8 +414,8 @@ func NewReadMessages(n int) Messages {
 	return m
 }
 
-// NewWriteMessages allocates memory for writing IPv4 Linux network stack
-// messages.
+// NewWriteMessages allocates memory for writing IPv4 and IPv6 Linux network
+// stack messages.
 func NewWriteMessages(n int) Messages {
 	m := make(Messages, n)
 	for i := range m {
@@ -0,0 +1,121 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build go1.9,linux
+
+package conn_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/underlay/conn"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("address family mismatch fails", func(t *testing.T) {
+		_, err := conn.New(
+			&net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: freePort(t, "udp4")},
+			&net.UDPAddr{IP: net.ParseIP("::1"), Port: 40000},
+			nil,
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	testCases := map[string]struct {
+		network string
+		ip      net.IP
+	}{
+		"IPv4": {network: "udp4", ip: net.ParseIP("127.0.0.1")},
+		"IPv6": {network: "udp6", ip: net.ParseIP("::1")},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			if tc.network == "udp6" && !ipv6Available() {
+				t.Skip("IPv6 loopback not available")
+			}
+			listenAddr := &net.UDPAddr{IP: tc.ip, Port: freePort(t, tc.network)}
+			dialAddr := &net.UDPAddr{IP: tc.ip, Port: freePort(t, tc.network)}
+
+			listener, err := conn.New(listenAddr, nil, nil)
+			require.NoError(t, err)
+			defer listener.Close()
+			dialer, err := conn.New(dialAddr, listenAddr, nil)
+			require.NoError(t, err)
+			defer dialer.Close()
+
+			// Connected socket to unconnected socket.
+			out := conn.NewWriteMessages(1)
+			out[0].Buffers[0] = []byte("request")
+			out[0].Addr = nil
+			n, err := dialer.WriteBatch(out)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			in := conn.NewReadMessages(1)
+			in[0].Buffers[0] = make([]byte, 100)
+			metas := make([]conn.ReadMeta, 1)
+			require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+			n, err = listener.ReadBatch(in, metas)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+			assert.Equal(t, []byte("request"), in[0].Buffers[0][:in[0].N])
+			assert.True(t, dialAddr.IP.Equal(metas[0].Src.IP))
+			assert.Equal(t, dialAddr.Port, metas[0].Src.Port)
+
+			// Unconnected socket back to the source of the request.
+			out[0].Buffers[0] = []byte("reply")
+			out[0].Addr = metas[0].Src
+			n, err = listener.WriteBatch(out)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			require.NoError(t, dialer.SetReadDeadline(time.Now().Add(time.Second)))
+			n, err = dialer.ReadBatch(in, metas)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+			assert.Equal(t, []byte("reply"), in[0].Buffers[0][:in[0].N])
+			assert.Equal(t, listenAddr, metas[0].Src)
+		})
+	}
+}
+
+// freePort returns a UDP port that is currently not in use on the loopback
+// address of the given network.
+func freePort(t *testing.T, network string) int {
+	ip := net.ParseIP("127.0.0.1")
+	if network == "udp6" {
+		ip = net.ParseIP("::1")
+	}
+	c, err := net.ListenUDP(network, &net.UDPAddr{IP: ip})
+	require.NoError(t, err)
+	defer c.Close()
+	return c.LocalAddr().(*net.UDPAddr).Port
+}
+
+func ipv6Available() bool {
+	c, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.ParseIP("::1")})
+	if err != nil {
+		return false
+	}
+	c.Close()
+	return true
+}
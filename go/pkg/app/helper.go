@@ -27,6 +27,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -50,18 +51,42 @@ func QueryASInfo(ctx context.Context, conn sciond.Connector) (ASInfo, error) {
 	}, nil
 }
 
+// PathQuery configures which paths ChoosePath chooses from.
+type PathQuery struct {
+	// Refresh sets the refresh flag of the path request.
+	Refresh bool
+	// Sequence is a string of space separated hop predicates that the paths
+	// must match. If it is empty, all paths match.
+	Sequence string
+	// Policy is a path policy that the SCION Daemon applies to the paths. If a
+	// policy is set and the path is not chosen interactively, the first path
+	// in the order of the policy is chosen instead of a random one.
+	Policy *pathpol.Policy
+	// Interactive lets the user choose the path on the terminal.
+	Interactive bool
+}
+
 // ChoosePath selects a path to the remote.
 func ChoosePath(ctx context.Context, conn sciond.Connector, remote addr.IA,
-	interactive, refresh bool, opts ...ColorOption) (snet.Path, error) {
+	query PathQuery, opts ...ColorOption) (snet.Path, error) {
 
-	paths, err := conn.Paths(ctx, remote, addr.IA{}, sciond.PathReqFlags{Refresh: refresh})
+	paths, err := conn.Paths(ctx, remote, addr.IA{},
+		sciond.PathReqFlags{Refresh: query.Refresh, Policy: query.Policy})
 	if err != nil {
 		return nil, serrors.WrapStr("retreiving paths", err)
 	}
+	if query.Sequence != "" {
+		if paths, err = filterSequence(paths, query.Sequence); err != nil {
+			return nil, err
+		}
+	}
 	if len(paths) == 0 {
 		return nil, serrors.New("no path available")
 	}
-	if !interactive {
+	if !query.Interactive {
+		if query.Policy != nil {
+			return paths[0], nil
+		}
 		return paths[rand.Intn(len(paths))], nil
 	}
 
@@ -97,6 +122,27 @@ func ChoosePath(ctx context.Context, conn sciond.Connector, remote addr.IA,
 	}
 }
 
+// filterSequence returns the paths that match the sequence, in their original
+// order.
+func filterSequence(paths []snet.Path, sequence string) ([]snet.Path, error) {
+	seq, err := pathpol.NewSequence(sequence)
+	if err != nil {
+		return nil, serrors.WrapStr("parsing sequence", err)
+	}
+	ps := make(pathpol.PathSet, len(paths))
+	for _, p := range paths {
+		ps[snet.Fingerprint(p)] = p
+	}
+	keep := seq.Eval(ps)
+	result := make([]snet.Path, 0, len(keep))
+	for _, p := range paths {
+		if _, ok := keep[snet.Fingerprint(p)]; ok {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
 // WithSignal derives a child context that subsribes a signal handler for the
 // provided signals. The returned context gets cancled if any of the subscribed
 // signals is received
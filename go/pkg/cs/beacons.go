@@ -0,0 +1,145 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// BeaconsHandler serves the beacons in the beacon DB as JSON. The beacons can
+// be restricted to a start ISD-AS and an ingress interface with the query
+// parameters start_isd_as and ingress_interface. For every beacon, the stored
+// usage and the result of the filters of the current beaconing policies are
+// reported. The expiration of a beacon is the expiration time of the hop field
+// that expires first, after it the beacon can no longer be used.
+type BeaconsHandler struct {
+	DB beacon.DBRead
+	// Policies returns the beaconing policies that are currently used.
+	Policies func() []beacon.Policy
+}
+
+type beaconHop struct {
+	IA      addr.IA         `json:"isd_as"`
+	Ingress common.IFIDType `json:"ingress_interface"`
+	Egress  common.IFIDType `json:"egress_interface"`
+}
+
+type beaconPolicyResult struct {
+	Type beacon.PolicyType `json:"type"`
+	// Usable indicates whether the stored usage allows the beacon for the
+	// policy.
+	Usable bool `json:"usable"`
+	// Filtered contains the reason why the current policy filters the beacon.
+	// It is empty if the beacon is allowed.
+	Filtered string `json:"filtered,omitempty"`
+}
+
+type beaconInfo struct {
+	ID               string               `json:"id"`
+	StartIA          addr.IA              `json:"start_isd_as"`
+	IngressInterface common.IFIDType      `json:"ingress_interface"`
+	Hops             []beaconHop          `json:"hops"`
+	Timestamp        time.Time            `json:"timestamp"`
+	Expiration       time.Time            `json:"expiration"`
+	LastUpdated      time.Time            `json:"last_updated"`
+	Policies         []beaconPolicyResult `json:"policies"`
+}
+
+func (h BeaconsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params, err := beaconQueryParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stored, err := h.DB.GetBeacons(r.Context(), params)
+	if err != nil {
+		log.FromCtx(r.Context()).Info("Unable to get beacons", "err", err)
+		http.Error(w, "Unable to get beacons", http.StatusInternalServerError)
+		return
+	}
+	var policies []beacon.Policy
+	if h.Policies != nil {
+		policies = h.Policies()
+	}
+	rep := make([]beaconInfo, 0, len(stored))
+	for _, b := range stored {
+		rep = append(rep, newBeaconInfo(b, policies))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(rep); err != nil {
+		http.Error(w, "Unable to marshal response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func beaconQueryParams(query url.Values) (beacon.QueryParams, error) {
+	var params beacon.QueryParams
+	if raw := query.Get("start_isd_as"); raw != "" {
+		ia, err := addr.IAFromString(raw)
+		if err != nil {
+			return params, serrors.WrapStr("parsing start_isd_as", err)
+		}
+		params.StartsAt = ia
+	}
+	if raw := query.Get("ingress_interface"); raw != "" {
+		if err := params.IngressInterface.UnmarshalText([]byte(raw)); err != nil {
+			return params, serrors.WrapStr("parsing ingress_interface", err)
+		}
+	}
+	return params, nil
+}
+
+func newBeaconInfo(b beacon.StoredBeacon, policies []beacon.Policy) beaconInfo {
+	hops := make([]beaconHop, 0, len(b.Segment.ASEntries))
+	for _, entry := range b.Segment.ASEntries {
+		hops = append(hops, beaconHop{
+			IA:      entry.Local,
+			Ingress: common.IFIDType(entry.HopEntry.HopField.ConsIngress),
+			Egress:  common.IFIDType(entry.HopEntry.HopField.ConsEgress),
+		})
+	}
+	results := make([]beaconPolicyResult, 0, len(policies))
+	for _, policy := range policies {
+		result := beaconPolicyResult{
+			Type:   policy.Type,
+			Usable: b.Usage&beacon.UsageFromPolicyType(policy.Type) != 0,
+		}
+		if err := policy.Filter.Apply(b.Beacon); err != nil {
+			result.Filtered = err.Error()
+		}
+		results = append(results, result)
+	}
+	return beaconInfo{
+		ID:               b.Segment.GetLoggingID(),
+		StartIA:          b.Segment.FirstIA(),
+		IngressInterface: b.InIfId,
+		Hops:             hops,
+		Timestamp:        b.Segment.Info.Timestamp,
+		Expiration:       b.Segment.MinExpiry(),
+		LastUpdated:      b.LastUpdated,
+		Policies:         results,
+	}
+}
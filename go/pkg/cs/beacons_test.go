@@ -0,0 +1,170 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cs_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/cs/beacon/beacondbtest"
+	"github.com/scionproto/scion/go/cs/beacon/mock_beacon"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/cs"
+)
+
+func TestBeaconsHandlerQueryParams(t *testing.T) {
+	tests := map[string]struct {
+		Query          string
+		ExpectedParams *beacon.QueryParams
+		ExpectedStatus int
+	}{
+		"no parameters": {
+			ExpectedParams: &beacon.QueryParams{},
+			ExpectedStatus: http.StatusOK,
+		},
+		"start ISD-AS and ingress interface": {
+			Query: "?start_isd_as=1-ff00:0:330&ingress_interface=12",
+			ExpectedParams: &beacon.QueryParams{
+				StartsAt:         xtest.MustParseIA("1-ff00:0:330"),
+				IngressInterface: 12,
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		"wildcard start ISD-AS": {
+			Query:          "?start_isd_as=1-0",
+			ExpectedParams: &beacon.QueryParams{StartsAt: xtest.MustParseIA("1-0")},
+			ExpectedStatus: http.StatusOK,
+		},
+		"bad start ISD-AS": {
+			Query:          "?start_isd_as=garbage",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		"bad ingress interface": {
+			Query:          "?ingress_interface=-1",
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range tests {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mctrl := gomock.NewController(t)
+			defer mctrl.Finish()
+
+			db := mock_beacon.NewMockDB(mctrl)
+			if tc.ExpectedParams != nil {
+				db.EXPECT().GetBeacons(gomock.Any(), *tc.ExpectedParams)
+			}
+			handler := cs.BeaconsHandler{DB: db}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beacons"+tc.Query, nil))
+			assert.Equal(t, tc.ExpectedStatus, w.Code)
+		})
+	}
+}
+
+func TestBeaconsHandlerServeHTTP(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	short, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info2, 12, 0)
+	long, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info3, 13, 0)
+	// The hop field of the second AS entry expires first.
+	long.Segment.ASEntries[1].HopEntry.HopField.ExpTime = 0
+	lastUpdated := time.Now().Truncate(time.Second)
+
+	db := mock_beacon.NewMockDB(mctrl)
+	db.EXPECT().GetBeacons(gomock.Any(), beacon.QueryParams{}).Return(
+		[]beacon.StoredBeacon{
+			{Beacon: short, Usage: beacon.UsageUpReg, LastUpdated: lastUpdated},
+			{Beacon: long, Usage: beacon.UsageUpReg | beacon.UsageDownReg},
+		}, nil,
+	)
+	up := beacon.Policy{Type: beacon.UpRegPolicy}
+	down := beacon.Policy{Type: beacon.DownRegPolicy, Filter: beacon.Filter{MaxHopsLength: 2}}
+	up.InitDefaults()
+	down.InitDefaults()
+	handler := cs.BeaconsHandler{
+		DB:       db,
+		Policies: func() []beacon.Policy { return []beacon.Policy{up, down} },
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beacons", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	type policyResult struct {
+		Type     beacon.PolicyType `json:"type"`
+		Usable   bool              `json:"usable"`
+		Filtered string            `json:"filtered"`
+	}
+	var rep []struct {
+		ID               string          `json:"id"`
+		IngressInterface common.IFIDType `json:"ingress_interface"`
+		Expiration       time.Time       `json:"expiration"`
+		LastUpdated      time.Time       `json:"last_updated"`
+		Hops             []struct{}      `json:"hops"`
+		Policies         []policyResult  `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rep))
+	require.Len(t, rep, 2)
+
+	assert.Equal(t, short.Segment.GetLoggingID(), rep[0].ID)
+	assert.Equal(t, common.IFIDType(12), rep[0].IngressInterface)
+	assert.Len(t, rep[0].Hops, 2)
+	assert.True(t, lastUpdated.Equal(rep[0].LastUpdated))
+	assert.Equal(t, []policyResult{
+		{Type: beacon.UpRegPolicy, Usable: true},
+		{Type: beacon.DownRegPolicy, Usable: false},
+	}, rep[0].Policies)
+
+	assert.Equal(t, long.Segment.GetLoggingID(), rep[1].ID)
+	assert.Equal(t, common.IFIDType(13), rep[1].IngressInterface)
+	assert.Len(t, rep[1].Hops, 3)
+	require.Len(t, rep[1].Policies, 2)
+	assert.Equal(t, policyResult{Type: beacon.UpRegPolicy, Usable: true}, rep[1].Policies[0])
+	assert.Equal(t, beacon.DownRegPolicy, rep[1].Policies[1].Type)
+	assert.True(t, rep[1].Policies[1].Usable)
+	assert.Contains(t, rep[1].Policies[1].Filtered, "MaxHopsLength exceeded")
+
+	// The expiration is the one of the hop field that expires first.
+	expiration := long.Segment.Info.Timestamp.Add(spath.ExpTimeType(0).ToDuration())
+	assert.True(t, expiration.Equal(rep[1].Expiration),
+		"expected: %s, actual: %s", expiration, rep[1].Expiration)
+	assert.True(t, rep[1].Expiration.Before(long.Segment.MaxExpiry()))
+}
+
+func TestBeaconsHandlerDBError(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	db := mock_beacon.NewMockDB(mctrl)
+	db.EXPECT().GetBeacons(gomock.Any(), gomock.Any()).Return(nil, serrors.New("db down"))
+	handler := cs.BeaconsHandler{DB: db}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/beacons", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
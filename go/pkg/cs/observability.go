@@ -45,9 +45,10 @@ func InitTracer(tracing env.Tracing, id string) (io.Closer, error) {
 }
 
 // StartHTTPEndpoints starts the HTTP endpoints that expose the metrics and
-// additional information.
+// additional information. The additional pages are registered alongside the
+// default status pages.
 func StartHTTPEndpoints(elemId string, cfg interface{}, signer cstrust.RenewingSigner,
-	ca cstrust.ChainBuilder, metrics env.Metrics) error {
+	ca cstrust.ChainBuilder, metrics env.Metrics, pages service.StatusPages) error {
 	statusPages := service.StatusPages{
 		"info":      service.NewInfoHandler(),
 		"config":    service.NewConfigHandler(cfg),
@@ -58,6 +59,9 @@ func StartHTTPEndpoints(elemId string, cfg interface{}, signer cstrust.RenewingS
 	if ca != (cstrust.ChainBuilder{}) {
 		statusPages["ca"] = caHandler(ca)
 	}
+	for name, handler := range pages {
+		statusPages[name] = handler
+	}
 	if err := statusPages.Register(http.DefaultServeMux, elemId); err != nil {
 		return serrors.WrapStr("registering status pages", err)
 	}
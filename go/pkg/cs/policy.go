@@ -15,8 +15,17 @@
 package cs
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
 	"github.com/scionproto/scion/go/cs/beacon"
 	"github.com/scionproto/scion/go/cs/config"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/metrics"
+	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/serrors"
 )
 
@@ -61,3 +70,78 @@ func loadPolicy(fn string, t beacon.PolicyType) (beacon.Policy, error) {
 	policy.InitDefaults()
 	return policy, nil
 }
+
+// PolicyReloader reloads the beaconing policies from the configured files and
+// applies them to the beacon store. The ISD loop setting of the propagation
+// filter that is used when propagating beacons is not reloaded.
+type PolicyReloader struct {
+	// Policies are the configured policy files.
+	Policies config.Policies
+	// Store is the beacon store the policies are applied to. It must either be
+	// a *beacon.Store or a *beacon.CoreStore.
+	Store Store
+	// Reloads counts the reloads by result. It is optional.
+	Reloads metrics.Counter
+
+	mtx sync.Mutex
+}
+
+// Reload loads and validates all policies and applies them to the store. If
+// any of the policies can not be loaded, none of them is applied and the store
+// keeps the previous policies.
+func (r *PolicyReloader) Reload(ctx context.Context) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	logger := log.FromCtx(ctx)
+	if err := r.reload(ctx); err != nil {
+		logger.Info("Failed to reload beaconing policies, keeping previous policies",
+			"err", err)
+		r.updateMetric(prom.ErrValidate)
+		return err
+	}
+	logger.Info("Reloaded beaconing policies", "policies", r.Policies)
+	r.updateMetric(prom.Success)
+	return nil
+}
+
+// ServeHTTP reloads the policies on PUT requests.
+func (r *PolicyReloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPut {
+		http.Error(w, "Only PUT is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	if err := r.Reload(req.Context()); err != nil {
+		http.Error(w, fmt.Sprintf("Unable to reload beaconing policies: %s", err),
+			http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintln(w, "Reloaded beaconing policies")
+}
+
+// reload loads the policies and replaces the whole set of policies of the
+// store at once.
+func (r *PolicyReloader) reload(ctx context.Context) error {
+	switch store := r.Store.(type) {
+	case *beacon.CoreStore:
+		policies, err := LoadCorePolicies(r.Policies)
+		if err != nil {
+			return err
+		}
+		return store.UpdatePolicies(ctx, policies)
+	case *beacon.Store:
+		policies, err := LoadNonCorePolicies(r.Policies)
+		if err != nil {
+			return err
+		}
+		return store.UpdatePolicies(ctx, policies)
+	default:
+		return serrors.New("unsupported beacon store", "type", common.TypeOf(r.Store))
+	}
+}
+
+func (r *PolicyReloader) updateMetric(result string) {
+	if r.Reloads != nil {
+		r.Reloads.With(prom.LabelResult, result).Add(1)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cs_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/cs/beacon/mock_beacon"
+	"github.com/scionproto/scion/go/cs/config"
+	"github.com/scionproto/scion/go/lib/metrics"
+	"github.com/scionproto/scion/go/lib/prom"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/cs"
+)
+
+func TestPolicyReloaderReload(t *testing.T) {
+	dir, cleanF := xtest.MustTempDir("", "policy_reloader")
+	defer cleanF()
+	fn := filepath.Join(dir, "up.yml")
+
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	store, err := beacon.NewBeaconStore(beacon.Policies{
+		UpReg: beacon.Policy{BestSetSize: 5},
+	}, mock_beacon.NewMockDB(mctrl))
+	require.NoError(t, err)
+	reloads := metrics.NewTestCounter()
+	reloader := &cs.PolicyReloader{
+		Policies: config.Policies{UpRegistration: fn},
+		Store:    store,
+		Reloads:  reloads,
+	}
+
+	t.Run("invalid file keeps the previous policies", func(t *testing.T) {
+		writeFile(t, fn, "BestSetSize: 3\nAlgorithm: Unknown\n")
+		err := reloader.Reload(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 5, store.Policies().UpReg.BestSetSize)
+		assert.Equal(t, float64(1),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.ErrValidate)))
+		assert.Equal(t, float64(0),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.Success)))
+	})
+	t.Run("valid file replaces the policies", func(t *testing.T) {
+		writeFile(t, fn, "BestSetSize: 3\n")
+		err := reloader.Reload(context.Background())
+		require.NoError(t, err)
+		policies := store.Policies()
+		assert.Equal(t, 3, policies.UpReg.BestSetSize)
+		assert.Equal(t, beacon.UpRegPolicy, policies.UpReg.Type)
+		assert.Equal(t, beacon.DefaultBestSetSize, policies.DownReg.BestSetSize)
+		assert.Equal(t, float64(1),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.Success)))
+	})
+}
+
+func TestPolicyReloaderReloadCore(t *testing.T) {
+	dir, cleanF := xtest.MustTempDir("", "policy_reloader")
+	defer cleanF()
+	fn := filepath.Join(dir, "core.yml")
+	writeFile(t, fn, "BestSetSize: 3\n")
+
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	store, err := beacon.NewCoreBeaconStore(beacon.CorePolicies{}, mock_beacon.NewMockDB(mctrl))
+	require.NoError(t, err)
+	reloader := &cs.PolicyReloader{
+		Policies: config.Policies{CoreRegistration: fn},
+		Store:    store,
+	}
+	require.NoError(t, reloader.Reload(context.Background()))
+	assert.Equal(t, 3, store.Policies().CoreReg.BestSetSize)
+	assert.Equal(t, beacon.DefaultBestSetSize, store.Policies().Prop.BestSetSize)
+}
+
+func TestPolicyReloaderServeHTTP(t *testing.T) {
+	dir, cleanF := xtest.MustTempDir("", "policy_reloader")
+	defer cleanF()
+	fn := filepath.Join(dir, "up.yml")
+
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	store, err := beacon.NewBeaconStore(beacon.Policies{}, mock_beacon.NewMockDB(mctrl))
+	require.NoError(t, err)
+	reloads := metrics.NewTestCounter()
+	reloader := &cs.PolicyReloader{
+		Policies: config.Policies{UpRegistration: fn},
+		Store:    store,
+		Reloads:  reloads,
+	}
+	serve := func(method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		reloader.ServeHTTP(w, httptest.NewRequest(method, "/policies", nil))
+		return w
+	}
+
+	t.Run("only PUT is supported", func(t *testing.T) {
+		writeFile(t, fn, "BestSetSize: 3\n")
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+			assert.Equal(t, http.StatusMethodNotAllowed, serve(method).Code, method)
+		}
+		assert.Equal(t, beacon.DefaultBestSetSize, store.Policies().UpReg.BestSetSize)
+		assert.Equal(t, float64(0),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.Success)))
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		writeFile(t, fn, "BestSetSize: [3]\n")
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut).Code)
+		assert.Equal(t, beacon.DefaultBestSetSize, store.Policies().UpReg.BestSetSize)
+		assert.Equal(t, float64(1),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.ErrValidate)))
+	})
+	t.Run("valid file", func(t *testing.T) {
+		writeFile(t, fn, "BestSetSize: 3\n")
+		assert.Equal(t, http.StatusOK, serve(http.MethodPut).Code)
+		assert.Equal(t, 3, store.Policies().UpReg.BestSetSize)
+		assert.Equal(t, float64(1),
+			metrics.CounterValue(reloads.With(prom.LabelResult, prom.Success)))
+	})
+}
+
+func writeFile(t *testing.T, fn, content string) {
+	t.Helper()
+	require.NoError(t, ioutil.WriteFile(fn, []byte(content), 0644))
+}
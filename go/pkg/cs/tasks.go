@@ -373,9 +373,6 @@ type Store interface {
 	InsertRevocations(ctx context.Context, revocations ...*path_mgmt.SignedRevInfo) error
 	// DeleteRevocation deletes the revocation from the BeaconDB.
 	DeleteRevocation(ctx context.Context, ia addr.IA, ifid common.IFIDType) error
-	// UpdatePolicy updates the policy. Beacons that are filtered by all
-	// policies after the update are removed.
-	UpdatePolicy(ctx context.Context, policy beacon.Policy) error
 	// MaxExpTime returns the segment maximum expiration time for the given policy.
 	MaxExpTime(policyType beacon.PolicyType) spath.ExpTimeType
 	// DeleteExpired deletes expired Beacons from the store.
@@ -0,0 +1,98 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathmon
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var pathLabels = []string{"remote", "fingerprint"}
+
+var (
+	rttDesc = prometheus.NewDesc(
+		"pathmon_path_rtt_seconds",
+		"Round trip time of the last ping reply over the path.",
+		pathLabels, nil,
+	)
+	lossDesc = prometheus.NewDesc(
+		"pathmon_path_loss_ratio",
+		"Ratio of lost pings among the most recent pings over the path.",
+		pathLabels, nil,
+	)
+	upDesc = prometheus.NewDesc(
+		"pathmon_path_up",
+		"Whether the path is up (1) or down (0).",
+		pathLabels, nil,
+	)
+	sentDesc = prometheus.NewDesc(
+		"pathmon_pings_sent_total",
+		"Total number of pings sent over the path.",
+		pathLabels, nil,
+	)
+	receivedDesc = prometheus.NewDesc(
+		"pathmon_pings_received_total",
+		"Total number of ping replies received over the path.",
+		pathLabels, nil,
+	)
+)
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Collector implements a prometheus collector that exports the state of all
+// monitored paths.
+type Collector struct {
+	monitor *Monitor
+}
+
+// NewCollector creates a prometheus collector that exports the state of all
+// paths monitored by the monitor.
+func NewCollector(monitor *Monitor) *Collector {
+	return &Collector{
+		monitor: monitor,
+	}
+}
+
+// Collect is called by prometheus to get the path states.
+func (c *Collector) Collect(mc chan<- prometheus.Metric) {
+	for _, s := range c.monitor.Statuses() {
+		labels := []string{s.Remote.String(), s.Fingerprint.String()}
+		up := float64(0)
+		if s.Up() {
+			up = 1
+		}
+		if s.Received > 0 {
+			mc <- prometheus.MustNewConstMetric(rttDesc, prometheus.GaugeValue,
+				s.RTT.Seconds(), labels...)
+		}
+		if s.Sent > 0 {
+			mc <- prometheus.MustNewConstMetric(lossDesc, prometheus.GaugeValue,
+				s.Loss, labels...)
+		}
+		mc <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, up, labels...)
+		mc <- prometheus.MustNewConstMetric(sentDesc, prometheus.CounterValue,
+			float64(s.Sent), labels...)
+		mc <- prometheus.MustNewConstMetric(receivedDesc, prometheus.CounterValue,
+			float64(s.Received), labels...)
+	}
+}
+
+// Describe is called by prometheus to get the descriptions.
+func (c *Collector) Describe(dc chan<- *prometheus.Desc) {
+	dc <- rttDesc
+	dc <- lossDesc
+	dc <- upDesc
+	dc <- sentDesc
+	dc <- receivedDesc
+}
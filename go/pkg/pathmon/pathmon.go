@@ -0,0 +1,372 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pathmon continuously monitors the paths to a set of remote hosts.
+//
+// The paths to every remote are discovered with showpaths and every path is
+// pinged once per interval. The monitor keeps the round trip time, the loss and
+// the up/down state of every path. The state can be exported to prometheus
+// with the Collector.
+package pathmon
+
+import (
+	"context"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/pkg/ping"
+	"github.com/scionproto/scion/go/pkg/showpaths"
+)
+
+const (
+	// DownThreshold is the number of consecutive lost pings after which a
+	// path is considered down.
+	DownThreshold = 3
+	// LossWindow is the number of most recent pings over which the loss of a
+	// path is computed.
+	LossWindow = 10
+	// DefaultPathRefresh is the default interval at which the paths to the
+	// remotes are refetched.
+	DefaultPathRefresh = time.Minute
+)
+
+// Config configures the monitor.
+type Config struct {
+	Dispatcher reliable.Dispatcher
+	// Remotes are the hosts whose paths are monitored.
+	Remotes []*snet.UDPAddr
+	// Paths configures which paths are monitored. The SCION Daemon address,
+	// the sequence, the path policy, the maximum number of paths and the
+	// local IP address are used. If the local IP address is not set, it is
+	// resolved for every path.
+	Paths showpaths.Config
+	// Interval is the time between two pings over a path.
+	Interval time.Duration
+	// Timeout is the time after which a ping is considered lost.
+	Timeout time.Duration
+	// PathRefresh is the interval at which the paths are refetched. If it is
+	// zero, DefaultPathRefresh is used.
+	PathRefresh time.Duration
+	// UpdateHandler is invoked with the state of all paths after every round
+	// of pings. Execution time must be small, as it is run synchronously.
+	UpdateHandler func([]PathStatus)
+	// ErrHandler is invoked for every error that does not cause monitoring to
+	// abort. Execution time must be small, as it is run synchronously.
+	ErrHandler func(error)
+
+	HeaderV2 bool
+}
+
+// PathStatus is the state of a monitored path.
+type PathStatus struct {
+	// Remote is the host that is pinged over the path.
+	Remote *snet.UDPAddr
+	// Path is the monitored path.
+	Path snet.Path
+	// Fingerprint is the fingerprint of the path.
+	Fingerprint snet.PathFingerprint
+	// RTT is the round trip time of the last reply. It is zero if no reply
+	// was received yet.
+	RTT time.Duration
+	// Loss is the ratio of lost pings among the last LossWindow pings,
+	// between 0 and 1.
+	Loss float64
+	// Sent is the number of pings sent over the path.
+	Sent int
+	// Received is the number of replies received over the path.
+	Received int
+	// ConsecutiveLost is the number of pings lost since the last reply.
+	ConsecutiveLost int
+	// LastReply is the time at which the last reply was received.
+	LastReply time.Time
+
+	// recent contains whether the most recent pings were lost, oldest first.
+	recent []bool
+}
+
+// Up returns whether the path is up, i.e., a reply was received for at least
+// one of the last DownThreshold pings.
+func (s PathStatus) Up() bool {
+	return s.Received > 0 && s.ConsecutiveLost < DownThreshold
+}
+
+func (s *PathStatus) update(rtt time.Duration, lost bool, now time.Time) {
+	s.Sent++
+	if lost {
+		s.ConsecutiveLost++
+	} else {
+		s.Received++
+		s.ConsecutiveLost = 0
+		s.RTT = rtt
+		s.LastReply = now
+	}
+	s.recent = append(s.recent, lost)
+	if len(s.recent) > LossWindow {
+		s.recent = s.recent[1:]
+	}
+	var lostCount int
+	for _, l := range s.recent {
+		if l {
+			lostCount++
+		}
+	}
+	s.Loss = float64(lostCount) / float64(len(s.recent))
+}
+
+// Monitor monitors the paths to the remotes. It is safe for concurrent use.
+type Monitor struct {
+	cfg Config
+	// paths returns the paths to the destination, using the SCION Daemon
+	// connection.
+	paths func(ctx context.Context, sdConn sciond.Connector, dst addr.IA) ([]snet.Path, error)
+	// ping pings the remote over the path set in the remote and reports the
+	// round trip time of the reply.
+	ping func(ctx context.Context, local, remote *snet.UDPAddr) (time.Duration, bool, error)
+
+	mtx      sync.Mutex
+	statuses map[statusKey]*PathStatus
+}
+
+type statusKey struct {
+	remote      string
+	fingerprint snet.PathFingerprint
+}
+
+// New creates a monitor with the configuration.
+func New(cfg Config) *Monitor {
+	m := &Monitor{
+		cfg:      cfg,
+		statuses: make(map[statusKey]*PathStatus),
+	}
+	m.paths = m.fetchPaths
+	m.ping = m.pingOnce
+	return m
+}
+
+// Run monitors the paths until the context is canceled.
+func (m *Monitor) Run(ctx context.Context) error {
+	if m.cfg.Interval <= 0 {
+		return serrors.New("interval must be positive")
+	}
+	if len(m.cfg.Remotes) == 0 {
+		return serrors.New("no remotes")
+	}
+	sdConn, err := sciond.NewService(m.cfg.Paths.SCIOND).Connect(ctx)
+	if err != nil {
+		return serrors.WrapStr("connecting to SCION Daemon", err)
+	}
+	defer sdConn.Close(ctx)
+	localIA, err := sdConn.LocalIA(ctx)
+	if err != nil {
+		return serrors.WrapStr("determining local ISD-AS", err)
+	}
+	refresh := m.cfg.PathRefresh
+	if refresh == 0 {
+		refresh = DefaultPathRefresh
+	}
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	var lastRefresh time.Time
+	for {
+		if time.Since(lastRefresh) >= refresh {
+			m.refreshPaths(ctx, sdConn)
+			lastRefresh = time.Now()
+		}
+		m.pingAll(ctx, localIA)
+		if m.cfg.UpdateHandler != nil {
+			m.cfg.UpdateHandler(m.Statuses())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Statuses returns the state of all monitored paths, ordered by remote and
+// path.
+func (m *Monitor) Statuses() []PathStatus {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	statuses := make([]PathStatus, 0, len(m.statuses))
+	for _, s := range m.statuses {
+		c := *s
+		c.recent = nil
+		statuses = append(statuses, c)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if ra, rb := a.Remote.String(), b.Remote.String(); ra != rb {
+			return ra < rb
+		}
+		if la, lb := len(a.Path.Interfaces()), len(b.Path.Interfaces()); la != lb {
+			return la < lb
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return statuses
+}
+
+// refreshPaths updates the monitored paths. The state of paths that are still
+// available is kept. If the paths to a remote can not be fetched, the
+// previous paths are kept.
+func (m *Monitor) refreshPaths(ctx context.Context, sdConn sciond.Connector) {
+	for _, remote := range m.cfg.Remotes {
+		paths, err := m.paths(ctx, sdConn, remote.IA)
+		if err != nil {
+			m.handleErr(serrors.WrapStr("fetching paths", err, "remote", remote))
+			continue
+		}
+		m.setPaths(remote, paths)
+	}
+}
+
+func (m *Monitor) setPaths(remote *snet.UDPAddr, paths []snet.Path) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	current := make(map[statusKey]bool, len(paths))
+	for _, path := range paths {
+		key := statusKey{remote: remote.String(), fingerprint: snet.Fingerprint(path)}
+		current[key] = true
+		if s, ok := m.statuses[key]; ok {
+			// Update the path, e.g., its expiration time.
+			s.Path = path
+			continue
+		}
+		m.statuses[key] = &PathStatus{
+			Remote:      remote,
+			Path:        path,
+			Fingerprint: key.fingerprint,
+		}
+	}
+	for key := range m.statuses {
+		if key.remote == remote.String() && !current[key] {
+			delete(m.statuses, key)
+		}
+	}
+}
+
+// pingAll pings every path once, concurrently.
+func (m *Monitor) pingAll(ctx context.Context, localIA addr.IA) {
+	m.mtx.Lock()
+	keys := make([]statusKey, 0, len(m.statuses))
+	targets := make([]*snet.UDPAddr, 0, len(m.statuses))
+	for key, s := range m.statuses {
+		remote := s.Remote.Copy()
+		remote.Path = s.Path.Path()
+		remote.NextHop = s.Path.UnderlayNextHop()
+		keys = append(keys, key)
+		targets = append(targets, remote)
+	}
+	m.mtx.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer log.HandlePanic()
+			defer wg.Done()
+			local, err := m.localAddr(localIA, targets[i])
+			if err != nil {
+				m.handleErr(err)
+				return
+			}
+			rtt, ok, err := m.ping(ctx, local, targets[i])
+			if err != nil {
+				if ctx.Err() == nil {
+					m.handleErr(serrors.WrapStr("pinging", err, "remote", targets[i]))
+				}
+				return
+			}
+			m.mtx.Lock()
+			defer m.mtx.Unlock()
+			// The path might have been removed in the meantime.
+			if s, found := m.statuses[keys[i]]; found {
+				s.update(rtt, !ok, time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *Monitor) localAddr(localIA addr.IA, remote *snet.UDPAddr) (*snet.UDPAddr, error) {
+	localIP := m.cfg.Paths.Local
+	if localIP == nil {
+		target := remote.Host.IP
+		if remote.NextHop != nil {
+			target = remote.NextHop.IP
+		}
+		var err error
+		if localIP, err = addrutil.ResolveLocal(target); err != nil {
+			return nil, serrors.WrapStr("resolving local address", err)
+		}
+	}
+	return &snet.UDPAddr{IA: localIA, Host: &net.UDPAddr{IP: localIP}}, nil
+}
+
+func (m *Monitor) fetchPaths(ctx context.Context, sdConn sciond.Connector,
+	dst addr.IA) ([]snet.Path, error) {
+
+	cfg := m.cfg.Paths
+	if cfg.MaxPaths == 0 {
+		cfg.MaxPaths = math.MaxInt32
+	}
+	ctx, cancelF := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelF()
+	return showpaths.Fetch(ctx, sdConn, dst, cfg)
+}
+
+// pingOnce sends a single ping to the remote and waits for the reply until
+// the timeout.
+func (m *Monitor) pingOnce(ctx context.Context, local,
+	remote *snet.UDPAddr) (time.Duration, bool, error) {
+
+	var rtt time.Duration
+	var received bool
+	_, err := ping.Run(ctx, ping.Config{
+		Dispatcher: m.cfg.Dispatcher,
+		Local:      local,
+		Remote:     remote,
+		Attempts:   1,
+		Interval:   time.Millisecond,
+		Timeout:    m.cfg.Timeout,
+		ErrHandler: m.handleErr,
+		UpdateHandler: func(update ping.Update) {
+			if update.State != ping.AfterTimeout && !received {
+				rtt, received = update.RTT, true
+			}
+		},
+		HeaderV2: m.cfg.HeaderV2,
+	})
+	return rtt, received, err
+}
+
+func (m *Monitor) handleErr(err error) {
+	if m.cfg.ErrHandler != nil {
+		m.cfg.ErrHandler(err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathmon
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/sciond/mock_sciond"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/showpaths"
+)
+
+func TestPathStatusUpdate(t *testing.T) {
+	var s PathStatus
+	assert.False(t, s.Up())
+
+	now := time.Now()
+	s.update(10*time.Millisecond, false, now)
+	assert.True(t, s.Up())
+	assert.Equal(t, 10*time.Millisecond, s.RTT)
+	assert.Equal(t, now, s.LastReply)
+	assert.Equal(t, 0.0, s.Loss)
+
+	for i := 0; i < DownThreshold-1; i++ {
+		s.update(0, true, now)
+		assert.True(t, s.Up())
+	}
+	s.update(0, true, now)
+	assert.False(t, s.Up())
+	assert.Equal(t, 10*time.Millisecond, s.RTT)
+	assert.Equal(t, 0.75, s.Loss)
+
+	// Only the most recent pings count towards the loss.
+	for i := 0; i < LossWindow; i++ {
+		s.update(20*time.Millisecond, false, now)
+	}
+	assert.True(t, s.Up())
+	assert.Equal(t, 0.0, s.Loss)
+	assert.Equal(t, DownThreshold+1+LossWindow, s.Sent)
+	assert.Equal(t, 1+LossWindow, s.Received)
+}
+
+func TestMonitor(t *testing.T) {
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	remote := &snet.UDPAddr{IA: ia111, Host: &net.UDPAddr{IP: net.IP{10, 0, 0, 1}}}
+	paths := testPaths(t, ia110, ia111, 1, 2)
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	sdConn := mock_sciond.NewMockConnector(mctrl)
+
+	var mtx sync.Mutex
+	var fetched [][]snet.Path
+	replies := map[string]bool{}
+	m := New(Config{
+		Remotes: []*snet.UDPAddr{remote},
+		Paths:   showpaths.Config{Local: net.IP{127, 0, 0, 1}},
+		Timeout: time.Second,
+	})
+	m.paths = func(_ context.Context, conn sciond.Connector, dst addr.IA) ([]snet.Path, error) {
+		// The connection of the monitor is reused for every refresh.
+		assert.Equal(t, sdConn, conn)
+		assert.Equal(t, ia111, dst)
+		result := fetched[0]
+		fetched = fetched[1:]
+		return result, nil
+	}
+	m.ping = func(_ context.Context, local,
+		remote *snet.UDPAddr) (time.Duration, bool, error) {
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		assert.Equal(t, ia110, local.IA)
+		return 5 * time.Millisecond, replies[string(remote.Path.Raw)], nil
+	}
+
+	fetched = [][]snet.Path{paths, paths[1:]}
+	replies[string(paths[0].Path().Raw)] = true
+	m.refreshPaths(context.Background(), sdConn)
+	m.pingAll(context.Background(), ia110)
+
+	statuses := m.Statuses()
+	require.Len(t, statuses, 2)
+	byFingerprint := map[snet.PathFingerprint]PathStatus{}
+	for _, s := range statuses {
+		assert.Equal(t, 1, s.Sent)
+		byFingerprint[s.Fingerprint] = s
+	}
+	up := byFingerprint[snet.Fingerprint(paths[0])]
+	assert.Equal(t, 1, up.Received)
+	assert.Equal(t, 5*time.Millisecond, up.RTT)
+	down := byFingerprint[snet.Fingerprint(paths[1])]
+	assert.Equal(t, 0, down.Received)
+	assert.Equal(t, 1.0, down.Loss)
+
+	// 2 paths with rtt, loss, up, sent and received, except for the rtt of the
+	// path without replies.
+	assert.Equal(t, 9, testutil.CollectAndCount(NewCollector(m)))
+
+	// Paths that disappear are not monitored anymore, the state of the
+	// remaining paths is kept.
+	m.refreshPaths(context.Background(), sdConn)
+	m.pingAll(context.Background(), ia110)
+	statuses = m.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, snet.Fingerprint(paths[1]), statuses[0].Fingerprint)
+	assert.Equal(t, 2, statuses[0].Sent)
+}
+
+func testPaths(t *testing.T, src, dst addr.IA, ifIDs ...uint64) []snet.Path {
+	var entries []sciond.PathReplyEntry
+	for _, ifID := range ifIDs {
+		entries = append(entries, sciond.PathReplyEntry{
+			Path: &sciond.FwdPathMeta{
+				FwdPath: []byte{byte(ifID)},
+				Interfaces: []sciond.PathInterface{
+					{RawIsdas: src.IAInt(), IfID: common.IFIDType(ifID)},
+					{RawIsdas: dst.IAInt(), IfID: common.IFIDType(ifID)},
+				},
+				HeaderV2: true,
+			},
+			HostInfo: hostinfo.FromUDPAddr(net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}),
+		})
+	}
+	paths, err := sciond.PathReplyToPaths(
+		&sciond.PathReply{ErrorCode: sciond.ErrorOk, Entries: entries}, dst)
+	require.NoError(t, err)
+	return paths
+}
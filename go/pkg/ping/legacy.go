@@ -62,7 +62,7 @@ func (p *legacyPinger) Ping(ctx context.Context, remote *snet.UDPAddr) (Stats, e
 		p.drain(ctx)
 	}()
 
-	for i := uint16(0); i < p.attempts; i++ {
+	for p.stats.Sent < int(p.attempts) {
 		select {
 		case <-ctx.Done():
 			return p.stats, nil
@@ -71,19 +71,36 @@ func (p *legacyPinger) Ping(ctx context.Context, remote *snet.UDPAddr) (Stats, e
 				return p.stats, serrors.WrapStr("sending", err)
 			}
 		case reply := <-p.replies:
-			if reply.Error != nil {
-				if p.errHandler != nil {
-					p.errHandler(reply.Error)
-				}
-				continue
-			}
-			p.legacyReceive(reply)
+			p.handleReply(reply)
+		}
+	}
+	// Wait for the replies to the last requests.
+	timeout := time.NewTimer(p.timeout)
+	defer timeout.Stop()
+	for p.stats.Received < p.stats.Sent {
+		select {
+		case <-ctx.Done():
+			return p.stats, nil
+		case <-timeout.C:
+			return p.stats, nil
+		case reply := <-p.replies:
+			p.handleReply(reply)
 		}
 	}
 	return p.stats, nil
 
 }
 
+func (p *legacyPinger) handleReply(reply legacyReply) {
+	if reply.Error != nil {
+		if p.errHandler != nil {
+			p.errHandler(reply.Error)
+		}
+		return
+	}
+	p.legacyReceive(reply)
+}
+
 func (p *legacyPinger) send(remote *snet.UDPAddr) error {
 	sequence := p.sentSequence + 1
 
@@ -125,6 +142,7 @@ func (p *legacyPinger) legacyReceive(reply legacyReply) {
 		p.receivedSequence = int(reply.Info.Seq)
 	}
 	p.stats.Received++
+	p.stats.RTTs = append(p.stats.RTTs, rtt)
 	if p.updateHandler != nil {
 		p.updateHandler(Update{
 			RTT:      rtt,
@@ -145,7 +163,9 @@ func (p *legacyPinger) drain(ctx context.Context) {
 		default:
 			var pkt snet.Packet
 			var ov net.UDPAddr
-			if err := p.conn.ReadFrom(&pkt, &ov); err != nil && p.errHandler != nil {
+			err := p.conn.ReadFrom(&pkt, &ov)
+			// Reading fails once the connection is closed after pinging.
+			if err != nil && ctx.Err() == nil && p.errHandler != nil {
 				// Rate limit the error reports.
 				if now := time.Now(); now.Sub(last) > 500*time.Millisecond {
 					p.errHandler(serrors.WrapStr("reading packet", err))
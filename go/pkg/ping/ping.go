@@ -18,8 +18,10 @@ package ping
 import (
 	"context"
 	"encoding/binary"
+	"math"
 	"math/rand"
 	"net"
+	"sort"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
@@ -35,6 +37,50 @@ import (
 type Stats struct {
 	Sent     int
 	Received int
+	// RTTs are the round trip times of the received replies, in the order in
+	// which the replies were received.
+	RTTs []time.Duration
+}
+
+// Loss returns the ratio of echo requests for which no reply was received,
+// between 0 and 1.
+func (s Stats) Loss() float64 {
+	if s.Sent == 0 || s.Received >= s.Sent {
+		return 0
+	}
+	return 1 - float64(s.Received)/float64(s.Sent)
+}
+
+// Average returns the average round trip time. It is zero if no reply was
+// received.
+func (s Stats) Average() time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, rtt := range s.RTTs {
+		sum += rtt
+	}
+	return sum / time.Duration(len(s.RTTs))
+}
+
+// Percentile returns the p-th percentile of the round trip times, with p
+// between 0 and 100. It uses the nearest-rank method, i.e., the result is one
+// of the measured round trip times. It is zero if no reply was received.
+func (s Stats) Percentile(p float64) time.Duration {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), s.RTTs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
 }
 
 // Update contains intermediary information about a received echo reply
@@ -82,7 +128,8 @@ type Config struct {
 }
 
 // Run ping with the configuration. This blocks until the configured number
-// attempts is sent, or the context is canceled.
+// attempts is sent and the replies are received or timed out, or the context
+// is canceled.
 func Run(ctx context.Context, cfg Config) (Stats, error) {
 	if cfg.Interval < time.Millisecond {
 		return Stats{}, serrors.New("interval below millisecond")
@@ -111,6 +158,7 @@ func Run(ctx context.Context, cfg Config) (Stats, error) {
 	if err != nil {
 		return Stats{}, err
 	}
+	defer conn.Close()
 
 	local := cfg.Local.Copy()
 	local.Host.Port = int(port)
@@ -187,7 +235,7 @@ func (p *pinger) Ping(ctx context.Context, remote *snet.UDPAddr) (Stats, error)
 		p.drain(ctx)
 	}()
 
-	for i := uint16(0); i < p.attempts; i++ {
+	for p.stats.Sent < int(p.attempts) {
 		select {
 		case <-ctx.Done():
 			return p.stats, nil
@@ -196,19 +244,36 @@ func (p *pinger) Ping(ctx context.Context, remote *snet.UDPAddr) (Stats, error)
 				return p.stats, serrors.WrapStr("sending", err)
 			}
 		case reply := <-p.replies:
-			if reply.Error != nil {
-				if p.errHandler != nil {
-					p.errHandler(reply.Error)
-				}
-				continue
-			}
-			p.receive(reply)
+			p.handleReply(reply)
+		}
+	}
+	// Wait for the replies to the last requests.
+	timeout := time.NewTimer(p.timeout)
+	defer timeout.Stop()
+	for p.stats.Received < p.stats.Sent {
+		select {
+		case <-ctx.Done():
+			return p.stats, nil
+		case <-timeout.C:
+			return p.stats, nil
+		case reply := <-p.replies:
+			p.handleReply(reply)
 		}
 	}
 	return p.stats, nil
 
 }
 
+func (p *pinger) handleReply(reply reply) {
+	if reply.Error != nil {
+		if p.errHandler != nil {
+			p.errHandler(reply.Error)
+		}
+		return
+	}
+	p.receive(reply)
+}
+
 func (p *pinger) send(remote *snet.UDPAddr) error {
 	sequence := p.sentSequence + 1
 
@@ -255,6 +320,7 @@ func (p *pinger) receive(reply reply) {
 		p.receivedSequence = int(reply.Reply.SeqNumber)
 	}
 	p.stats.Received++
+	p.stats.RTTs = append(p.stats.RTTs, rtt)
 	if p.updateHandler != nil {
 		p.updateHandler(Update{
 			RTT:      rtt,
@@ -281,6 +347,7 @@ func (p *pinger) legacyReceive(reply legacyReply) {
 		p.receivedSequence = int(reply.Info.Seq)
 	}
 	p.stats.Received++
+	p.stats.RTTs = append(p.stats.RTTs, rtt)
 	if p.updateHandler != nil {
 		p.updateHandler(Update{
 			RTT:      rtt,
@@ -301,7 +368,9 @@ func (p *pinger) drain(ctx context.Context) {
 		default:
 			var pkt snet.Packet
 			var ov net.UDPAddr
-			if err := p.conn.ReadFrom(&pkt, &ov); err != nil && p.errHandler != nil {
+			err := p.conn.ReadFrom(&pkt, &ov)
+			// Reading fails once the connection is closed after pinging.
+			if err != nil && ctx.Err() == nil && p.errHandler != nil {
 				// Rate limit the error reports.
 				if now := time.Now(); now.Sub(last) > 500*time.Millisecond {
 					p.errHandler(serrors.WrapStr("reading packet", err))
@@ -0,0 +1,49 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/pkg/ping"
+)
+
+func TestStats(t *testing.T) {
+	ms := time.Millisecond
+	stats := ping.Stats{
+		Sent:     10,
+		Received: 8,
+		RTTs:     []time.Duration{5 * ms, 1 * ms, 8 * ms, 2 * ms, 7 * ms, 3 * ms, 6 * ms, 4 * ms},
+	}
+	assert.InDelta(t, 0.2, stats.Loss(), 1e-9)
+	assert.Equal(t, 4500*time.Microsecond, stats.Average())
+	assert.Equal(t, 1*ms, stats.Percentile(0))
+	assert.Equal(t, 4*ms, stats.Percentile(50))
+	assert.Equal(t, 8*ms, stats.Percentile(90))
+	assert.Equal(t, 8*ms, stats.Percentile(100))
+	// The RTTs are not reordered.
+	assert.Equal(t, 5*ms, stats.RTTs[0])
+
+	var empty ping.Stats
+	assert.Equal(t, 0.0, empty.Loss())
+	assert.Equal(t, time.Duration(0), empty.Average())
+	assert.Equal(t, time.Duration(0), empty.Percentile(50))
+
+	duplicates := ping.Stats{Sent: 1, Received: 2}
+	assert.Equal(t, 0.0, duplicates.Loss())
+}
@@ -129,6 +129,13 @@ type Session struct {
 	// messages is the channel on which the session receives BFD packets.
 	messages chan *layers.BFD
 
+	// closeLock protects the initialization and closing of the closed channel.
+	closeLock sync.Mutex
+	// closed is closed by Close to stop a running session.
+	closed chan struct{}
+	// closeMarker is set to true the first time a Session is closed.
+	closeMarker bool
+
 	// localStateLock protects access to the local state.
 	localStateLock sync.RWMutex
 	// localState is the state of the local BFD session.
@@ -178,6 +185,7 @@ func (s *Session) Run() error {
 		s.remoteDiscriminator = s.RemoteDiscriminator
 	}
 	s.initMessages()
+	closed := s.closedChan()
 
 	// detectionTimer tracks the period of time without receiving BFD packets after which the
 	// session is determined to have failed.
@@ -193,6 +201,8 @@ func (s *Session) Run() error {
 MainLoop:
 	for {
 		select {
+		case <-closed:
+			break MainLoop
 		case msg, ok := <-s.messages:
 			if !ok {
 				break MainLoop
@@ -357,6 +367,44 @@ func (s *Session) Messages() chan<- *layers.BFD {
 	return s.messages
 }
 
+// ReceiveMessage passes a BFD packet received from the network to the session.
+// It blocks until the session accepts the packet, unless the session is
+// closed, in which case the packet is ignored.
+func (s *Session) ReceiveMessage(msg *layers.BFD) {
+	s.initMessages()
+	select {
+	case s.messages <- msg:
+	case <-s.closedChan():
+	}
+}
+
+// Close stops the session. A running session stops sending BFD control packets
+// and Run returns. Messages passed to ReceiveMessage after the session was
+// closed are ignored. Close can be called multiple times and before Run, in
+// which case Run returns immediately.
+func (s *Session) Close() error {
+	s.closeLock.Lock()
+	defer s.closeLock.Unlock()
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	if !s.closeMarker {
+		s.closeMarker = true
+		close(s.closed)
+	}
+	return nil
+}
+
+// closedChan returns the channel that is closed once the session is closed.
+func (s *Session) closedChan() <-chan struct{} {
+	s.closeLock.Lock()
+	defer s.closeLock.Unlock()
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	return s.closed
+}
+
 // initMessages creates and sets the message receive queue if it is not
 // already created.
 func (s *Session) initMessages() {
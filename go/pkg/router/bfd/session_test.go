@@ -565,6 +565,76 @@ func TestSessionRunInit(t *testing.T) {
 	}
 }
 
+func TestSessionClose(t *testing.T) {
+	t.Run("close running session", func(t *testing.T) {
+		session := &bfd.Session{
+			DetectMult:            1,
+			DesiredMinTxInterval:  time.Microsecond,
+			RequiredMinRxInterval: time.Microsecond,
+			LocalDiscriminator:    1,
+			RemoteDiscriminator:   2,
+			Sender:                &redirectSender{},
+			Logger:                log.New(),
+		}
+
+		barrier := make(chan struct{})
+
+		go func() {
+			err := session.Run()
+			assert.NoError(t, err)
+			close(barrier)
+		}()
+
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, session.Close())
+		require.NoError(t, session.Close())
+
+		select {
+		case <-barrier:
+		case <-time.After(200 * time.Millisecond):
+			t.Fatalf("Run did not finish in time")
+		}
+	})
+	t.Run("close before run", func(t *testing.T) {
+		session := &bfd.Session{
+			DetectMult:            1,
+			DesiredMinTxInterval:  time.Microsecond,
+			RequiredMinRxInterval: time.Microsecond,
+			LocalDiscriminator:    1,
+			RemoteDiscriminator:   2,
+			Sender:                &redirectSender{},
+		}
+		require.NoError(t, session.Close())
+		assert.NoError(t, session.Run())
+	})
+	t.Run("receive after close does not block", func(t *testing.T) {
+		session := &bfd.Session{
+			DetectMult:            1,
+			DesiredMinTxInterval:  time.Microsecond,
+			RequiredMinRxInterval: time.Microsecond,
+			LocalDiscriminator:    1,
+			RemoteDiscriminator:   2,
+			Sender:                &redirectSender{},
+			ReceiveQueueSize:      2,
+		}
+		require.NoError(t, session.Close())
+
+		barrier := make(chan struct{})
+		go func() {
+			for i := 0; i < 2*session.ReceiveQueueSize+1; i++ {
+				session.ReceiveMessage(&layers.BFD{})
+			}
+			close(barrier)
+		}()
+
+		select {
+		case <-barrier:
+		case <-time.After(200 * time.Millisecond):
+			t.Fatalf("ReceiveMessage blocked after close")
+		}
+	})
+}
+
 func TestPrintPacket(t *testing.T) {
 	testCases := []*struct {
 		packet         *layers.BFD
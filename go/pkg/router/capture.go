@@ -0,0 +1,421 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers"
+)
+
+const (
+	// LinkTypeSCION is the link type of the captured packets. It is the first
+	// user defined link type (DLT_USER0), the packets start with the SCION
+	// common header and can be decoded with slayers.LayerTypeSCION.
+	LinkTypeSCION = layers.LinkType(147)
+
+	// DefaultCaptureSize is the default number of packets a capture keeps.
+	DefaultCaptureSize = 1000
+	// MaxCaptureSize is the maximum number of packets a capture keeps.
+	MaxCaptureSize = 10000
+)
+
+// CaptureFilter selects the packets that are captured. A filter is a
+// conjunction of terms that are separated by "and". Each term can be negated
+// with a "not" prefix. The following terms are supported:
+//
+//	src <isd-as>   the source ISD-AS of the packet
+//	dst <isd-as>   the destination ISD-AS of the packet
+//	ia <isd-as>    the source or the destination ISD-AS of the packet
+//	path <type>    the path type of the packet, one of empty, scion or onehop
+//
+// For example "ia 1-ff00:0:110 and not path onehop". The empty filter selects
+// all packets.
+type CaptureFilter struct {
+	expr  string
+	terms []captureTerm
+}
+
+type captureTerm struct {
+	negate bool
+	match  func(s *slayers.SCION) bool
+}
+
+// ParseCaptureFilter parses the filter expression.
+func ParseCaptureFilter(expr string) (CaptureFilter, error) {
+	f := CaptureFilter{expr: strings.Join(strings.Fields(expr), " ")}
+	tokens := strings.Fields(expr)
+	for len(tokens) > 0 {
+		var t captureTerm
+		if tokens[0] == "not" {
+			t.negate = true
+			tokens = tokens[1:]
+		}
+		if len(tokens) < 2 {
+			return CaptureFilter{}, serrors.New("incomplete filter term", "filter", expr)
+		}
+		match, err := parseCaptureTerm(tokens[0], tokens[1])
+		if err != nil {
+			return CaptureFilter{}, serrors.WrapStr("parsing filter term", err, "filter", expr)
+		}
+		t.match = match
+		f.terms = append(f.terms, t)
+		tokens = tokens[2:]
+		if len(tokens) > 0 {
+			if tokens[0] != "and" || len(tokens) == 1 {
+				return CaptureFilter{}, serrors.New("terms must be joined with and",
+					"filter", expr)
+			}
+			tokens = tokens[1:]
+		}
+	}
+	return f, nil
+}
+
+func parseCaptureTerm(key, value string) (func(s *slayers.SCION) bool, error) {
+	switch key {
+	case "src", "dst", "ia":
+		ia, err := addr.IAFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "src":
+			return func(s *slayers.SCION) bool { return s.SrcIA.Equal(ia) }, nil
+		case "dst":
+			return func(s *slayers.SCION) bool { return s.DstIA.Equal(ia) }, nil
+		default:
+			return func(s *slayers.SCION) bool {
+				return s.SrcIA.Equal(ia) || s.DstIA.Equal(ia)
+			}, nil
+		}
+	case "path":
+		var pathType slayers.PathType
+		switch value {
+		case "empty":
+			pathType = slayers.PathTypeEmpty
+		case "scion":
+			pathType = slayers.PathTypeSCION
+		case "onehop":
+			pathType = slayers.PathTypeOneHop
+		default:
+			return nil, serrors.New("unknown path type", "type", value)
+		}
+		return func(s *slayers.SCION) bool { return s.PathType == pathType }, nil
+	default:
+		return nil, serrors.New("unknown filter term", "term", key)
+	}
+}
+
+// Match indicates whether the filter selects the packet.
+func (f CaptureFilter) Match(s *slayers.SCION) bool {
+	for _, t := range f.terms {
+		if t.match(s) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (f CaptureFilter) String() string {
+	return f.expr
+}
+
+// capturedPacket is a packet that was captured on an interface.
+type capturedPacket struct {
+	timestamp time.Time
+	outbound  bool
+	data      []byte
+}
+
+// capture is a ring buffer that keeps the last packets that were received or
+// sent on an interface and that are selected by the filter. It is safe for
+// concurrent use.
+type capture struct {
+	filter CaptureFilter
+
+	mtx     sync.Mutex
+	packets []capturedPacket
+	// next is the index in packets that is written next.
+	next int
+	// total is the number of packets captured so far, including the ones
+	// that were overwritten.
+	total int
+	// scionLayer is used to decode the packets for filtering.
+	scionLayer slayers.SCION
+}
+
+func newCapture(filter CaptureFilter, size int) *capture {
+	return &capture{
+		filter:  filter,
+		packets: make([]capturedPacket, 0, size),
+	}
+}
+
+// add adds a copy of the packet to the capture if it is selected by the
+// filter.
+func (c *capture) add(data []byte, outbound bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.filter.terms) > 0 {
+		err := c.scionLayer.DecodeFromBytes(data, gopacket.NilDecodeFeedback)
+		if err != nil || !c.filter.Match(&c.scionLayer) {
+			return
+		}
+	}
+	p := capturedPacket{
+		timestamp: time.Now(),
+		outbound:  outbound,
+		data:      append([]byte(nil), data...),
+	}
+	if len(c.packets) < cap(c.packets) {
+		c.packets = append(c.packets, p)
+	} else {
+		c.packets[c.next] = p
+	}
+	c.next = (c.next + 1) % cap(c.packets)
+	c.total++
+}
+
+// snapshot returns the captured packets, oldest first.
+func (c *capture) snapshot() []capturedPacket {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.packets) < cap(c.packets) {
+		return append([]capturedPacket(nil), c.packets...)
+	}
+	return append(append([]capturedPacket(nil), c.packets[c.next:]...), c.packets[:c.next]...)
+}
+
+// CaptureInfo describes a running capture.
+type CaptureInfo struct {
+	// Filter is the filter of the capture.
+	Filter CaptureFilter
+	// Size is the maximum number of packets the capture keeps.
+	Size int
+	// Buffered is the number of packets the capture currently holds.
+	Buffered int
+	// Total is the number of packets captured since the capture started.
+	Total int
+}
+
+func (c *capture) info() CaptureInfo {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return CaptureInfo{
+		Filter:   c.filter,
+		Size:     cap(c.packets),
+		Buffered: len(c.packets),
+		Total:    c.total,
+	}
+}
+
+// captures returns the running captures by interface ID. The returned map must
+// not be modified.
+func (d *DataPlane) captures() map[uint16]*capture {
+	caps, _ := d.captureState.Load().(map[uint16]*capture)
+	return caps
+}
+
+// StartCapture starts capturing the packets that are received and sent on the
+// given interface and that are selected by the filter. The internal interface
+// has ID 0. The capture keeps the last size packets, a running capture on the
+// interface is replaced.
+func (d *DataPlane) StartCapture(ifID uint16, filter CaptureFilter, size int) error {
+	if size <= 0 || size > MaxCaptureSize {
+		return serrors.New("invalid capture size", "size", size, "max", MaxCaptureSize)
+	}
+	if _, ok := d.forwarding().external[ifID]; ifID != 0 && !ok {
+		return serrors.WithCtx(notFound, "if_id", ifID)
+	}
+	d.updateCaptures(func(caps map[uint16]*capture) {
+		caps[ifID] = newCapture(filter, size)
+	})
+	return nil
+}
+
+// StopCapture stops the capture on the given interface and discards the
+// captured packets.
+func (d *DataPlane) StopCapture(ifID uint16) error {
+	if _, ok := d.captures()[ifID]; !ok {
+		return serrors.WithCtx(notFound, "if_id", ifID)
+	}
+	d.updateCaptures(func(caps map[uint16]*capture) {
+		delete(caps, ifID)
+	})
+	return nil
+}
+
+// Captures returns the information of the running captures by interface ID.
+func (d *DataPlane) Captures() map[uint16]CaptureInfo {
+	caps := d.captures()
+	infos := make(map[uint16]CaptureInfo, len(caps))
+	for ifID, c := range caps {
+		infos[ifID] = c.info()
+	}
+	return infos
+}
+
+// WriteCapture writes the packets captured on the given interface to w in the
+// pcapng format. Received and sent packets are recorded on two separate pcapng
+// interfaces, suffixed with "in" and "out".
+func (d *DataPlane) WriteCapture(ifID uint16, w io.Writer) error {
+	c, ok := d.captures()[ifID]
+	if !ok {
+		return serrors.WithCtx(notFound, "if_id", ifID)
+	}
+	name := interfaceToMetricLabel(ifID)
+	intf := pcapgo.NgInterface{
+		Name:     name + "-in",
+		Filter:   c.filter.String(),
+		LinkType: LinkTypeSCION,
+	}
+	nw, err := pcapgo.NewNgWriterInterface(w, intf, pcapgo.DefaultNgWriterOptions)
+	if err != nil {
+		return err
+	}
+	intf.Name = name + "-out"
+	out, err := nw.AddInterface(intf)
+	if err != nil {
+		return err
+	}
+	for _, p := range c.snapshot() {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     p.timestamp,
+			CaptureLength: len(p.data),
+			Length:        len(p.data),
+		}
+		if p.outbound {
+			ci.InterfaceIndex = out
+		}
+		if err := nw.WritePacket(ci, p.data); err != nil {
+			return err
+		}
+	}
+	return nw.Flush()
+}
+
+func (d *DataPlane) updateCaptures(fn func(caps map[uint16]*capture)) {
+	d.captureMtx.Lock()
+	defer d.captureMtx.Unlock()
+	old := d.captures()
+	caps := make(map[uint16]*capture, len(old)+1)
+	for k, v := range old {
+		caps[k] = v
+	}
+	fn(caps)
+	d.captureState.Store(caps)
+}
+
+// NewCaptureHandler returns an HTTP handler to control the packet captures of
+// the dataplane. The interface is selected with the interface query parameter,
+// which is an interface ID or "internal".
+//
+//	GET                       lists the running captures.
+//	GET ?interface=           downloads the captured packets in the pcapng format.
+//	POST ?interface=          starts a capture, the optional filter and size
+//	                          parameters set the filter and the number of packets
+//	                          that are kept.
+//	DELETE ?interface=        stops a capture.
+func NewCaptureHandler(d *DataPlane) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method == http.MethodGet && query.Get("interface") == "" {
+			writeCaptureList(w, d.Captures())
+			return
+		}
+		ifID, err := parseCaptureInterface(query.Get("interface"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if _, ok := d.captures()[ifID]; !ok {
+				http.Error(w, "no capture running", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(
+				"attachment; filename=capture_%s.pcapng", interfaceToMetricLabel(ifID)))
+			if err := d.WriteCapture(ifID, w); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		case http.MethodPost:
+			filter, err := ParseCaptureFilter(query.Get("filter"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			size := DefaultCaptureSize
+			if s := query.Get("size"); s != "" {
+				if size, err = strconv.Atoi(s); err != nil {
+					http.Error(w, "invalid size", http.StatusBadRequest)
+					return
+				}
+			}
+			if err := d.StartCapture(ifID, filter, size); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			if err := d.StopCapture(ifID); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func parseCaptureInterface(s string) (uint16, error) {
+	if s == "internal" {
+		return 0, nil
+	}
+	ifID, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, serrors.New("invalid interface", "interface", s)
+	}
+	return uint16(ifID), nil
+}
+
+func writeCaptureList(w http.ResponseWriter, infos map[uint16]CaptureInfo) {
+	w.Header().Set("Content-Type", "text/plain")
+	ifIDs := make([]int, 0, len(infos))
+	for ifID := range infos {
+		ifIDs = append(ifIDs, int(ifID))
+	}
+	sort.Ints(ifIDs)
+	for _, ifID := range ifIDs {
+		info := infos[uint16(ifID)]
+		fmt.Fprintf(w, "interface %s: filter=%q packets=%d/%d total=%d\n",
+			interfaceToMetricLabel(uint16(ifID)), info.Filter, info.Buffered, info.Size,
+			info.Total)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	underlayconn "github.com/scionproto/scion/go/lib/underlay/conn"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/router"
+	"github.com/scionproto/scion/go/pkg/router/mock_router"
+)
+
+func TestParseCaptureFilter(t *testing.T) {
+	scn := &slayers.SCION{
+		SrcIA:    xtest.MustParseIA("1-ff00:0:110"),
+		DstIA:    xtest.MustParseIA("1-ff00:0:111"),
+		PathType: slayers.PathTypeSCION,
+	}
+	testCases := map[string]struct {
+		Filter    string
+		Match     bool
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"empty": {
+			Filter:    "",
+			Match:     true,
+			Assertion: assert.NoError,
+		},
+		"src": {
+			Filter:    "src 1-ff00:0:110",
+			Match:     true,
+			Assertion: assert.NoError,
+		},
+		"dst mismatch": {
+			Filter:    "dst 1-ff00:0:110",
+			Match:     false,
+			Assertion: assert.NoError,
+		},
+		"ia and path": {
+			Filter:    "ia 1-ff00:0:111 and path scion",
+			Match:     true,
+			Assertion: assert.NoError,
+		},
+		"negated path": {
+			Filter:    "ia 1-ff00:0:111 and not path scion",
+			Match:     false,
+			Assertion: assert.NoError,
+		},
+		"unknown term": {
+			Filter:    "port 30041",
+			Assertion: assert.Error,
+		},
+		"unknown path type": {
+			Filter:    "path epic",
+			Assertion: assert.Error,
+		},
+		"invalid ia": {
+			Filter:    "src 1-ff00",
+			Assertion: assert.Error,
+		},
+		"missing and": {
+			Filter:    "src 1-ff00:0:110 path scion",
+			Assertion: assert.Error,
+		},
+		"trailing and": {
+			Filter:    "src 1-ff00:0:110 and",
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			f, err := router.ParseCaptureFilter(tc.Filter)
+			tc.Assertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.Match, f.Match(scn))
+		})
+	}
+}
+
+func TestDataPlaneCapture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	done := make(chan struct{})
+
+	mInternal := mock_router.NewMockBatchConn(ctrl)
+	mInternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+	mInternal.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(
+		func(underlayconn.Messages) (int, error) {
+			close(done)
+			return 1, nil
+		})
+	mInternal.EXPECT().Close().Return(nil).AnyTimes()
+
+	spkt, dpath := prepBaseMsg()
+	spkt.DstIA = local
+	dpath.HopFields = []*path.HopField{
+		{ConsIngress: 41, ConsEgress: 40},
+		{ConsIngress: 31, ConsEgress: 30},
+		{ConsIngress: 1, ConsEgress: 0},
+	}
+	dpath.Base.PathMeta.CurrHF = 2
+	dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[2])
+	msg := toMsg(t, spkt, dpath)
+	// Keep a copy of the packet as it was received, processing updates it in place.
+	want := append([]byte(nil), msg.Buffers[0]...)
+
+	mExternal := mock_router.NewMockBatchConn(ctrl)
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(m underlayconn.Messages, _ []underlayconn.ReadMeta) (int, error) {
+			m[0].N = copy(m[0].Buffers[0], want)
+			return 1, nil
+		})
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+	mExternal.EXPECT().Close().Return(nil).AnyTimes()
+
+	dp := &router.DataPlane{}
+	require.NoError(t, dp.AddInternalInterface(mInternal, net.IP{}))
+	require.NoError(t, dp.AddExternalInterface(1, mExternal))
+	require.NoError(t, dp.SetIA(local))
+	require.NoError(t, dp.SetKey(0, key))
+
+	all, err := router.ParseCaptureFilter("")
+	require.NoError(t, err)
+	require.NoError(t, dp.StartCapture(1, all, 10))
+	toLocal, err := router.ParseCaptureFilter("dst 1-ff00:0:110 and not path onehop")
+	require.NoError(t, err)
+	require.NoError(t, dp.StartCapture(0, toLocal, 10))
+	assert.Error(t, dp.StartCapture(2, all, 10))
+	assert.Error(t, dp.StartCapture(1, all, router.MaxCaptureSize+1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- dp.Run(ctx)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("time out")
+	}
+	cancel()
+	require.NoError(t, <-errs)
+
+	infos := dp.Captures()
+	assert.Equal(t, 1, infos[1].Total)
+	assert.Equal(t, 1, infos[0].Total)
+
+	var buf bytes.Buffer
+	require.NoError(t, dp.WriteCapture(1, &buf))
+	r, err := pcapgo.NewNgReader(&buf, pcapgo.DefaultNgReaderOptions)
+	require.NoError(t, err)
+	data, ci, err := r.ReadPacketData()
+	require.NoError(t, err)
+	assert.Equal(t, 0, ci.InterfaceIndex)
+	assert.Equal(t, want, data)
+	pkt := gopacket.NewPacket(data, slayers.LayerTypeSCION, gopacket.Default)
+	require.NotNil(t, pkt.Layer(slayers.LayerTypeSCION))
+	assert.Equal(t, local, pkt.Layer(slayers.LayerTypeSCION).(*slayers.SCION).DstIA)
+	_, _, err = r.ReadPacketData()
+	assert.Equal(t, io.EOF, err)
+
+	// The packet is sent on the internal interface, the capture records it as
+	// outbound.
+	buf.Reset()
+	require.NoError(t, dp.WriteCapture(0, &buf))
+	r, err = pcapgo.NewNgReader(&buf, pcapgo.DefaultNgReaderOptions)
+	require.NoError(t, err)
+	_, ci, err = r.ReadPacketData()
+	require.NoError(t, err)
+	assert.Equal(t, 1, ci.InterfaceIndex)
+
+	require.NoError(t, dp.StopCapture(1))
+	assert.Error(t, dp.WriteCapture(1, &buf))
+	assert.Error(t, dp.StopCapture(1))
+}
+
+func TestCaptureHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dp := &router.DataPlane{}
+	require.NoError(t, dp.AddExternalInterface(1, mock_router.NewMockBatchConn(ctrl)))
+	handler := router.NewCaptureHandler(dp)
+	do := func(method, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(method, "/capture?"+query, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "interface=x").Code)
+	assert.Equal(t, http.StatusBadRequest,
+		do(http.MethodPost, "interface=1&filter=port+1").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "interface=1&size=0").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "interface=1").Code)
+
+	assert.Equal(t, http.StatusCreated,
+		do(http.MethodPost, "interface=1&filter=path+scion&size=5").Code)
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "interface=internal").Code)
+	assert.Equal(t, "interface internal: filter=\"\" packets=0/1000 total=0\n"+
+		"interface 1: filter=\"path scion\" packets=0/5 total=0\n",
+		do(http.MethodGet, "").Body.String())
+
+	w := do(http.MethodGet, "interface=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, err := pcapgo.NewNgReader(w.Body, pcapgo.DefaultNgReaderOptions)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, "interface=1").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "interface=1").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodPut, "interface=1").Code)
+}
@@ -15,11 +15,14 @@
 package router
 
 import (
+	"errors"
 	"net"
 	"os"
+	"sync"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/topology"
@@ -28,16 +31,107 @@ import (
 
 // Connector implements the Dataplane API of the router control process. It sets
 // up connections for the DataPlane.
+//
+// Changes are applied to the DataPlane immediately, unless a reconfiguration is
+// in progress. In that case they are only applied once the reconfiguration is
+// committed.
 type Connector struct {
 	DataPlane DataPlane
 
-	ia addr.IA
+	// mtx protects the ISD-AS and the reconfiguration in progress.
+	mtx sync.Mutex
+	ia  addr.IA
+	// reconf is the reconfiguration in progress, nil if there is none.
+	reconf *Reconfiguration
+}
+
+// configurer is the part of the configuration API that is shared by the
+// DataPlane and a Reconfiguration.
+type configurer interface {
+	SetKey(index int, key []byte) error
+	DelKey(index int) error
+	AddExternalInterface(ifID uint16, conn BatchConn) error
+	DelExternalInterface(ifID uint16) error
+	AddNeighborIA(ifID uint16, remote addr.IA) error
+	AddLinkType(ifID uint16, linkTo topology.LinkType) error
+	AddExternalInterfaceBFD(ifID uint16, conn BatchConn) error
+	AddSvc(svc addr.HostSVC, a net.Addr) error
+	DelSvc(svc addr.HostSVC, a net.Addr) error
+	AddNextHop(ifID uint16, a net.Addr) error
+	DelNextHop(ifID uint16) error
+	AddNextHopBFD(ifID uint16, a net.Addr) error
 }
 
-var errMultiIA = serrors.New("different IA not allowed")
+var (
+	errMultiIA          = serrors.New("different IA not allowed")
+	errReconfInProgress = serrors.New("reconfiguration already in progress")
+	errNoReconf         = serrors.New("no reconfiguration in progress")
+)
+
+// configurer returns the reconfiguration in progress, or the DataPlane if there
+// is none.
+func (c *Connector) configurer() configurer {
+	if c.reconf != nil {
+		return c.reconf
+	}
+	return &c.DataPlane
+}
+
+// BeginReconfiguration starts a reconfiguration for the given ISD-AS. All
+// following changes are applied atomically once CommitReconfiguration is
+// called.
+func (c *Connector) BeginReconfiguration(ia addr.IA) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Beginning reconfiguration", "isd_as", ia)
+	if !c.ia.Equal(ia) {
+		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
+	}
+	if c.reconf != nil {
+		return errReconfInProgress
+	}
+	c.reconf = c.DataPlane.BeginReconfiguration()
+	return nil
+}
+
+// CommitReconfiguration applies all changes of the reconfiguration in progress
+// for the given ISD-AS.
+func (c *Connector) CommitReconfiguration(ia addr.IA) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Committing reconfiguration", "isd_as", ia)
+	if !c.ia.Equal(ia) {
+		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
+	}
+	if c.reconf == nil {
+		return errNoReconf
+	}
+	r := c.reconf
+	c.reconf = nil
+	return r.Commit()
+}
+
+// AbortReconfiguration discards all changes of the reconfiguration in progress
+// for the given ISD-AS.
+func (c *Connector) AbortReconfiguration(ia addr.IA) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Aborting reconfiguration", "isd_as", ia)
+	if !c.ia.Equal(ia) {
+		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
+	}
+	if c.reconf == nil {
+		return errNoReconf
+	}
+	c.reconf.Abort()
+	c.reconf = nil
+	return nil
+}
 
 // CreateIACtx creates the context for ISD-AS.
 func (c *Connector) CreateIACtx(ia addr.IA) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	log.Debug("CreateIACtx", "isd_as", ia)
 	if !c.ia.IsZero() {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
@@ -48,6 +142,8 @@ func (c *Connector) CreateIACtx(ia addr.IA) error {
 
 // AddInternalInterface adds the internal interface.
 func (c *Connector) AddInternalInterface(ia addr.IA, local net.UDPAddr) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	log.Debug("Adding internal interface", "isd_as", ia, "local", local)
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
@@ -61,8 +157,11 @@ func (c *Connector) AddInternalInterface(ia addr.IA, local net.UDPAddr) error {
 
 // AddExternalInterface adds a link between the local and remote address.
 func (c *Connector) AddExternalInterface(ia addr.IA, ifID common.IFIDType,
-	local, remote net.UDPAddr, linkTo topology.LinkType, mtu int, owned bool) error {
+	local, remote net.UDPAddr, remoteIA addr.IA, linkTo topology.LinkType, mtu int,
+	owned bool) error {
 
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	var bfdDisabled bool
 	disabled, _ := os.LookupEnv("DISABLE_BFD")
 	if disabled == "true" {
@@ -70,78 +169,140 @@ func (c *Connector) AddExternalInterface(ia addr.IA, ifID common.IFIDType,
 	}
 
 	log.Debug("Adding external interface",
-		"isd_as", ia, "ifID", ifID, "local", local, "remote", remote, "owned", owned,
-		"bfd", !bfdDisabled)
+		"isd_as", ia, "ifID", ifID, "local", local, "remote", remote,
+		"remote_isd_as", remoteIA, "owned", owned, "bfd", !bfdDisabled)
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
+	cfg := c.configurer()
 	if !owned {
 		if !bfdDisabled {
-			if err := c.DataPlane.AddNextHopBFD(uint16(ifID), &remote); err != nil {
+			if err := cfg.AddNextHopBFD(uint16(ifID), &remote); err != nil {
 				return serrors.WrapStr("adding next hop BFD", err, "if_id", ifID)
 			}
 		}
-		return c.DataPlane.AddNextHop(uint16(ifID), &remote)
+		return cfg.AddNextHop(uint16(ifID), &remote)
 	}
 	connection, err := conn.New(&local, &remote, nil)
 	if err != nil {
 		return err
 	}
+	if err := cfg.AddNeighborIA(uint16(ifID), remoteIA); err != nil {
+		connection.Close()
+		return serrors.WrapStr("adding neighbor IA", err, "if_id", ifID)
+	}
+	if err := cfg.AddLinkType(uint16(ifID), linkTo); err != nil {
+		connection.Close()
+		return serrors.WrapStr("adding link type", err, "if_id", ifID)
+	}
 	if !bfdDisabled {
-		if err := c.DataPlane.AddExternalInterfaceBFD(uint16(ifID), connection); err != nil {
+		if err := cfg.AddExternalInterfaceBFD(uint16(ifID), connection); err != nil {
+			connection.Close()
 			return serrors.WrapStr("adding external BFD", err, "if_id", ifID)
 		}
 	}
-	return c.DataPlane.AddExternalInterface(uint16(ifID), connection)
+	if err := cfg.AddExternalInterface(uint16(ifID), connection); err != nil {
+		connection.Close()
+		return err
+	}
+	return nil
+}
+
+// DelExternalInterface removes the interface with the given ID. For interfaces
+// owned by this router the connection is closed, for interfaces owned by other
+// routers of the AS the next hop is removed.
+func (c *Connector) DelExternalInterface(ia addr.IA, ifID common.IFIDType) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Deleting external interface", "isd_as", ia, "ifID", ifID)
+	if !c.ia.Equal(ia) {
+		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
+	}
+	cfg := c.configurer()
+	err := cfg.DelExternalInterface(uint16(ifID))
+	if errors.Is(err, notFound) {
+		return cfg.DelNextHop(uint16(ifID))
+	}
+	return err
 }
 
 // AddSvc adds the service address for the given ISD-AS.
 func (c *Connector) AddSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	log.Debug("Adding SVC", "isd_as", ia, "svc", svc, "ip", ip)
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	return c.DataPlane.AddSvc(svc, &net.IPAddr{IP: ip})
+	return c.configurer().AddSvc(svc, &net.IPAddr{IP: ip})
 }
 
 // DelSvc deletes the service entry for the given ISD-AS and IP pair.
 func (c *Connector) DelSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Deleting SVC", "isd_as", ia, "svc", svc, "ip", ip)
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	// TODO(lukedirtwalker) implement.
-	log.Info("DelSVC is currently not implemented for the GoBR")
-	return nil
+	return c.configurer().DelSvc(svc, &net.IPAddr{IP: ip})
 }
 
 // SetKey sets the key for the given ISD-AS at the given index.
 func (c *Connector) SetKey(ia addr.IA, index int, key common.RawBytes) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	log.Debug("Setting key", "isd_as", ia, "index", index)
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	if index != 0 {
-		return serrors.New("currently only index 0 key is supported")
+	return c.configurer().SetKey(index, key)
+}
+
+// DelKey deletes the key for the given ISD-AS at the given index.
+func (c *Connector) DelKey(ia addr.IA, index int) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	log.Debug("Deleting key", "isd_as", ia, "index", index)
+	if !c.ia.Equal(ia) {
+		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	return c.DataPlane.SetKey(key)
+	return c.configurer().DelKey(index)
 }
 
-// SetRevocation sets the revocation for the given ISD-AS and interface.
+// SetRevocation sets the revocation for the given ISD-AS and interface. The
+// revocation is removed from the data plane once it expires.
 func (c *Connector) SetRevocation(ia addr.IA, ifID common.IFIDType, rev common.RawBytes) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	// TODO(lukedirtwalker) implement.
-	log.Info("SetRevocation is currently not implemented for the GoBR")
-	return nil
+	sRevInfo, err := path_mgmt.NewSignedRevInfoFromRaw(rev)
+	if err != nil {
+		return serrors.WrapStr("parsing signed revocation", err, "if_id", ifID)
+	}
+	revInfo, err := sRevInfo.RevInfo()
+	if err != nil {
+		return serrors.WrapStr("parsing revocation", err, "if_id", ifID)
+	}
+	if revInfo.IfID != ifID || !revInfo.IA().Equal(ia) {
+		return serrors.New("revocation does not match interface", "isd_as", ia,
+			"if_id", ifID, "rev_isd_as", revInfo.IA(), "rev_if_id", revInfo.IfID)
+	}
+	log.Debug("Setting revocation", "isd_as", ia, "if_id", ifID,
+		"expiration", revInfo.Expiration())
+	return c.DataPlane.SetRevocation(uint16(ifID), revInfo.Expiration(), rev)
 }
 
 // DelRevocation deletes the revocation for the given ISD-AS and interface.
 func (c *Connector) DelRevocation(ia addr.IA, ifid common.IFIDType) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 	if !c.ia.Equal(ia) {
 		return serrors.WithCtx(errMultiIA, "current", c.ia, "new", ia)
 	}
-	// TODO(lukedirtwalker) implement.
-	log.Info("DelRevocation is currently not implemented for the GoBR")
+	log.Debug("Deleting revocation", "isd_as", ia, "if_id", ifid)
+	c.DataPlane.DelRevocation(uint16(ifid))
 	return nil
 }
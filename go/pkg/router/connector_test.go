@@ -0,0 +1,83 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/router"
+)
+
+func TestConnectorSetRevocation(t *testing.T) {
+	local := xtest.MustParseIA("1-ff00:0:110")
+	signedRev := func(t *testing.T, ia string, ifID common.IFIDType) common.RawBytes {
+		sRev, err := path_mgmt.NewSignedRevInfo(&path_mgmt.RevInfo{
+			IfID:         ifID,
+			RawIsdas:     xtest.MustParseIA(ia).IAInt(),
+			RawTimestamp: util.TimeToSecs(time.Now()),
+			RawTTL:       10,
+		}, infra.NullSigner)
+		require.NoError(t, err)
+		raw, err := sRev.Pack()
+		require.NoError(t, err)
+		return raw
+	}
+
+	testCases := map[string]struct {
+		Rev       common.RawBytes
+		Assertion assert.ErrorAssertionFunc
+		Revoked   map[uint16]struct{}
+	}{
+		"matching revocation": {
+			Rev:       signedRev(t, "1-ff00:0:110", 3),
+			Assertion: assert.NoError,
+			Revoked:   map[uint16]struct{}{3: {}},
+		},
+		"different interface": {
+			Rev:       signedRev(t, "1-ff00:0:110", 4),
+			Assertion: assert.Error,
+			Revoked:   map[uint16]struct{}{},
+		},
+		"different ISD-AS": {
+			Rev:       signedRev(t, "1-ff00:0:111", 3),
+			Assertion: assert.Error,
+			Revoked:   map[uint16]struct{}{},
+		},
+		"malformed revocation": {
+			Rev:       common.RawBytes("garbage"),
+			Assertion: assert.Error,
+			Revoked:   map[uint16]struct{}{},
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c := &router.Connector{}
+			require.NoError(t, c.CreateIACtx(local))
+			tc.Assertion(t, c.SetRevocation(local, 3, tc.Rev))
+			assert.Equal(t, tc.Revoked, c.DataPlane.RevokedInterfaces())
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package control
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"net"
 	"sort"
@@ -25,6 +26,7 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/assert"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/topology"
 )
@@ -34,14 +36,22 @@ import (
 type Dataplane interface {
 	CreateIACtx(ia addr.IA) error
 	AddInternalInterface(ia addr.IA, local net.UDPAddr) error
-	AddExternalInterface(ia addr.IA, ifid common.IFIDType,
-		local, remote net.UDPAddr, linkTo topology.LinkType, mtu int, owned bool) error
+	AddExternalInterface(ia addr.IA, ifid common.IFIDType, local, remote net.UDPAddr,
+		remoteIA addr.IA, linkTo topology.LinkType, mtu int, owned bool) error
+	DelExternalInterface(ia addr.IA, ifid common.IFIDType) error
 	AddSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error
 	DelSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error
 	SetKey(ia addr.IA, index int, key common.RawBytes) error
+	DelKey(ia addr.IA, index int) error
 
 	SetRevocation(ia addr.IA, ifid common.IFIDType, rev common.RawBytes) error
 	DelRevocation(ia addr.IA, ifid common.IFIDType) error
+
+	// BeginReconfiguration starts collecting changes, which are only applied
+	// once CommitReconfiguration is called.
+	BeginReconfiguration(ia addr.IA) error
+	CommitReconfiguration(ia addr.IA) error
+	AbortReconfiguration(ia addr.IA) error
 }
 
 // ConfigDataplane configures the data-plane with the new configuration.
@@ -55,13 +65,9 @@ func ConfigDataplane(dp Dataplane, cfg *brconf.BRConf) error {
 		return err
 	}
 	// Set Keys
-	// XXX HSR currently only support 1 key, so use Key0
 	// Should it be an error if no key is set?
-	if len(cfg.MasterKeys.Key0) > 0 {
-		key0 := DeriveHFMacKey(cfg.MasterKeys.Key0)
-		if err := dp.SetKey(cfg.IA, 0, key0); err != nil {
-			return err
-		}
+	if err := confKeys(dp, cfg); err != nil {
+		return err
 	}
 	// Add internal interfaces
 	if cfg.BR != nil {
@@ -92,37 +98,86 @@ func DeriveHFMacKey(k []byte) []byte {
 	return pbkdf2.Key(k, hfMacSalt, 1000, 16, sha256.New)
 }
 
+// DeriveHFMacKeys derives the MAC keys of all key epochs from the master keys,
+// ordered from the current to the oldest epoch. The current epoch uses Key0,
+// the previous epoch Key1. Derivation stops at the first epoch without a master
+// key, so that the current key is always set if any key is.
+func DeriveHFMacKeys(m keyconf.Master) [][]byte {
+	var keys [][]byte
+	for _, k := range [][]byte{m.Key0, m.Key1} {
+		if len(k) == 0 {
+			break
+		}
+		keys = append(keys, DeriveHFMacKey(k))
+	}
+	return keys
+}
+
+// numKeyEpochs is the number of key epochs, one per master key.
+const numKeyEpochs = 2
+
+// confKeys installs the keys of all key epochs, new hop fields are created with
+// the key of the current epoch. The keys of previous epochs without a master key
+// are removed.
+func confKeys(dp Dataplane, cfg *brconf.BRConf) error {
+	keys := DeriveHFMacKeys(cfg.MasterKeys)
+	for i, key := range keys {
+		if err := dp.SetKey(cfg.IA, i, key); err != nil {
+			return serrors.WrapStr("setting key", err, "index", i)
+		}
+	}
+	// The key of the current epoch can only be replaced.
+	for i := len(keys); i < numKeyEpochs; i++ {
+		if i == 0 {
+			continue
+		}
+		if err := dp.DelKey(cfg.IA, i); err != nil {
+			return serrors.WrapStr("deleting key", err, "index", i)
+		}
+	}
+	return nil
+}
+
 func confExternalInterfaces(dp Dataplane, cfg *brconf.BRConf) error {
 	// Sort out keys/ifids to get deterministic order for unit testing
-	infoMap := cfg.Topo.IFInfoMap()
-	if len(infoMap) == 0 {
-		// nothing to do
-		return nil
+	for _, ifid := range sortedIFIDs(cfg.Topo.IFInfoMap()) {
+		if err := addExternalInterface(dp, cfg, ifid); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+func sortedIFIDs(infoMap topology.IfInfoMap) []common.IFIDType {
 	ifids := []common.IFIDType{}
 	for k := range infoMap {
 		ifids = append(ifids, k)
 	}
 	sort.Slice(ifids, func(i, j int) bool { return ifids[i] < ifids[j] })
-	// External interfaces
-	for _, ifid := range ifids {
-		iface := infoMap[ifid]
-		_, owned := cfg.BR.IFs[ifid]
-		if !owned {
-			// XXX The current implementation effectively uses IP/UDP tunnels to create
-			// the SCION network as an overlay, with forwarding to local hosts being a special case.
-			// When setting up external interfaces that belong to other routers in the AS, they
-			// are basically IP/UDP tunnels between the two border routers, and as such is
-			// configured in the data plane.
-			iface.Local = cfg.BR.InternalAddr
-			iface.Remote = iface.InternalAddr
-		}
-		if err := dp.AddExternalInterface(cfg.IA, ifid, *iface.Local, *iface.Remote,
-			iface.LinkType, iface.MTU, owned); err != nil {
-			return err
-		}
+	return ifids
+}
+
+// externalInterface returns the interface with the given ID as it is configured
+// in the data plane, and whether it is owned by this router.
+func externalInterface(cfg *brconf.BRConf, ifid common.IFIDType) (topology.IFInfo, bool) {
+	iface := cfg.Topo.IFInfoMap()[ifid]
+	_, owned := cfg.BR.IFs[ifid]
+	if !owned {
+		// XXX The current implementation effectively uses IP/UDP tunnels to create
+		// the SCION network as an overlay, with forwarding to local hosts being a special case.
+		// When setting up external interfaces that belong to other routers in the AS, they
+		// are basically IP/UDP tunnels between the two border routers, and as such is
+		// configured in the data plane.
+		iface.Local = cfg.BR.InternalAddr
+		iface.Remote = iface.InternalAddr
 	}
-	return nil
+	return iface, owned
+}
+
+func addExternalInterface(dp Dataplane, cfg *brconf.BRConf, ifid common.IFIDType) error {
+	iface, owned := externalInterface(cfg, ifid)
+	return dp.AddExternalInterface(cfg.IA, ifid, *iface.Local, *iface.Remote,
+		iface.IA, iface.LinkType, iface.MTU, owned)
 }
 
 var svcTypes = []addr.HostSVC{
@@ -134,9 +189,23 @@ var svcTypes = []addr.HostSVC{
 }
 
 func confServices(dp Dataplane, cfg *brconf.BRConf) error {
+	svcs := services(cfg)
+	for _, svc := range svcTypes {
+		for _, ip := range svcs[svc] {
+			if err := dp.AddSvc(cfg.IA, svc, ip); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// services returns the addresses of all SVC types in the configuration.
+func services(cfg *brconf.BRConf) map[addr.HostSVC][]net.IP {
+	svcs := make(map[addr.HostSVC][]net.IP)
 	if cfg.Topo == nil {
 		// nothing to tdo
-		return nil
+		return svcs
 	}
 	for _, svc := range svcTypes {
 		addrs, err := cfg.Topo.UnderlayMulticast(svc)
@@ -149,10 +218,169 @@ func confServices(dp Dataplane, cfg *brconf.BRConf) error {
 			return addrs[i].IP.String() < addrs[j].IP.String()
 		})
 		for _, a := range addrs {
-			if err := dp.AddSvc(cfg.IA, svc, a.IP); err != nil {
+			svcs[svc] = append(svcs[svc], a.IP)
+		}
+	}
+	return svcs
+}
+
+// ReconfigDataplane applies the difference between the old and the new
+// configuration to a configured, and possibly running, data-plane. The changes
+// are applied atomically, packets are either processed with the old or the new
+// configuration.
+//
+// The ISD-AS and the internal address can not be changed. An interface that
+// changes but keeps its local address is an exception to the atomicity: its
+// socket can only be bound once the old one is closed. Such an interface is
+// removed in a separate reconfiguration first, and packets on it are dropped
+// until the new configuration is committed. If applying the new configuration
+// fails, the interface stays removed.
+func ReconfigDataplane(dp Dataplane, old, cfg *brconf.BRConf) error {
+	if old == nil || cfg == nil {
+		return serrors.New("empty configuration")
+	}
+	if !old.IA.Equal(cfg.IA) {
+		return serrors.New("changing the ISD-AS is not supported",
+			"old", old.IA, "new", cfg.IA)
+	}
+	if internalAddr(old).String() != internalAddr(cfg).String() {
+		return serrors.New("changing the internal address is not supported",
+			"old", internalAddr(old), "new", internalAddr(cfg))
+	}
+
+	removed, added, rebound := diffExternalInterfaces(old, cfg)
+	if len(rebound) > 0 {
+		err := reconfigure(dp, cfg.IA, func() error {
+			for _, ifid := range rebound {
+				if err := dp.DelExternalInterface(cfg.IA, ifid); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return serrors.WrapStr("removing rebound interfaces", err)
+		}
+	}
+	return reconfigure(dp, cfg.IA, func() error {
+		if !bytes.Equal(old.MasterKeys.Key0, cfg.MasterKeys.Key0) ||
+			!bytes.Equal(old.MasterKeys.Key1, cfg.MasterKeys.Key1) {
+
+			if err := confKeys(dp, cfg); err != nil {
+				return err
+			}
+		}
+		for _, ifid := range removed {
+			if err := dp.DelExternalInterface(cfg.IA, ifid); err != nil {
+				return err
+			}
+		}
+		for _, ifid := range added {
+			if err := addExternalInterface(dp, cfg, ifid); err != nil {
 				return err
 			}
 		}
+		oldSvcs, newSvcs := services(old), services(cfg)
+		for _, svc := range svcTypes {
+			for _, ip := range diffIPs(oldSvcs[svc], newSvcs[svc]) {
+				if err := dp.DelSvc(cfg.IA, svc, ip); err != nil {
+					return err
+				}
+			}
+			for _, ip := range diffIPs(newSvcs[svc], oldSvcs[svc]) {
+				if err := dp.AddSvc(cfg.IA, svc, ip); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// reconfigure applies the changes made by f atomically. If f fails, none of
+// the changes are applied.
+func reconfigure(dp Dataplane, ia addr.IA, f func() error) error {
+	if err := dp.BeginReconfiguration(ia); err != nil {
+		return err
 	}
-	return nil
+	if err := f(); err != nil {
+		if abortErr := dp.AbortReconfiguration(ia); abortErr != nil {
+			return serrors.WithCtx(err, "abort_err", abortErr)
+		}
+		return err
+	}
+	return dp.CommitReconfiguration(ia)
+}
+
+func internalAddr(cfg *brconf.BRConf) *net.UDPAddr {
+	if cfg.BR == nil {
+		return nil
+	}
+	return cfg.BR.InternalAddr
+}
+
+// diffExternalInterfaces returns the interfaces that have to be removed from and
+// added to the data plane to go from the old to the new configuration. Changed
+// interfaces are both removed and added. Changed interfaces owned by this router
+// that keep their local address are returned as rebound instead of removed.
+func diffExternalInterfaces(old, cfg *brconf.BRConf) ([]common.IFIDType,
+	[]common.IFIDType, []common.IFIDType) {
+
+	var oldIFs, newIFs topology.IfInfoMap
+	if old.BR != nil && old.Topo != nil {
+		oldIFs = old.Topo.IFInfoMap()
+	}
+	if cfg.BR != nil && cfg.Topo != nil {
+		newIFs = cfg.Topo.IFInfoMap()
+	}
+	var removed, added, rebound []common.IFIDType
+	for _, ifid := range sortedIFIDs(oldIFs) {
+		oldIF, oldOwned := externalInterface(old, ifid)
+		if _, ok := newIFs[ifid]; !ok {
+			removed = append(removed, ifid)
+			continue
+		}
+		newIF, newOwned := externalInterface(cfg, ifid)
+		if oldOwned == newOwned && sameExternalInterface(oldIF, newIF) {
+			continue
+		}
+		if oldOwned && newOwned && oldIF.Local.String() == newIF.Local.String() {
+			rebound = append(rebound, ifid)
+		} else {
+			removed = append(removed, ifid)
+		}
+		added = append(added, ifid)
+	}
+	for _, ifid := range sortedIFIDs(newIFs) {
+		if _, ok := oldIFs[ifid]; !ok {
+			added = append(added, ifid)
+		}
+	}
+	return removed, added, rebound
+}
+
+func sameExternalInterface(a, b topology.IFInfo) bool {
+	return a.Local.String() == b.Local.String() &&
+		a.Remote.String() == b.Remote.String() &&
+		a.IA.Equal(b.IA) &&
+		a.LinkType == b.LinkType &&
+		a.MTU == b.MTU
+}
+
+// diffIPs returns the addresses in a that are not in b.
+func diffIPs(a, b []net.IP) []net.IP {
+	var diff []net.IP
+	for _, ip := range a {
+		found := false
+		for _, other := range b {
+			if ip.Equal(other) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			diff = append(diff, ip)
+		}
+	}
+	return diff
 }
@@ -0,0 +1,273 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/border/brconf"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestReconfigDataplane(t *testing.T) {
+	keys := keyconf.Master{Key0: []byte("key0"), Key1: []byte("key1")}
+	if1 := testIF(1, "10.0.0.1:50000", "192.168.0.1:50000", "1-ff00:0:111")
+	if2 := testIF(2, "10.0.0.1:50001", "192.168.0.2:50000", "1-ff00:0:112")
+
+	testCases := map[string]struct {
+		Old       *brconf.BRConf
+		New       *brconf.BRConf
+		FailOn    string
+		Calls     []string
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"no change": {
+			Old:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			Calls:     []string{"Begin", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"add interface": {
+			Old:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1, if2),
+			Calls:     []string{"Begin", "AddExternalInterface 2", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"remove interface": {
+			Old:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1, if2),
+			New:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			Calls:     []string{"Begin", "DelExternalInterface 2", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"change remote address": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys,
+				testIF(1, "10.0.0.1:50000", "192.168.0.3:50000", "1-ff00:0:111")),
+			Calls: []string{
+				"Begin", "DelExternalInterface 1", "Commit",
+				"Begin", "AddExternalInterface 1", "Commit",
+			},
+			Assertion: assert.NoError,
+		},
+		"change local address": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys,
+				testIF(1, "10.0.0.1:50002", "192.168.0.1:50000", "1-ff00:0:111")),
+			Calls: []string{
+				"Begin", "DelExternalInterface 1", "AddExternalInterface 1", "Commit",
+			},
+			Assertion: assert.NoError,
+		},
+		"change remote ISD-AS": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys,
+				testIF(1, "10.0.0.1:50000", "192.168.0.1:50000", "1-ff00:0:113")),
+			Calls: []string{
+				"Begin", "DelExternalInterface 1", "Commit",
+				"Begin", "AddExternalInterface 1", "Commit",
+			},
+			Assertion: assert.NoError,
+		},
+		"change key": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New: testConf(t, "1-ff00:0:110", "10.0.0.10:30042",
+				keyconf.Master{Key0: []byte("key2"), Key1: []byte("key0")}, if1),
+			Calls:     []string{"Begin", "SetKey 0", "SetKey 1", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"remove previous key": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New: testConf(t, "1-ff00:0:110", "10.0.0.10:30042",
+				keyconf.Master{Key0: []byte("key0")}, if1),
+			Calls:     []string{"Begin", "SetKey 0", "DelKey 1", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"add previous key": {
+			Old: testConf(t, "1-ff00:0:110", "10.0.0.10:30042",
+				keyconf.Master{Key0: []byte("key0")}, if1),
+			New:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			Calls:     []string{"Begin", "SetKey 0", "SetKey 1", "Commit"},
+			Assertion: assert.NoError,
+		},
+		"change internal address": {
+			Old:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New:       testConf(t, "1-ff00:0:110", "10.0.0.11:30042", keys, if1, if2),
+			Assertion: assert.Error,
+		},
+		"change ISD-AS": {
+			Old:       testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New:       testConf(t, "1-ff00:0:120", "10.0.0.10:30042", keys, if1),
+			Assertion: assert.Error,
+		},
+		"dataplane error aborts": {
+			Old:    testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if1),
+			New:    testConf(t, "1-ff00:0:110", "10.0.0.10:30042", keys, if2),
+			FailOn: "AddExternalInterface 2",
+			Calls: []string{
+				"Begin", "DelExternalInterface 1", "AddExternalInterface 2", "Abort",
+			},
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dp := &recordingDataplane{failOn: tc.FailOn}
+			err := ReconfigDataplane(dp, tc.Old, tc.New)
+			tc.Assertion(t, err)
+			assert.Equal(t, tc.Calls, dp.calls)
+		})
+	}
+}
+
+func testIF(ifid common.IFIDType, local, remote, ia string) topology.IFInfo {
+	return topology.IFInfo{
+		ID:       ifid,
+		Local:    udpAddr(local),
+		Remote:   udpAddr(remote),
+		IA:       xtest.MustParseIA(ia),
+		LinkType: topology.Child,
+		MTU:      1472,
+	}
+}
+
+func testConf(t *testing.T, ia, internal string, keys keyconf.Master,
+	ifs ...topology.IFInfo) *brconf.BRConf {
+
+	br := topology.BRInfo{
+		Name:         "br1",
+		InternalAddr: udpAddr(internal),
+		IFs:          make(map[common.IFIDType]*topology.IFInfo),
+	}
+	ifInfoMap := make(topology.IfInfoMap)
+	for _, iface := range ifs {
+		iface := iface
+		iface.BRName = br.Name
+		br.IFIDs = append(br.IFIDs, iface.ID)
+		br.IFs[iface.ID] = &iface
+		ifInfoMap[iface.ID] = iface
+	}
+	rw := topology.NewRWTopology()
+	rw.IA = xtest.MustParseIA(ia)
+	rw.BR[br.Name] = br
+	rw.BRNames = []string{br.Name}
+	rw.IFInfoMap = ifInfoMap
+	return &brconf.BRConf{
+		Topo:       topology.FromRWTopology(rw),
+		IA:         rw.IA,
+		BR:         &br,
+		MasterKeys: keys,
+	}
+}
+
+func udpAddr(s string) *net.UDPAddr {
+	a, err := net.ResolveUDPAddr("udp", s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// recordingDataplane records the calls made to it. The call matching failOn
+// returns an error.
+type recordingDataplane struct {
+	failOn string
+	calls  []string
+}
+
+func (d *recordingDataplane) record(call string) error {
+	d.calls = append(d.calls, call)
+	if call == d.failOn {
+		return serrors.New("test error", "call", call)
+	}
+	return nil
+}
+
+func (d *recordingDataplane) CreateIACtx(ia addr.IA) error {
+	return d.record("CreateIACtx")
+}
+
+func (d *recordingDataplane) AddInternalInterface(ia addr.IA, local net.UDPAddr) error {
+	return d.record("AddInternalInterface")
+}
+
+func (d *recordingDataplane) AddExternalInterface(ia addr.IA, ifid common.IFIDType, local,
+	remote net.UDPAddr, remoteIA addr.IA, linkTo topology.LinkType, mtu int, owned bool) error {
+
+	return d.record(fmt.Sprintf("AddExternalInterface %d", ifid))
+}
+
+func (d *recordingDataplane) DelExternalInterface(ia addr.IA, ifid common.IFIDType) error {
+	return d.record(fmt.Sprintf("DelExternalInterface %d", ifid))
+}
+
+func (d *recordingDataplane) AddSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error {
+	return d.record(fmt.Sprintf("AddSvc %s %s", svc, ip))
+}
+
+func (d *recordingDataplane) DelSvc(ia addr.IA, svc addr.HostSVC, ip net.IP) error {
+	return d.record(fmt.Sprintf("DelSvc %s %s", svc, ip))
+}
+
+func (d *recordingDataplane) SetKey(ia addr.IA, index int, key common.RawBytes) error {
+	return d.record(fmt.Sprintf("SetKey %d", index))
+}
+
+func (d *recordingDataplane) DelKey(ia addr.IA, index int) error {
+	return d.record(fmt.Sprintf("DelKey %d", index))
+}
+
+func (d *recordingDataplane) SetRevocation(ia addr.IA, ifid common.IFIDType,
+	rev common.RawBytes) error {
+
+	return d.record(fmt.Sprintf("SetRevocation %d", ifid))
+}
+
+func (d *recordingDataplane) DelRevocation(ia addr.IA, ifid common.IFIDType) error {
+	return d.record(fmt.Sprintf("DelRevocation %d", ifid))
+}
+
+func (d *recordingDataplane) BeginReconfiguration(ia addr.IA) error {
+	return d.record("Begin")
+}
+
+func (d *recordingDataplane) CommitReconfiguration(ia addr.IA) error {
+	return d.record("Commit")
+}
+
+func (d *recordingDataplane) AbortReconfiguration(ia addr.IA) error {
+	return d.record("Abort")
+}
+
+var _ Dataplane = (*recordingDataplane)(nil)
+
+func TestDiffIPs(t *testing.T) {
+	a := []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}
+	b := []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.3")}
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1")}, diffIPs(a, b))
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.3")}, diffIPs(b, a))
+	require.Empty(t, diffIPs(a, a))
+}
@@ -27,7 +27,7 @@ import (
 )
 
 func processCtrl(c *IACtx) {
-	a := c.BRConf.BR.CtrlAddrs.SCIONAddress
+	a := c.config().BR.CtrlAddrs.SCIONAddress
 	log.Debug("Listening for gRPC", "addr", a)
 	routerListener, err := net.Listen("tcp", a.String())
 	if err != nil {
@@ -42,6 +42,9 @@ type IACtx struct {
 
 	// Revocation expiration timers
 	timers *revTimer
+
+	// mtx protects BRConf once the dataplane is started.
+	mtx sync.RWMutex
 }
 
 // Start configures the dataplane for the given context.
@@ -97,6 +100,25 @@ func (iac *IACtx) Start(wg *sync.WaitGroup, v2 bool) error {
 	return nil
 }
 
+// Reload applies the new configuration to the running dataplane. The current
+// configuration is only replaced if the dataplane accepted the new one.
+func (iac *IACtx) Reload(brConf *brconf.BRConf) error {
+	iac.mtx.Lock()
+	defer iac.mtx.Unlock()
+	if err := ReconfigDataplane(iac.DP, iac.BRConf, brConf); err != nil {
+		return err
+	}
+	iac.BRConf = brConf
+	return nil
+}
+
+// config returns the current configuration.
+func (iac *IACtx) config() *brconf.BRConf {
+	iac.mtx.RLock()
+	defer iac.mtx.RUnlock()
+	return iac.BRConf
+}
+
 func dumpConfig(brConf *brconf.BRConf) (string, error) {
 	if brConf == nil {
 		return "", serrors.New("empty configuration")
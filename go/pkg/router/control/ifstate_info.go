@@ -72,7 +72,7 @@ func (h StateHandler) UpdateState(ifStates []grpc.InterfaceState) {
 	merr := common.MultiError{}
 	for _, info := range ifStates {
 		ifid := common.IFIDType(info.ID)
-		intf, ok := h.c.BRConf.Topo.IFInfoMap()[ifid]
+		intf, ok := h.c.config().Topo.IFInfoMap()[ifid]
 		if !ok {
 			log.Info("Interface ID does not exist", "ifid", ifid)
 			continue
@@ -118,7 +118,7 @@ func (h StateHandler) UpdateState(ifStates []grpc.InterfaceState) {
 				log.Debug("Revocation deletion stopped ", "timer", t, "ifid", ifid)
 			}
 			// Set the new revocation.
-			err = h.c.DP.SetRevocation(h.c.BRConf.IA, ifid, rawSRev)
+			err = h.c.DP.SetRevocation(h.c.config().IA, ifid, rawSRev)
 			if err != nil {
 				merr = append(merr, common.NewBasicError("set revocation", err,
 					"revinfo", info))
@@ -127,7 +127,7 @@ func (h StateHandler) UpdateState(ifStates []grpc.InterfaceState) {
 			// Schedule revocation removal
 			h.c.timers.Store(ifid, time.AfterFunc(revinfo.Expiration().Sub(time.Now()), func() {
 				h.c.timers.Delete(ifid)
-				err = h.c.DP.DelRevocation(h.c.BRConf.IA, ifid)
+				err = h.c.DP.DelRevocation(h.c.config().IA, ifid)
 				if err != nil {
 					log.Error("Delete expired revocation failed", "err", err, "ifid", ifid)
 					return
@@ -149,7 +149,7 @@ func (h StateHandler) UpdateState(ifStates []grpc.InterfaceState) {
 				// already run, so do nothing else.
 				if t.Stop() {
 					// Timer stopped successfully, hence delRevocation did not run yet.
-					err = h.c.DP.DelRevocation(h.c.BRConf.IA, ifid)
+					err = h.c.DP.DelRevocation(h.c.config().IA, ifid)
 					if err != nil {
 
 						merr = append(merr, common.NewBasicError("delete revocation", err,
@@ -61,7 +61,7 @@ func updateInterfaces(c *IACtx, updater grpc.IfStateUpdater) error {
 	cl := metrics.ControlLabels{
 		Result: metrics.ErrProcess,
 	}
-	bsAddrs, err := c.BRConf.Topo.Multicast(addr.SvcCS)
+	bsAddrs, err := c.config().Topo.Multicast(addr.SvcCS)
 	if err != nil {
 		cl.Result = metrics.ErrResolveSVC
 		metrics.Control.SentIFStateReq(cl).Inc()
@@ -15,22 +15,22 @@
 package router
 
 import (
-	"crypto/rand"
+	"context"
 	"errors"
-	"hash"
-	"math/big"
+	"hash/fnv"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"golang.org/x/net/ipv4"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/log"
-	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/slayers"
 	"github.com/scionproto/scion/go/lib/slayers/path"
@@ -55,10 +55,27 @@ const (
 	bufSize = 9000
 )
 
+const (
+	// addrTypePointer is the offset of the address types and lengths in the
+	// common header, it is used as pointer of SCMP errors for invalid
+	// addresses.
+	addrTypePointer = 9
+	// dstIAPointer is the offset of the destination ISD-AS in the address
+	// header.
+	dstIAPointer = slayers.CmnHdrLen
+	// srcIAPointer is the offset of the source ISD-AS in the address header.
+	srcIAPointer = slayers.CmnHdrLen + addr.IABytes
+)
+
+// NumKeys is the number of keys in the keyring used for hop field MACs, the
+// current and the previous key.
+const NumKeys = 2
+
 type bfdSession interface {
 	Run() error
-	Messages() chan<- *layers.BFD
+	ReceiveMessage(*layers.BFD)
 	IsUp() bool
+	Close() error
 }
 
 // BatchConn is a connection that supports batch reads and writes.
@@ -72,22 +89,65 @@ type BatchConn interface {
 // from multiple sockets, performs routing, and sends them to their destinations
 // (after updating the path, if that is needed).
 //
+// The local IA and the internal interface must be configured prior to calling
+// Run. The external interfaces, the internal next hops, the SVC backends and
+// the forwarding key can also be changed on a running dataplane, see
+// BeginReconfiguration.
+//
 // XXX(lukedirtwalker): this is still in development and not feature complete.
-// Currently, only the following features are supported:
-//  - initializing connections; MUST be done prior to calling Run
 type DataPlane struct {
-	external         map[uint16]BatchConn
-	internal         BatchConn
-	internalIP       net.IP
-	internalNextHops map[uint16]net.Addr
-	svc              map[addr.HostSVC][]net.Addr
-	macFactory       func() hash.Hash
-	bfdSessions      map[uint16]bfdSession
-	localIA          addr.IA
-	mtx              sync.Mutex
-	running          bool
+	// Metrics are the metrics the dataplane reports. If nil, no metrics are
+	// reported. Must be set before calling Run.
+	Metrics *Metrics
+	// Policing is the admission control configuration. The zero value disables
+	// policing. Must be set before calling Run.
+	Policing Policing
+
+	internal   BatchConn
+	internalIP net.IP
+	localIA    addr.IA
+	// state holds the *forwardingState that is used for packet processing. It
+	// is replaced atomically when a reconfiguration is committed.
+	state atomic.Value
+	// metrics are the metrics used by the running dataplane, it is never nil
+	// after Run was called.
+	metrics *Metrics
+	// iaPolicer polices the packets per source ISD-AS across all interfaces.
+	// It is set when Run is called, nil disables the limit.
+	iaPolicer *iaPolicer
+	// captureState holds the map[uint16]*capture of the running packet
+	// captures by interface ID. It is replaced atomically, under captureMtx,
+	// when a capture is started or stopped.
+	captureState atomic.Value
+	captureMtx   sync.Mutex
+	// mtx serializes configuration changes. It is held for the whole duration
+	// of a reconfiguration.
+	mtx     sync.Mutex
+	running bool
+	// stop is closed once the dataplane shuts down.
+	stop chan struct{}
+	// readers tracks the goroutines that read from the connections, and
+	// bfdRunners the goroutines that run the BFD sessions.
+	readers    sync.WaitGroup
+	bfdRunners sync.WaitGroup
+	// revocations maps the IDs of revoked interfaces to their *revocation. In
+	// contrast to the rest of the configuration it can be modified on a running
+	// dataplane.
+	revocations sync.Map
 }
 
+// revocation is the revocation of an interface by the control plane.
+type revocation struct {
+	expiration time.Time
+	// signedRevInfo is the raw signed revocation, it is included in the SCMP
+	// messages for packets that are routed towards the revoked interface.
+	signedRevInfo []byte
+}
+
+// maxSignedRevInfoLen is the maximum length of a signed revocation, such that
+// the SCMP message still has room for a quote of the offending packet.
+const maxSignedRevInfoLen = 512
+
 var (
 	alreadySet                    = serrors.New("already set")
 	cannotRoute                   = serrors.New("cannot route, dropping pkt")
@@ -99,6 +159,18 @@ var (
 	unsupportedPathTypeNextHeader = serrors.New("unsupported combination")
 	noBFDSessionFound             = serrors.New("no BFD sessions was found")
 	noBFDSessionConfigured        = serrors.New("no BFD sessions have been configured")
+	invalidMAC                    = serrors.New("invalid MAC")
+	expiredHop                    = serrors.New("expired hop")
+	bfdSessionDown                = serrors.New("bfd session down")
+	interfaceRevoked              = serrors.New("interface revoked")
+	notFound                      = serrors.New("not found")
+	policedInterface              = serrors.New("ingress interface rate limit exceeded")
+	policedSourceIA               = serrors.New("source ISD-AS rate limit exceeded")
+	scmpRateLimited               = serrors.New("SCMP rate limit exceeded")
+	invalidPath                   = serrors.New("invalid path")
+	invalidSrcAddr                = serrors.New("invalid source address")
+	invalidDstAddr                = serrors.New("invalid destination address")
+	invalidSrcIA                  = serrors.New("invalid source ISD-AS")
 )
 
 type scmpError struct {
@@ -127,29 +199,26 @@ func (d *DataPlane) SetIA(ia addr.IA) error {
 	return nil
 }
 
-// SetKey sets the key used for MAC verification. The key provided here should
-// already be derived as in scrypto.HFMacFactory.
-func (d *DataPlane) SetKey(key []byte) error {
-	d.mtx.Lock()
-	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
-	}
-	if len(key) == 0 {
-		return emptyValue
-	}
-	if d.macFactory != nil {
-		return alreadySet
-	}
-	// First check for MAC creation errors.
-	if _, err := scrypto.InitMac(key); err != nil {
-		return err
-	}
-	d.macFactory = func() hash.Hash {
-		mac, _ := scrypto.InitMac(key)
-		return mac
-	}
-	return nil
+// SetKey sets the key at the given index of the keyring used for MAC
+// verification, replacing the key that is currently set at that index. Index 0
+// is the current key, with which new hop fields are created. Hop fields are
+// verified with all keys in the keyring, so that a rolled over key can stay
+// valid for the path segments that were created with it. The key provided here
+// should already be derived as in scrypto.HFMacFactory. This can be called on a
+// running dataplane.
+func (d *DataPlane) SetKey(index int, key []byte) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.SetKey(index, key)
+	})
+}
+
+// DelKey removes the previous key at the given index of the keyring, hop fields
+// created with it are no longer valid. The current key at index 0 can only be
+// replaced. This can be called on a running dataplane.
+func (d *DataPlane) DelKey(index int) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.DelKey(index)
+	})
 }
 
 // AddInternalInterface sets the interface the data-plane will use to
@@ -175,238 +244,432 @@ func (d *DataPlane) AddInternalInterface(conn BatchConn, ip net.IP) error {
 
 // AddExternalInterface adds the inter AS connection for the given interface ID.
 // If a connection for the given ID is already set this method will return an
-// error. This can only be called on a not yet running dataplane.
+// error. This can be called on a running dataplane.
 func (d *DataPlane) AddExternalInterface(ifID uint16, conn BatchConn) error {
-	d.mtx.Lock()
-	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
-	}
-	if conn == nil {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddExternalInterface(ifID, conn)
+	})
+}
+
+// DelExternalInterface removes the inter AS connection for the given interface
+// ID, together with its BFD session and neighbor IA. The connection is closed.
+// This can be called on a running dataplane.
+func (d *DataPlane) DelExternalInterface(ifID uint16) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.DelExternalInterface(ifID)
+	})
+}
+
+// AddNeighborIA adds the neighboring IA for a given interface ID. It is used to
+// label the metrics of the interface and to validate the source of packets
+// from child ASes. If an IA is already set for the given ID this method will
+// return an error. This can be called on a running dataplane.
+func (d *DataPlane) AddNeighborIA(ifID uint16, remote addr.IA) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddNeighborIA(ifID, remote)
+	})
+}
+
+// AddLinkType adds the type of the link of a given interface ID. It is used to
+// validate the source of packets from child ASes. If a link type is already set
+// for the given ID this method will return an error. This can be called on a
+// running dataplane.
+func (d *DataPlane) AddLinkType(ifID uint16, linkTo topology.LinkType) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddLinkType(ifID, linkTo)
+	})
+}
+
+// SetRevocation marks the given interface as revoked until the given
+// expiration time. Packets that are routed towards a revoked interface are
+// answered with an SCMP external interface down message that carries the
+// signed revocation. An existing revocation for the interface is replaced, and
+// expired revocations of other interfaces are removed. This can be called on a
+// running dataplane.
+func (d *DataPlane) SetRevocation(ifID uint16, expiration time.Time,
+	signedRevInfo []byte) error {
+
+	if ifID == 0 || len(signedRevInfo) == 0 {
 		return emptyValue
 	}
-	if _, exists := d.external[ifID]; exists {
-		return serrors.WithCtx(alreadySet, "ifID", ifID)
-	}
-	if d.external == nil {
-		d.external = make(map[uint16]BatchConn)
+	if len(signedRevInfo) > maxSignedRevInfoLen {
+		return serrors.New("signed revocation too long", "if_id", ifID,
+			"len", len(signedRevInfo), "max", maxSignedRevInfoLen)
 	}
-	d.external[ifID] = conn
+	now := time.Now()
+	d.revocations.Range(func(k, v interface{}) bool {
+		if !now.Before(v.(*revocation).expiration) {
+			d.revocations.Delete(k)
+		}
+		return true
+	})
+	d.revocations.Store(ifID, &revocation{
+		expiration:    expiration,
+		signedRevInfo: append([]byte(nil), signedRevInfo...),
+	})
 	return nil
 }
 
-// AddExternalInterfaceBFD adds the inter AS connection BFD session.
-func (d *DataPlane) AddExternalInterfaceBFD(ifID uint16, conn BatchConn) error {
-	d.mtx.Lock()
-	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
+// DelRevocation removes the revocation for the given interface, if any. This
+// can be called on a running dataplane.
+func (d *DataPlane) DelRevocation(ifID uint16) {
+	d.revocations.Delete(ifID)
+}
+
+// revocation returns the revocation of the given interface, or nil if the
+// interface is not revoked. Expired revocations are ignored, they are removed by
+// the control plane.
+func (d *DataPlane) revocation(ifID uint16) *revocation {
+	v, ok := d.revocations.Load(ifID)
+	if !ok {
+		return nil
 	}
-	if conn == nil {
-		return emptyValue
+	rev := v.(*revocation)
+	if !time.Now().Before(rev.expiration) {
+		return nil
 	}
+	return rev
+}
 
-	s := &bfdSend{
-		conn: conn,
-		addr: nil,
-	}
-	return d.addBFDController(ifID, s)
+// AddExternalInterfaceBFD adds the inter AS connection BFD session. This can be
+// called on a running dataplane.
+func (d *DataPlane) AddExternalInterfaceBFD(ifID uint16, conn BatchConn) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddExternalInterfaceBFD(ifID, conn)
+	})
 }
 
-func (d *DataPlane) addBFDController(ifID uint16, s *bfdSend) error {
-	// TODO(karampok). add extra argument as BFD params{} to set the timers.
-	// TODO(karampok). make the local discriminator random.
+// AddSvc adds the address for the given SVC. This can be called multiple times
+// for the same service, with the address added to the list of addresses that
+// provide the service. This can be called on a running dataplane.
+func (d *DataPlane) AddSvc(svc addr.HostSVC, a net.Addr) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddSvc(svc, a)
+	})
+}
 
-	if d.bfdSessions == nil {
-		d.bfdSessions = make(map[uint16]bfdSession)
-	}
+// DelSvc removes the address for the given SVC. This can be called on a
+// running dataplane.
+func (d *DataPlane) DelSvc(svc addr.HostSVC, a net.Addr) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.DelSvc(svc, a)
+	})
+}
 
-	// Generate random discriminator. It can't be zero.
-	discInt, err := rand.Int(rand.Reader, big.NewInt(0xfffffffe))
-	if err != nil {
-		return err
-	}
-	disc := layers.BFDDiscriminator(uint32(discInt.Uint64()) + 1)
+// AddNextHop sets the next hop address for the given interface ID. If the
+// interface ID already has an address associated this operation fails. This can
+// be called on a running dataplane.
+func (d *DataPlane) AddNextHop(ifID uint16, a net.Addr) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddNextHop(ifID, a)
+	})
+}
 
-	d.bfdSessions[ifID] = &bfd.Session{
-		Sender:                s,
-		DetectMult:            3,
-		Logger:                log.New("component", "BFD"),
-		DesiredMinTxInterval:  1 * time.Millisecond,
-		RequiredMinRxInterval: 25 * time.Millisecond,
-		LocalDiscriminator:    disc,
-		ReceiveQueueSize:      10,
-	}
+// DelNextHop removes the next hop address for the given interface ID, together
+// with its BFD session. This can be called on a running dataplane.
+func (d *DataPlane) DelNextHop(ifID uint16) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.DelNextHop(ifID)
+	})
+}
 
-	return nil
+// AddNextHopBFD adds the BFD session for the next hop address.
+// If the remote ifID belongs to an existing address, the existing
+// BFD session will be re-used. This can be called on a running dataplane.
+func (d *DataPlane) AddNextHopBFD(ifID uint16, a net.Addr) error {
+	return d.reconfigure(func(r *Reconfiguration) error {
+		return r.AddNextHopBFD(ifID, a)
+	})
 }
 
-// AddSvc adds the address for the given SVC. This can be called multiple times
-// for the same service, with the address added to the list of addresses that
-// provide the service. This can only be called on a not yet running dataplane.
-func (d *DataPlane) AddSvc(svc addr.HostSVC, a net.Addr) error {
-	d.mtx.Lock()
-	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
+// reconfigure applies the changes done by fn in a single reconfiguration.
+func (d *DataPlane) reconfigure(fn func(r *Reconfiguration) error) error {
+	r := d.BeginReconfiguration()
+	if err := fn(r); err != nil {
+		r.Abort()
+		return err
 	}
-	if a == nil {
-		return emptyValue
+	return r.Commit()
+}
+
+// stopped returns whether the dataplane is shutting down.
+func (d *DataPlane) stopped() bool {
+	select {
+	case <-d.stop:
+		return true
+	default:
+		return false
 	}
-	if d.svc == nil {
-		d.svc = make(map[addr.HostSVC][]net.Addr)
+}
+
+// forwarding returns the forwarding state that is currently installed.
+func (d *DataPlane) forwarding() *forwardingState {
+	if s, ok := d.state.Load().(*forwardingState); ok {
+		return s
 	}
-	d.svc[svc] = append(d.svc[svc], a)
-	return nil
+	return &forwardingState{}
 }
 
-// AddNextHop sets the next hop address for the given interface ID. If the
-// interface ID already has an address associated this operation fails. This can
-// only be called on a not yet running dataplane.
-func (d *DataPlane) AddNextHop(ifID uint16, a net.Addr) error {
+// Run starts running the dataplane. Note that only the configuration that is
+// part of a reconfiguration can be changed after calling this method.
+//
+// Run blocks until the context is canceled, then shuts the dataplane down: The
+// batches that are being processed are forwarded, the BFD sessions are closed,
+// and all connections are closed. Run returns once all goroutines of the
+// dataplane have exited. A dataplane that was shut down can not be run again.
+func (d *DataPlane) Run(ctx context.Context) error {
 	d.mtx.Lock()
-	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
+	if d.running || d.stop != nil {
+		d.mtx.Unlock()
+		return serrors.New("dataplane already started")
 	}
-	if a == nil {
-		return emptyValue
+	d.running = true
+	d.stop = make(chan struct{})
+
+	d.metrics = d.Metrics
+	if d.metrics == nil {
+		d.metrics = newMetrics(promauto.With(nil))
 	}
-	if _, exists := d.internalNextHops[ifID]; exists {
-		return serrors.WithCtx(alreadySet, "ifID", ifID)
+	d.iaPolicer = newIAPolicer(d.Policing.SourceIA)
+	s := d.forwarding().copy()
+	d.initMetrics(s)
+	d.state.Store(s)
+
+	for k, v := range s.bfdSessions {
+		d.runBFDSession(k, v)
 	}
-	if d.internalNextHops == nil {
-		d.internalNextHops = make(map[uint16]net.Addr)
+	for ifID, v := range s.external {
+		d.runReader(ifID, v)
 	}
-	d.internalNextHops[ifID] = a
+	d.runReader(0, d.internal)
+	d.mtx.Unlock()
+
+	<-ctx.Done()
+	d.shutdown()
 	return nil
 }
 
-// AddNextHopBFD adds the BFD session for the next hop address.
-// If the remote ifID belongs to an existing address, the existing
-// BFD session will be re-used.
-func (d *DataPlane) AddNextHopBFD(ifID uint16, a net.Addr) error {
+// readDeadliner is implemented by connections whose blocking reads can be
+// interrupted without closing the connection.
+type readDeadliner interface {
+	SetReadDeadline(time.Time) error
+}
+
+// shutdown stops all goroutines of the running dataplane and closes all BFD
+// sessions and connections. The readers are stopped first, so that they can
+// still forward the batch they are processing. Connections that do not support
+// read deadlines are closed right away to unblock their readers.
+func (d *DataPlane) shutdown() {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
-	if d.running {
-		return modifyExisting
-	}
 
-	if a == nil {
-		return emptyValue
-	}
+	d.running = false
+	close(d.stop)
 
-	for k, v := range d.internalNextHops {
-		if v.String() == a.String() {
-			if c, ok := d.bfdSessions[k]; ok {
-				d.bfdSessions[ifID] = c
-				return nil
+	s := d.forwarding()
+	conns := make(map[uint16]BatchConn, len(s.external)+1)
+	for ifID, c := range s.external {
+		conns[ifID] = c
+	}
+	conns[0] = d.internal
+	for ifID, c := range conns {
+		if dl, ok := c.(readDeadliner); ok {
+			if err := dl.SetReadDeadline(time.Now()); err == nil {
+				continue
 			}
 		}
+		if err := c.Close(); err != nil {
+			log.Info("Closing interface", "if_id", ifID, "err", err)
+		}
+		delete(conns, ifID)
 	}
+	d.readers.Wait()
 
-	s := &bfdSend{
-		conn: d.internal,
-		addr: a,
+	for ifID, c := range s.bfdSessions {
+		if err := c.Close(); err != nil {
+			log.Info("Closing BFD session", "if_id", ifID, "err", err)
+		}
 	}
+	d.bfdRunners.Wait()
 
-	return d.addBFDController(ifID, s)
+	for ifID, c := range conns {
+		if err := c.Close(); err != nil {
+			log.Info("Closing interface", "if_id", ifID, "err", err)
+		}
+	}
 }
 
-// Run starts running the dataplane. Note that configuration is not possible
-// after calling this method.
-func (d *DataPlane) Run() error {
-	d.mtx.Lock()
-	d.running = true
+// runReader starts the goroutine that reads and processes the packets from the
+// given connection.
+func (d *DataPlane) runReader(ingressID uint16, c BatchConn) {
+	d.readers.Add(1)
+	go func() {
+		defer log.HandlePanic()
+		defer d.readers.Done()
+		d.read(ingressID, c)
+	}()
+}
 
-	read := func(ingressID uint16, rd BatchConn) {
-		msgs := conn.NewReadMessages(inputBatchCnt)
-		for _, msg := range msgs {
-			msg.Buffers[0] = make([]byte, bufSize)
+// runBFDSession starts the goroutine that runs the given BFD session.
+func (d *DataPlane) runBFDSession(ifID uint16, c bfdSession) {
+	d.bfdRunners.Add(1)
+	go func() {
+		defer log.HandlePanic()
+		defer d.bfdRunners.Done()
+		if err := c.Run(); err != nil && err != bfd.AlreadyRunning {
+			log.Error("BFD session failed to start", "ifID", ifID, "err", err)
 		}
+	}()
+}
 
-		var scmpErr scmpError
-		metas := make([]conn.ReadMeta, inputBatchCnt)
-		spkt := slayers.SCION{}
-		buffer := gopacket.NewSerializeBuffer()
-		origPacket := make([]byte, bufSize)
-		for d.running {
-			pkts, err := rd.ReadBatch(msgs, metas)
-			if err != nil {
-				log.Debug("Failed to read batch", "err", err)
-				// error metric
+// read reads and processes the packets from the given connection. It returns
+// once the connection is no longer part of the forwarding state, or the
+// dataplane shuts down.
+func (d *DataPlane) read(ingressID uint16, rd BatchConn) {
+	inputMetrics := newInterfaceMetrics(d.metrics, ingressID, d.localIA,
+		d.forwarding().neighborIA(ingressID, d.localIA))
+	var lastRcvOvfl uint32
+	policer := d.newIngressPolicer(ingressID)
+	msgs := conn.NewReadMessages(inputBatchCnt)
+	for _, msg := range msgs {
+		msg.Buffers[0] = make([]byte, bufSize)
+	}
+
+	var scmpErr scmpError
+	metas := make([]conn.ReadMeta, inputBatchCnt)
+	spkt := slayers.SCION{}
+	buffer := gopacket.NewSerializeBuffer()
+	origPacket := make([]byte, bufSize)
+	for !d.stopped() {
+		pkts, err := rd.ReadBatch(msgs, metas)
+		if err != nil {
+			if d.stopped() {
+				return
+			}
+			if ingressID != 0 && d.forwarding().external[ingressID] != rd {
+				// The interface was removed and its connection closed.
+				return
+			}
+			log.Debug("Failed to read batch", "err", err)
+			inputMetrics.InputErrorsTotal.Inc()
+			continue
+		}
+		if pkts == 0 {
+			continue
+		}
+		// RcvOvfl is the total number of packets the kernel dropped on the
+		// socket so far, only the increase is added to the counter.
+		if rcvOvfl := metas[pkts-1].RcvOvfl; rcvOvfl > lastRcvOvfl {
+			inputMetrics.InputDroppedPacketsTotal.Add(float64(rcvOvfl - lastRcvOvfl))
+			lastRcvOvfl = rcvOvfl
+		}
+		caps := d.captures()
+		for _, p := range msgs[:pkts] {
+			inputMetrics.InputPacketsTotal.Inc()
+			inputMetrics.InputBytesTotal.Add(float64(p.N))
+
+			origPacket = origPacket[:p.N]
+			// TODO(karampok). Use meta for sanity checks.
+			p.Buffers[0] = p.Buffers[0][:p.N]
+			copy(origPacket[:p.N], p.Buffers[0])
+			if c, ok := caps[ingressID]; ok {
+				c.add(origPacket, false)
+			}
+			wr, err := d.processPkt(ingressID, &p, spkt, origPacket, buffer, policer)
+			switch {
+			case err == nil:
+			case errors.As(err, &scmpErr):
+				if !scmpErr.TypeCode.InfoMsg() {
+					log.Debug("SCMP", "err", scmpErr, "dst_addr", p.Addr)
+					inputMetrics.DroppedPacketsTotal[dropReason(err)].Inc()
+				}
+				// SCMP go back the way they came.
+				wr = rd
+			default:
+				log.Debug("Error processing packet", "err", err)
+				inputMetrics.DroppedPacketsTotal[dropReason(err)].Inc()
 				continue
 			}
-			if pkts == 0 {
+			if wr == nil { // e.g. BFD case no message is forwarded
 				continue
 			}
-			for _, p := range msgs[:pkts] {
-				origPacket = origPacket[:p.N]
-				// TODO(karampok). Use meta for sanity checks.
-				p.Buffers[0] = p.Buffers[0][:p.N]
-				copy(origPacket[:p.N], p.Buffers[0])
-				wr, err := d.processPkt(ingressID, &p, spkt, origPacket, buffer)
-				switch {
-				case err == nil:
-				case errors.As(err, &scmpErr):
-					if !scmpErr.TypeCode.InfoMsg() {
-						log.Debug("SCMP", "err", scmpErr, "dst_addr", p.Addr)
-					}
-					// SCMP go back the way they came.
-					wr = rd
-					// SCMP metric?
-				default:
-					log.Debug("Error processing packet", "err", err)
-					// error metric
-					continue
+			if len(caps) > 0 {
+				if c, ok := caps[d.forwarding().interfaceID(wr)]; ok {
+					c.add(p.Buffers[0], true)
 				}
-				if wr == nil { // e.g. BFD case no message is forwarded
-					continue
-				}
-				_, err = wr.WriteBatch(underlayconn.Messages([]ipv4.Message{p}))
-				if err != nil {
-					log.Debug("Error writing packet", "err", err)
-					// error metric
-					continue
-				}
-				// ok metric
 			}
-
-			// Reset buffers to original capacity.
-			for _, p := range msgs[:pkts] {
-				p.Buffers[0] = p.Buffers[0][:bufSize]
+			outputMetrics := d.forwarding().outputMetrics(wr, d.internal)
+			// More than one packet is written if the packet is fanned out to
+			// multiple destinations.
+			n, err := wr.WriteBatch(underlayconn.Messages([]ipv4.Message{p}))
+			if err != nil {
+				log.Debug("Error writing packet", "err", err)
+				outputMetrics.OutputErrorsTotal.Inc()
+				continue
 			}
+			outputMetrics.OutputPacketsTotal.Add(float64(n))
+			outputMetrics.OutputBytesTotal.Add(float64(n * len(p.Buffers[0])))
 		}
-	}
 
-	for k, v := range d.bfdSessions {
-		go func(ifID uint16, c bfdSession) {
-			defer log.HandlePanic()
-			if err := c.Run(); err != nil && err != bfd.AlreadyRunning {
-				log.Error("BFD session failed to start", "ifID", ifID, "err", err)
-			}
-		}(k, v)
+		// Reset buffers to original capacity.
+		for _, p := range msgs[:pkts] {
+			p.Buffers[0] = p.Buffers[0][:bufSize]
+		}
 	}
-	for ifID, v := range d.external {
-		go func(i uint16, c BatchConn) {
-			defer log.HandlePanic()
-			read(i, c)
-		}(ifID, v)
+}
+
+// initMetrics resolves the metrics of all interfaces of the given forwarding
+// state. It must only be called after d.metrics is set.
+func (d *DataPlane) initMetrics(s *forwardingState) {
+	s.metrics = make(map[BatchConn]interfaceMetrics, len(s.external)+1)
+	s.metrics[d.internal] = newInterfaceMetrics(d.metrics, 0, d.localIA, d.localIA)
+	for ifID, c := range s.external {
+		s.metrics[c] = newInterfaceMetrics(d.metrics, ifID, d.localIA,
+			s.neighborIA(ifID, d.localIA))
 	}
-	go func(c BatchConn) {
-		defer log.HandlePanic()
-		read(0, c)
-	}(d.internal)
+}
 
-	d.mtx.Unlock()
-	for d.running {
-		time.Sleep(time.Second)
+// dropReason returns the reason label for a packet that was dropped because
+// processing returned the given error.
+func dropReason(err error) string {
+	var scmpErr scmpError
+	isSCMP := errors.As(err, &scmpErr)
+	if isSCMP {
+		err = scmpErr.Cause
+	}
+	switch {
+	case errors.Is(err, invalidMAC):
+		return dropReasonInvalidMAC
+	case errors.Is(err, expiredHop):
+		return dropReasonExpiredHop
+	case errors.Is(err, bfdSessionDown):
+		return dropReasonBFDDown
+	case errors.Is(err, interfaceRevoked):
+		return dropReasonRevokedInterface
+	case errors.Is(err, policedInterface):
+		return dropReasonPolicedInterface
+	case errors.Is(err, policedSourceIA):
+		return dropReasonPolicedSourceIA
+	case errors.Is(err, invalidPath):
+		return dropReasonInvalidPath
+	case errors.Is(err, invalidSrcAddr), errors.Is(err, invalidDstAddr):
+		return dropReasonInvalidAddress
+	case errors.Is(err, invalidSrcIA):
+		return dropReasonInvalidSourceIA
+	case errors.Is(err, scmpRateLimited):
+		return dropReasonSCMPRateLimited
+	case isSCMP && errors.Is(err, cannotRoute):
+		return dropReasonUnknownEgress
+	case isSCMP:
+		return dropReasonSCMPGenerated
+	default:
+		return dropReasonProcessingError
 	}
-	return nil
 }
 
 func (d *DataPlane) processPkt(ingressID uint16, m *ipv4.Message, s slayers.SCION,
-	origPacket []byte, buffer gopacket.SerializeBuffer) (BatchConn, error) {
+	origPacket []byte, buffer gopacket.SerializeBuffer,
+	policer *ingressPolicer) (BatchConn, error) {
 
 	defer func() {
 		// zero out the fields for sending:
@@ -416,8 +679,9 @@ func (d *DataPlane) processPkt(ingressID uint16, m *ipv4.Message, s slayers.SCIO
 		m.OOB = nil
 	}()
 
+	fwd := d.forwarding()
 	if err := s.DecodeFromBytes(m.Buffers[0], gopacket.NilDecodeFeedback); err != nil {
-		return nil, err
+		return nil, d.decodeError(ingressID, m, s, origPacket, buffer, policer, err)
 	}
 	if err := buffer.Clear(); err != nil {
 		return nil, serrors.WrapStr("Failed to clear buffer", err)
@@ -426,21 +690,23 @@ func (d *DataPlane) processPkt(ingressID uint16, m *ipv4.Message, s slayers.SCIO
 	switch s.PathType {
 	case slayers.PathTypeEmpty:
 		if s.NextHdr == common.L4BFD {
-			return nil, d.processBFD(ingressID, m.Addr, s.Payload)
+			return nil, d.processBFD(fwd, ingressID, m.Addr, s.Payload)
 		}
 		return nil, serrors.WithCtx(unsupportedPathTypeNextHeader,
 			"type", s.PathType, "header", s.NextHdr)
 	case slayers.PathTypeOneHop:
-		return d.processOHP(ingressID, m, s, buffer)
+		return d.processOHP(fwd, ingressID, m, s, origPacket, buffer, policer)
 	case slayers.PathTypeSCION:
-		return d.processSCION(ingressID, m, s, origPacket, buffer)
+		return d.processSCION(fwd, ingressID, m, s, origPacket, buffer, policer)
 	default:
 		return nil, serrors.WithCtx(unsupportedPathType, "type", s.PathType)
 	}
 }
 
-func (d *DataPlane) processBFD(ingressID uint16, a net.Addr, data []byte) error {
-	if len(d.bfdSessions) == 0 {
+func (d *DataPlane) processBFD(fwd *forwardingState, ingressID uint16, a net.Addr,
+	data []byte) error {
+
+	if len(fwd.bfdSessions) == 0 {
 		return noBFDSessionConfigured
 	}
 	p := &layers.BFD{}
@@ -451,23 +717,44 @@ func (d *DataPlane) processBFD(ingressID uint16, a net.Addr, data []byte) error
 		return serrors.New("cannot receive packet without source address on internal interface")
 	}
 	if a != nil {
-		for k, v := range d.internalNextHops {
-			if v.String() == a.String() {
+		for k, v := range fwd.internalNextHops {
+			if sameUnderlayAddr(v, a) {
 				ingressID = k
 				continue
 			}
 		}
 	}
-	if v, ok := d.bfdSessions[ingressID]; ok {
-		v.Messages() <- p
+	if v, ok := fwd.bfdSessions[ingressID]; ok {
+		v.ReceiveMessage(p)
 		return nil
 	}
 	return noBFDSessionFound
 }
 
-func (d *DataPlane) processSCION(ingressID uint16, m *ipv4.Message, s slayers.SCION,
-	origPacket []byte, buffer gopacket.SerializeBuffer) (BatchConn, error) {
+// decodeError handles a packet that can not be decoded. The segment lengths of
+// a SCION path are checked while decoding the path, a packet whose segment
+// lengths have holes is answered like the other invalid path meta headers, see
+// validatePathMeta. All other packets are dropped.
+func (d *DataPlane) decodeError(ingressID uint16, m *ipv4.Message, s slayers.SCION,
+	origPacket []byte, buffer gopacket.SerializeBuffer, policer *ingressPolicer,
+	err error) error {
 
+	if s.PathType != slayers.PathTypeSCION {
+		return err
+	}
+	// The path is only decoded if the common and the address header are valid
+	// and the packet contains the whole header.
+	data := m.Buffers[0]
+	offset := slayers.CmnHdrLen + s.AddrHdrLen()
+	hdrLen := int(s.HdrLen) * slayers.LineLen
+	if hdrLen < offset+scion.MetaLen || len(data) < hdrLen {
+		return err
+	}
+	var base scion.Base
+	if base.DecodeFromBytes(data[offset:hdrLen]) == nil {
+		return err
+	}
+	s.Payload = data[hdrLen:]
 	p := scionPacketProcessor{
 		d:          d,
 		ingressID:  ingressID,
@@ -475,6 +762,25 @@ func (d *DataPlane) processSCION(ingressID uint16, m *ipv4.Message, s slayers.SC
 		scionLayer: s,
 		origPacket: origPacket,
 		buffer:     buffer,
+		policer:    policer,
+	}
+	return p.localParamProblem(slayers.SCMPCodeInvalidPath, p.pathMetaPointer(),
+		serrors.Wrap(invalidPath, err, "details", "invalid segment lengths"))
+}
+
+func (d *DataPlane) processSCION(fwd *forwardingState, ingressID uint16, m *ipv4.Message,
+	s slayers.SCION, origPacket []byte, buffer gopacket.SerializeBuffer,
+	policer *ingressPolicer) (BatchConn, error) {
+
+	p := scionPacketProcessor{
+		d:          d,
+		fwd:        fwd,
+		ingressID:  ingressID,
+		m:          m,
+		scionLayer: s,
+		origPacket: origPacket,
+		buffer:     buffer,
+		policer:    policer,
 	}
 	return p.process()
 }
@@ -482,6 +788,8 @@ func (d *DataPlane) processSCION(ingressID uint16, m *ipv4.Message, s slayers.SC
 type scionPacketProcessor struct {
 	// d is a reference to the dataplane instance that initiated this processor.
 	d *DataPlane
+	// fwd is the forwarding state that is used to process this packet.
+	fwd *forwardingState
 	// ingressID is the interface ID this packet came in, determined from the
 	// socket.
 	ingressID uint16
@@ -494,6 +802,9 @@ type scionPacketProcessor struct {
 	origPacket []byte
 	// buffer is the buffer that can be used to serialize gopacket layers.
 	buffer gopacket.SerializeBuffer
+	// policer polices the packets of the ingress interface. If it is nil, no
+	// limits are applied.
+	policer *ingressPolicer
 
 	// path is the raw SCION path. Will be set during processing.
 	path *scion.Raw
@@ -501,11 +812,20 @@ type scionPacketProcessor struct {
 	hopField *path.HopField
 	// infoField is the current infoField field, is updated during processing.
 	infoField *path.InfoField
+	// peering indicates whether the current hop field is a peering hop field.
+	// It is set during path parsing.
+	peering bool
 }
 
 func (p *scionPacketProcessor) packSCMP(scmpH *slayers.SCMP, scmpP gopacket.SerializableLayer,
 	cause error) error {
 
+	// SCMP messages are rate limited, so that the router can not be used to
+	// amplify traffic.
+	if !p.policer.allowSCMP() {
+		return serrors.WrapStr("not sending SCMP", scmpRateLimited, "cause", cause)
+	}
+
 	// parse everything to see if the original packet was an SCMP error.
 	var (
 		scionLayer slayers.SCION
@@ -552,7 +872,7 @@ func (p *scionPacketProcessor) packSCMP(scmpH *slayers.SCMP, scmpP gopacket.Seri
 	copy(quote[:len(updated)], updated)
 	copy(quote[len(updated):], p.origPacket[len(updated):quoteLen])
 
-	_, external := p.d.external[p.ingressID]
+	_, external := p.fwd.external[p.ingressID]
 	rawSCMP, err := scmpPacker{
 		internalIP: p.d.internalIP,
 		localIA:    p.d.localIA,
@@ -578,20 +898,29 @@ func (p *scionPacketProcessor) parsePath() error {
 	var ok bool
 	p.path, ok = p.scionLayer.Path.(*scion.Raw)
 	if !ok {
-		// TODO(lukedirtwalker) parameter problem invalid path?
+		// The path type is checked before the packet is handed to the
+		// processor, so this can not happen for a valid packet.
 		return malformedPath
 	}
+	if err := p.validatePathMeta(); err != nil {
+		return err
+	}
 	var err error
 	p.hopField, err = p.path.GetCurrentHopField()
 	if err != nil {
-		// TODO(lukedirtwalker) parameter problem invalid path?
-		return err
+		return serrors.Wrap(invalidPath, err)
 	}
 	p.infoField, err = p.path.GetCurrentInfoField()
 	if err != nil {
-		// TODO(lukedirtwalker) parameter problem invalid path?
+		return serrors.Wrap(invalidPath, err)
+	}
+	if err := p.validateCurrINF(); err != nil {
 		return err
 	}
+	if err := p.validatePeering(); err != nil {
+		return err
+	}
+	p.peering = isPeeringHop(p.path.PathMeta, p.infoField)
 	if err := p.validateHopExpiry(); err != nil {
 		return err
 	}
@@ -601,6 +930,162 @@ func (p *scionPacketProcessor) parsePath() error {
 	return nil
 }
 
+// validatePathMeta checks that the segment lengths describe a path of at most
+// scion.MaxHops hop fields and that the current info and hop field indices are
+// within the path. An SCMP error for such a path can not be sent on the
+// reversed path, which can not be derived without a valid current hop field.
+// The error is therefore only sent to hosts in the local AS, see
+// localParamProblem. Segment lengths with holes are already rejected when the
+// path is decoded, see decodeError.
+func (p *scionPacketProcessor) validatePathMeta() error {
+	meta := p.path.PathMeta
+	var err error
+	switch {
+	case p.path.NumINF == 0:
+		err = serrors.WithCtx(invalidPath, "details", "empty path")
+	case p.path.NumHops > scion.MaxHops:
+		err = serrors.WithCtx(invalidPath, "details", "too many hop fields",
+			"seg_len", meta.SegLen, "max", scion.MaxHops)
+	case int(meta.CurrINF) >= p.path.NumINF:
+		err = serrors.WithCtx(invalidPath, "details", "current info field out of range",
+			"curr_inf", meta.CurrINF, "num_inf", p.path.NumINF)
+	case int(meta.CurrHF) >= p.path.NumHops:
+		err = serrors.WithCtx(invalidPath, "details", "current hop field out of range",
+			"curr_hf", meta.CurrHF, "num_hops", p.path.NumHops)
+	default:
+		return nil
+	}
+	return p.localParamProblem(slayers.SCMPCodeInvalidPath, p.pathMetaPointer(), err)
+}
+
+// validateCurrINF checks that the current info field is the info field of the
+// segment the current hop field belongs to.
+func (p *scionPacketProcessor) validateCurrINF() error {
+	meta := p.path.PathMeta
+	if expected := infIndexForHF(meta, meta.CurrHF); meta.CurrINF != expected {
+		return p.packSCMP(
+			&slayers.SCMP{TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodeInvalidPath),
+			},
+			&slayers.SCMPParameterProblem{Pointer: p.pathMetaPointer()},
+			serrors.WithCtx(invalidPath, "details", "current info field does not match hop field",
+				"curr_inf", meta.CurrINF, "curr_hf", meta.CurrHF, "expected_inf", expected),
+		)
+	}
+	return nil
+}
+
+// validatePeering checks the peering flags of the path. A path over a peering
+// link consists of exactly two segments, which both have the peering flag set.
+func (p *scionPacketProcessor) validatePeering() error {
+	if !p.infoField.Peer {
+		return nil
+	}
+	valid := p.path.NumINF == 2
+	if valid {
+		other, err := p.path.GetInfoField(1 - int(p.path.PathMeta.CurrINF))
+		if err != nil {
+			return serrors.Wrap(invalidPath, err)
+		}
+		valid = other.Peer
+	}
+	if valid {
+		return nil
+	}
+	return p.packSCMP(
+		&slayers.SCMP{TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+			slayers.SCMPCodeInvalidPath),
+		},
+		&slayers.SCMPParameterProblem{Pointer: p.currentInfoPointer()},
+		serrors.WithCtx(invalidPath, "details", "inconsistent peering flags",
+			"curr_inf", p.path.PathMeta.CurrINF, "num_inf", p.path.NumINF),
+	)
+}
+
+// isPeeringHop returns whether the current hop field of a path with the given
+// meta header and current info field is a peering hop field. In a peering path
+// these are the last hop field of the first segment and the first hop field of
+// the second segment. The MAC of a peering hop field is chained to the same
+// SegID as the hop field of the child AS on the same segment, so the SegID is
+// not updated when a peering hop field is processed.
+func isPeeringHop(meta scion.MetaHdr, inf *path.InfoField) bool {
+	if !inf.Peer {
+		return false
+	}
+	return meta.CurrHF+1 == meta.SegLen[0] || meta.CurrHF == meta.SegLen[0]
+}
+
+// infIndexForHF returns the index of the info field of the segment the given
+// hop field belongs to.
+func infIndexForHF(meta scion.MetaHdr, hf uint8) uint8 {
+	var end uint8
+	for i, segLen := range meta.SegLen {
+		end += segLen
+		if hf < end {
+			return uint8(i)
+		}
+	}
+	return uint8(len(meta.SegLen) - 1)
+}
+
+// validateSrcAddr checks the type and length of the source host address. The
+// SCMP error for an invalid source address can not be addressed to a sender in
+// a remote AS. It is therefore only sent to hosts in the local AS, which are
+// addressed with their underlay address, see localParamProblem.
+func (p *scionPacketProcessor) validateSrcAddr() error {
+	if _, err := p.scionLayer.SrcAddr(); err != nil {
+		return p.localParamProblem(slayers.SCMPCodeInvalidSourceAddress, addrTypePointer,
+			serrors.Wrap(invalidSrcAddr, err))
+	}
+	return nil
+}
+
+// localParamProblem answers a packet that can not be answered on its reversed
+// path with an SCMP parameter problem. The error is only sent if the packet was
+// sent by a host in the local AS, see localSCMP. Otherwise the packet is
+// dropped.
+func (p *scionPacketProcessor) localParamProblem(code slayers.SCMPCode, pointer uint16,
+	cause error) error {
+
+	if p.ingressID != 0 || !p.scionLayer.SrcIA.Equal(p.d.localIA) {
+		return cause
+	}
+	return p.d.localSCMP(p.m, p.scionLayer, p.origPacket, p.buffer, p.policer,
+		&slayers.SCMP{
+			TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+		},
+		&slayers.SCMPParameterProblem{Pointer: pointer},
+		cause,
+	)
+}
+
+// validateSrcIA checks the source ISD-AS of packets from a child AS. Such a
+// packet must originate in the local ISD. If it traversed a single hop so far,
+// i.e. it was sent by a host in the child AS, the source must be the child AS.
+func (p *scionPacketProcessor) validateSrcIA() error {
+	if p.fwd.linkTypes[p.ingressID] != topology.Child {
+		return nil
+	}
+	srcIA := p.scionLayer.SrcIA
+	valid := srcIA.I == p.d.localIA.I
+	meta := p.path.PathMeta
+	if neighbor, ok := p.fwd.neighborIAs[p.ingressID]; ok && valid &&
+		meta.CurrINF == 0 && meta.CurrHF == 1 {
+
+		valid = srcIA.Equal(neighbor)
+	}
+	if valid {
+		return nil
+	}
+	return p.packSCMP(
+		&slayers.SCMP{TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+			slayers.SCMPCodeInvalidSourceAddress),
+		},
+		&slayers.SCMPParameterProblem{Pointer: srcIAPointer},
+		serrors.WithCtx(invalidSrcIA, "src_isd_as", srcIA, "if_id", p.ingressID),
+	)
+}
+
 func (p *scionPacketProcessor) validateHopExpiry() error {
 	expiration := util.SecsToTime(p.infoField.Timestamp).
 		Add(spath.ExpTimeType(p.hopField.ExpTime).ToDuration())
@@ -613,7 +1098,7 @@ func (p *scionPacketProcessor) validateHopExpiry() error {
 			slayers.SCMPCodePathExpired),
 		},
 		&slayers.SCMPParameterProblem{Pointer: p.currentHopPointer()},
-		serrors.New("expired hop", "cons_dir", p.infoField.ConsDir, "if_id", p.ingressID,
+		serrors.WithCtx(expiredHop, "cons_dir", p.infoField.ConsDir, "if_id", p.ingressID,
 			"curr_inf", p.path.PathMeta.CurrINF, "curr_hf", p.path.PathMeta.CurrHF),
 	)
 }
@@ -640,8 +1125,8 @@ func (p *scionPacketProcessor) validateIngressID() error {
 
 func (p *scionPacketProcessor) validateEgressID() error {
 	pktEgressID := p.egressInterface()
-	_, ih := p.d.internalNextHops[pktEgressID]
-	_, eh := p.d.external[pktEgressID]
+	_, ih := p.fwd.internalNextHops[pktEgressID]
+	_, eh := p.fwd.external[pktEgressID]
 	if !ih && !eh {
 		errCode := slayers.SCMPCodeUnknownHopFieldEgress
 		if !p.infoField.ConsDir {
@@ -660,10 +1145,10 @@ func (p *scionPacketProcessor) validateEgressID() error {
 
 func (p *scionPacketProcessor) updateNonConsDirIngressSegID() error {
 	// against construction dir the ingress router updates the SegID, ifID == 0
-	// means this comes from this AS itself, so nothing has to be done.
-	// TODO(lukedirtwalker): For packets destined to peer links this shouldn't
-	// be updated.
-	if !p.infoField.ConsDir && p.ingressID != 0 {
+	// means this comes from this AS itself, so nothing has to be done. The
+	// SegID of a peering hop field is the one of the child hop field, so it is
+	// not updated either.
+	if !p.infoField.ConsDir && p.ingressID != 0 && !p.peering {
 		p.infoField.UpdateSegID(p.hopField.Mac)
 		if err := p.path.SetInfoField(p.infoField, int(p.path.PathMeta.CurrINF)); err != nil {
 			return serrors.WrapStr("update info field", err)
@@ -675,19 +1160,28 @@ func (p *scionPacketProcessor) updateNonConsDirIngressSegID() error {
 	return nil
 }
 
+func (p *scionPacketProcessor) pathMetaPointer() uint16 {
+	return uint16(slayers.CmnHdrLen + p.scionLayer.AddrHdrLen())
+}
+
+func (p *scionPacketProcessor) currentInfoPointer() uint16 {
+	return uint16(slayers.CmnHdrLen + p.scionLayer.AddrHdrLen() +
+		scion.MetaLen + path.InfoLen*int(p.path.PathMeta.CurrINF))
+}
+
 func (p *scionPacketProcessor) currentHopPointer() uint16 {
 	return uint16(slayers.CmnHdrLen + p.scionLayer.AddrHdrLen() +
 		scion.MetaLen + path.InfoLen*p.path.NumINF + path.HopLen*int(p.path.PathMeta.CurrHF))
 }
 
 func (p *scionPacketProcessor) verifyCurrentMAC() error {
-	if err := path.VerifyMAC(p.d.macFactory(), p.infoField, p.hopField); err != nil {
+	if err := p.fwd.verifyMAC(p.infoField, p.hopField); err != nil {
 		return p.packSCMP(
 			&slayers.SCMP{TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
 				slayers.SCMPCodeInvalidHopFieldMAC),
 			},
 			&slayers.SCMPParameterProblem{Pointer: p.currentHopPointer()},
-			serrors.WithCtx(err, "cons_dir", p.infoField.ConsDir, "if_id", p.ingressID,
+			serrors.Wrap(invalidMAC, err, "cons_dir", p.infoField.ConsDir, "if_id", p.ingressID,
 				"curr_inf", p.path.PathMeta.CurrINF, "curr_hf", p.path.PathMeta.CurrHF,
 				"seg_id", p.infoField.SegID),
 		)
@@ -695,35 +1189,62 @@ func (p *scionPacketProcessor) verifyCurrentMAC() error {
 	return nil
 }
 
-func (p *scionPacketProcessor) processInbound() error {
-	var err error
-	p.m.Addr, err = p.d.resolveLocalDst(p.scionLayer)
+// police applies the admission control to the packet. Over-limit packets are
+// dropped, or answered with an SCMP destination unreachable message if the
+// policer is configured to signal them.
+func (p *scionPacketProcessor) police() error {
+	err := p.policer.admit(p.scionLayer.SrcIA)
+	if err == nil || !p.policer.signalSCMP {
+		return err
+	}
+	return p.packSCMP(
+		&slayers.SCMP{
+			TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeDestinationUnreachable,
+				slayers.SCMPCodeAdminDeny),
+		},
+		&slayers.SCMPDestinationUnreachable{},
+		err,
+	)
+}
+
+func (p *scionPacketProcessor) processInbound() (BatchConn, error) {
+	dsts, err := p.fwd.resolveLocalDst(p.scionLayer)
 	switch {
 	case errors.Is(err, noSVCBackend):
-		return p.packSCMP(
+		return nil, p.packSCMP(
 			&slayers.SCMP{
 				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeDestinationUnreachable,
 					slayers.SCMPCodeNoRoute),
 			},
 			&slayers.SCMPDestinationUnreachable{}, err)
-	default:
-		return nil
+	case errors.Is(err, invalidDstAddr):
+		return nil, p.packSCMP(
+			&slayers.SCMP{
+				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+					slayers.SCMPCodeInvalidDestinationAddress),
+			},
+			&slayers.SCMPParameterProblem{Pointer: addrTypePointer}, err)
+	case err != nil:
+		return nil, err
 	}
+	p.m.Addr = dsts[0]
+	return p.d.localConn(dsts), nil
 }
 
 func (p *scionPacketProcessor) processEgress() error {
 	// we are the egress router and if we go in construction direction we
-	// need to update the SegID.
-	if p.infoField.ConsDir {
+	// need to update the SegID, unless we leave over a peering hop field. The
+	// next hop field is chained to the same SegID as the peering hop field.
+	if p.infoField.ConsDir && !p.peering {
 		p.infoField.UpdateSegID(p.hopField.Mac)
 		if err := p.path.SetInfoField(p.infoField, int(p.path.PathMeta.CurrINF)); err != nil {
-			// TODO parameter problem invalid path
 			return serrors.WrapStr("update info field", err)
 		}
 	}
+	// Packets at the last hop field of the path are delivered locally, see
+	// process, so this can not fail.
 	if err := p.path.IncPath(); err != nil {
-		// TODO parameter problem invalid path
-		return serrors.WrapStr("incrementing path", err)
+		return serrors.Wrap(invalidPath, err, "details", "incrementing path")
 	}
 	if err := updateSCIONLayer(p.m, p.scionLayer, p.buffer); err != nil {
 		return err
@@ -734,18 +1255,17 @@ func (p *scionPacketProcessor) processEgress() error {
 }
 
 func (p *scionPacketProcessor) doXover() error {
+	// The current info field was validated in parsePath, so a crossover is
+	// never at the last hop field of the path.
 	if err := p.path.IncPath(); err != nil {
-		// TODO parameter problem invalid path
-		return serrors.WrapStr("incrementing path", err)
+		return serrors.Wrap(invalidPath, err, "details", "incrementing path")
 	}
 	var err error
 	if p.hopField, err = p.path.GetCurrentHopField(); err != nil {
-		// TODO parameter problem invalid path
-		return err
+		return serrors.Wrap(invalidPath, err)
 	}
 	if p.infoField, err = p.path.GetCurrentInfoField(); err != nil {
-		// TODO parameter problem invalid path
-		return err
+		return serrors.Wrap(invalidPath, err)
 	}
 	if err := updateSCIONLayer(p.m, p.scionLayer, p.buffer); err != nil {
 		return err
@@ -769,7 +1289,7 @@ func (p *scionPacketProcessor) egressInterface() uint16 {
 
 func (p *scionPacketProcessor) validateEgressUp() error {
 	egressID := p.egressInterface()
-	if v, ok := p.d.bfdSessions[egressID]; ok {
+	if v, ok := p.fwd.bfdSessions[egressID]; ok {
 		if !v.IsUp() {
 			scmpH := &slayers.SCMP{
 				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeExternalInterfaceDown, 0),
@@ -778,7 +1298,7 @@ func (p *scionPacketProcessor) validateEgressUp() error {
 				IA:   p.d.localIA,
 				IfID: uint64(egressID),
 			}
-			if _, external := p.d.external[egressID]; !external {
+			if _, external := p.fwd.external[egressID]; !external {
 				scmpH.TypeCode =
 					slayers.CreateSCMPTypeCode(slayers.SCMPTypeInternalConnectivityDown, 0)
 				scmpP = &slayers.SCMPInternalConnectivityDown{
@@ -787,12 +1307,55 @@ func (p *scionPacketProcessor) validateEgressUp() error {
 					Egress:  uint64(egressID),
 				}
 			}
-			return p.packSCMP(scmpH, scmpP, serrors.New("bfd session down"))
+			return p.packSCMP(scmpH, scmpP, bfdSessionDown)
 		}
 	}
 	return nil
 }
 
+// validateEgressNotRevoked checks that the egress interface is not revoked by
+// the control plane. Packets towards a revoked interface are answered with an
+// SCMP external interface down message that carries the signed revocation.
+func (p *scionPacketProcessor) validateEgressNotRevoked() error {
+	egressID := p.egressInterface()
+	rev := p.d.revocation(egressID)
+	if rev == nil {
+		return nil
+	}
+	return p.packSCMP(
+		&slayers.SCMP{
+			TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeExternalInterfaceDown,
+				slayers.SCMPCodeRevokedInterface),
+		},
+		&revokedInterfaceDown{
+			SCMPExternalInterfaceDown: slayers.SCMPExternalInterfaceDown{
+				IA:   p.d.localIA,
+				IfID: uint64(egressID),
+			},
+			Revocation: slayers.SCMPRevocation{SignedRevInfo: rev.signedRevInfo},
+		},
+		serrors.WithCtx(interfaceRevoked, "egress", egressID),
+	)
+}
+
+// revokedInterfaceDown is an SCMP external interface down message followed by
+// the signed revocation of the interface.
+type revokedInterfaceDown struct {
+	slayers.SCMPExternalInterfaceDown
+	Revocation slayers.SCMPRevocation
+}
+
+// SerializeTo writes the message and the revocation into the buffer,
+// implementing gopacket.SerializableLayer.
+func (r *revokedInterfaceDown) SerializeTo(b gopacket.SerializeBuffer,
+	opts gopacket.SerializeOptions) error {
+
+	if err := r.Revocation.SerializeTo(b, opts); err != nil {
+		return err
+	}
+	return r.SCMPExternalInterfaceDown.SerializeTo(b, opts)
+}
+
 func (p *scionPacketProcessor) handleIngressRouterAlert() error {
 	if p.ingressID == 0 {
 		return nil
@@ -813,7 +1376,7 @@ func (p *scionPacketProcessor) handleEgressRouterAlert() error {
 		return nil
 	}
 	egressID := p.egressInterface()
-	if _, ok := p.d.external[egressID]; !ok {
+	if _, ok := p.fwd.external[egressID]; !ok {
 		return nil
 	}
 	p.hopField.EgressRouterAlert = false
@@ -866,36 +1429,53 @@ func (p *scionPacketProcessor) validatePktLen() error {
 }
 
 func (p *scionPacketProcessor) process() (BatchConn, error) {
-
-	// TODO(karampok). Add various packet validations.
+	if err := p.validateSrcAddr(); err != nil {
+		return nil, err
+	}
 	if err := p.parsePath(); err != nil {
 		return nil, err
 	}
 	if err := p.validatePktLen(); err != nil {
 		return nil, err
 	}
+	if err := p.validateSrcIA(); err != nil {
+		return nil, err
+	}
 	if err := p.updateNonConsDirIngressSegID(); err != nil {
 		return nil, err
 	}
 	if err := p.verifyCurrentMAC(); err != nil {
 		return nil, err
 	}
+	if err := p.police(); err != nil {
+		return nil, err
+	}
 	if err := p.handleIngressRouterAlert(); err != nil {
 		return nil, err
 	}
 
 	// Inbound: pkts destined to the local IA.
-	if p.scionLayer.DstIA.Equal(p.d.localIA) && int(p.path.PathMeta.CurrHF)+1 == p.path.NumHops {
-		if err := p.processInbound(); err != nil {
-			return nil, err
+	if int(p.path.PathMeta.CurrHF)+1 == p.path.NumHops {
+		if !p.scionLayer.DstIA.Equal(p.d.localIA) {
+			return nil, p.packSCMP(
+				&slayers.SCMP{
+					TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+						slayers.SCMPCodeNonLocalDelivery),
+				},
+				&slayers.SCMPParameterProblem{Pointer: dstIAPointer},
+				serrors.WithCtx(invalidPath, "details", "path ends before destination",
+					"dst_isd_as", p.scionLayer.DstIA),
+			)
 		}
-		return p.d.internal, nil
+		return p.processInbound()
 	}
 
 	// Outbound: pkts leaving the local IA.
 	// BRTransit: pkts leaving from the same BR different interface.
 
-	if p.path.IsXover() {
+	// A peering path changes the segment at the peering link, i.e. between the
+	// two peering hop fields, so there is no crossover within the AS.
+	if p.path.IsXover() && !p.peering {
 		if err := p.doXover(); err != nil {
 			return nil, err
 		}
@@ -912,9 +1492,12 @@ func (p *scionPacketProcessor) process() (BatchConn, error) {
 	if err := p.validateEgressUp(); err != nil {
 		return nil, err
 	}
+	if err := p.validateEgressNotRevoked(); err != nil {
+		return nil, err
+	}
 
 	egressID := p.egressInterface()
-	if c, ok := p.d.external[egressID]; ok {
+	if c, ok := p.fwd.external[egressID]; ok {
 		if err := p.processEgress(); err != nil {
 			return nil, err
 		}
@@ -922,7 +1505,7 @@ func (p *scionPacketProcessor) process() (BatchConn, error) {
 	}
 
 	// ASTransit: pkts leaving from another AS BR.
-	if a, ok := p.d.internalNextHops[egressID]; ok {
+	if a, ok := p.fwd.internalNextHops[egressID]; ok {
 		p.m.Addr = a
 		return p.d.internal, nil
 	}
@@ -939,44 +1522,65 @@ func (p *scionPacketProcessor) process() (BatchConn, error) {
 	)
 }
 
-func (d *DataPlane) processOHP(ingressID uint16, m *ipv4.Message, s slayers.SCION,
-	buffer gopacket.SerializeBuffer) (BatchConn, error) {
+// processOHP processes a packet with a one-hop path. A one-hop path can not be
+// reversed, so a packet that violates the path rules is only answered with an
+// SCMP error if it was sent by a host in the local AS, see localSCMP. Otherwise
+// it is dropped.
+func (d *DataPlane) processOHP(fwd *forwardingState, ingressID uint16, m *ipv4.Message,
+	s slayers.SCION, origPacket []byte, buffer gopacket.SerializeBuffer,
+	policer *ingressPolicer) (BatchConn, error) {
 
 	p, ok := s.Path.(*onehop.Path)
 	if !ok {
-		// TODO parameter problem -> invalid path
+		// The path type is checked before, so this can not happen for a valid
+		// packet.
 		return nil, malformedPath
 	}
+	paramProblem := func(code slayers.SCMPCode, pointer int, cause error) error {
+		if ingressID != 0 {
+			return cause
+		}
+		return d.localSCMP(m, s, origPacket, buffer, policer,
+			&slayers.SCMP{
+				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+			},
+			&slayers.SCMPParameterProblem{Pointer: uint16(pointer)},
+			cause,
+		)
+	}
+	pathPointer := slayers.CmnHdrLen + s.AddrHdrLen()
 	if !p.Info.ConsDir {
-		// TODO parameter problem -> invalid path
-		return nil, serrors.WrapStr("OneHop path in reverse construction direction is not allowed",
-			malformedPath, "srcIA", s.SrcIA, "dstIA", s.DstIA)
+		return nil, paramProblem(slayers.SCMPCodeInvalidPath, pathPointer,
+			serrors.WrapStr("OneHop path in reverse construction direction is not allowed",
+				invalidPath, "srcIA", s.SrcIA, "dstIA", s.DstIA))
 	}
 	if !d.localIA.Equal(s.DstIA) && !d.localIA.Equal(s.SrcIA) {
-		// TODO parameter problem -> invalid path
-		return nil, serrors.WrapStr("OneHop neither destined or originating from IA", cannotRoute,
-			"localIA", d.localIA, "srcIA", s.SrcIA, "dstIA", s.DstIA)
+		return nil, paramProblem(slayers.SCMPCodeInvalidPath, pathPointer,
+			serrors.WrapStr("OneHop neither destined or originating from IA", invalidPath,
+				"localIA", d.localIA, "srcIA", s.SrcIA, "dstIA", s.DstIA))
 	}
 	// OHP leaving our IA
 	if d.localIA.Equal(s.SrcIA) {
-		if err := path.VerifyMAC(d.macFactory(), &p.Info, &p.FirstHop); err != nil {
-			// TODO parameter problem -> invalid MAC
-			return nil, serrors.WithCtx(err, "type", "ohp")
+		firstHopPointer := pathPointer + path.InfoLen
+		if err := fwd.verifyMAC(&p.Info, &p.FirstHop); err != nil {
+			return nil, paramProblem(slayers.SCMPCodeInvalidHopFieldMAC, firstHopPointer,
+				serrors.Wrap(invalidMAC, err, "type", "ohp"))
+		}
+		// OHP should always be directed to the correct BR.
+		c, ok := fwd.external[p.FirstHop.ConsEgress]
+		if !ok {
+			return nil, paramProblem(slayers.SCMPCodeUnknownHopFieldEgress, firstHopPointer,
+				serrors.WithCtx(cannotRoute, "type", "ohp",
+					"egress", p.FirstHop.ConsEgress, "consDir", p.Info.ConsDir))
 		}
 		p.Info.UpdateSegID(p.FirstHop.Mac)
 
 		if err := updateSCIONLayer(m, s, buffer); err != nil {
 			return nil, err
 		}
-		// OHP should always be directed to the correct BR.
-		if c, ok := d.external[p.FirstHop.ConsEgress]; ok {
-			// buffer should already be correct
-			m.Addr = nil
-			return c, nil
-		}
-		// TODO parameter problem invalid interface
-		return nil, serrors.WithCtx(cannotRoute, "type", "ohp",
-			"egress", p.FirstHop.ConsEgress, "consDir", p.Info.ConsDir)
+		// buffer should already be correct
+		m.Addr = nil
+		return c, nil
 	}
 
 	// OHP entering our IA
@@ -984,43 +1588,178 @@ func (d *DataPlane) processOHP(ingressID uint16, m *ipv4.Message, s slayers.SCIO
 		ConsIngress: ingressID,
 		ExpTime:     p.FirstHop.ExpTime,
 	}
-	p.SecondHop.Mac = path.MAC(d.macFactory(), &p.Info, &p.SecondHop)
+	p.SecondHop.Mac = path.MAC(fwd.newMAC(), &p.Info, &p.SecondHop)
 
 	if err := updateSCIONLayer(m, s, buffer); err != nil {
 		return nil, err
 	}
-	var err error
-	m.Addr, err = d.resolveLocalDst(s)
+	dsts, err := fwd.resolveLocalDst(s)
 	if err != nil {
 		return nil, err
 	}
-	return d.internal, nil
+	m.Addr = dsts[0]
+	return d.localConn(dsts), nil
 }
 
-func (d *DataPlane) resolveLocalDst(s slayers.SCION) (net.Addr, error) {
+// localSCMP answers a packet that was sent by a host in the local AS with an
+// SCMP error. It is used for packets whose path can not be reversed. The error
+// is sent to the host with an empty path, it uses the underlay address the
+// packet was received from. If the source address of the packet is invalid,
+// the host is addressed with the underlay address.
+func (d *DataPlane) localSCMP(m *ipv4.Message, s slayers.SCION, origPacket []byte,
+	buffer gopacket.SerializeBuffer, policer *ingressPolicer, scmpH *slayers.SCMP,
+	scmpP gopacket.SerializableLayer, cause error) error {
+
+	if !policer.allowSCMP() {
+		return serrors.WrapStr("not sending SCMP", scmpRateLimited, "cause", cause)
+	}
+	if s.NextHdr == common.L4SCMP {
+		var scmpLayer slayers.SCMP
+		err := scmpLayer.DecodeFromBytes(s.Payload, gopacket.NilDecodeFeedback)
+		if err == nil && !scmpLayer.TypeCode.InfoMsg() {
+			return serrors.WrapStr("SCMP error for SCMP error pkt -> DROP", cause)
+		}
+	}
+	src, err := s.SrcAddr()
+	if err != nil {
+		underlay, ok := m.Addr.(*net.UDPAddr)
+		if !ok {
+			return serrors.Wrap(cannotRoute, err, "details", "extracting src addr")
+		}
+		src = &net.IPAddr{IP: underlay.IP}
+	}
+	reply := &slayers.SCION{
+		Version:      s.Version,
+		TrafficClass: s.TrafficClass,
+		FlowID:       s.FlowID,
+		NextHdr:      common.L4SCMP,
+		PathType:     slayers.PathTypeEmpty,
+		Path:         &scion.Decoded{},
+		DstIA:        d.localIA,
+		SrcIA:        d.localIA,
+	}
+	if err := reply.SetDstAddr(src); err != nil {
+		return serrors.Wrap(cannotRoute, err, "details", "setting dest addr")
+	}
+	if err := reply.SetSrcAddr(&net.IPAddr{IP: d.internalIP}); err != nil {
+		return serrors.Wrap(cannotRoute, err, "details", "setting src addr")
+	}
+	scmpH.SetNetworkLayerForChecksum(reply)
+
+	hdrLen := slayers.CmnHdrLen + reply.AddrHdrLen() + reply.Path.Len() + 8
+	quote := origPacket
+	if maxQuoteLen := slayers.MaxSCMPPacketLen - hdrLen; len(quote) > maxQuoteLen {
+		quote = quote[:maxQuoteLen]
+	}
+	if err := buffer.Clear(); err != nil {
+		return err
+	}
+	sopts := gopacket.SerializeOptions{
+		ComputeChecksums: true,
+		FixLengths:       true,
+	}
+	err = gopacket.SerializeLayers(buffer, sopts, reply, scmpH, scmpP, gopacket.Payload(quote))
+	if err != nil {
+		return serrors.Wrap(cannotRoute, err, "details", "serializing SCMP message")
+	}
+	raw := buffer.Bytes()
+	m.Buffers[0] = m.Buffers[0][:len(raw)]
+	copy(m.Buffers[0], raw)
+	return scmpError{TypeCode: scmpH.TypeCode, Cause: cause}
+}
+
+// resolveLocalDst resolves the destinations of a packet to the local AS. A
+// multicast SVC address resolves to all backends of the service. An anycast SVC
+// address resolves to one of the backends, see anycastIndex. All other
+// addresses resolve to a single destination.
+func (fwd *forwardingState) resolveLocalDst(s slayers.SCION) ([]net.Addr, error) {
 	dst, err := s.DstAddr()
 	if err != nil {
-		// TODO parameter problem.
-		return nil, err
+		return nil, serrors.Wrap(invalidDstAddr, err)
 	}
 	if v, ok := dst.(addr.HostSVC); ok {
-		// TODO(karampok). We need to decide if we support multicast. They are
-		// needed to support keepalives in a HA architecture, because we need
-		// to make sure we hit the leader. If yes, then it requires significant
-		// re-factor because the processPkt function cannot anymore return a
-		// batchConn,error, but either an array and alternative approach (e.g.
-		// write message to channel).
-
 		// For map lookup use the Base address, i.e. strip the multi cast
 		// information, because we only register base addresses in the map.
-		if a, ok := d.svc[v.Base()]; ok {
-			if len(a) > 0 {
-				return addEndhostPort(a[0]), nil
-			}
+		a := fwd.svc[v.Base()]
+		if len(a) == 0 {
+			return nil, noSVCBackend
 		}
-		return nil, noSVCBackend
+		if !v.IsMulticast() {
+			return []net.Addr{addEndhostPort(a[anycastIndex(s, len(a))])}, nil
+		}
+		dsts := make([]net.Addr, 0, len(a))
+		for _, b := range a {
+			dsts = append(dsts, addEndhostPort(b))
+		}
+		return dsts, nil
 	}
-	return addEndhostPort(dst), nil
+	return []net.Addr{addEndhostPort(dst)}, nil
+}
+
+// anycastIndex returns the index of the backend, out of n backends, that an
+// anycast SVC packet is sent to. The index is derived from the source of the
+// packet. This balances the load over the backends, while the packets of a
+// source, e.g. the requests of a client, always reach the same backend.
+func anycastIndex(s slayers.SCION, n int) int {
+	if n == 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	var ia [8]byte
+	s.SrcIA.Write(ia[:])
+	h.Write(ia[:])
+	if src, err := s.SrcAddr(); err == nil {
+		h.Write([]byte(src.String()))
+	}
+	return int(h.Sum32() % uint32(n))
+}
+
+// localConn returns the connection that sends a packet to the given
+// destinations in the local AS. The address of the packet must be set to the
+// first destination.
+func (d *DataPlane) localConn(dsts []net.Addr) BatchConn {
+	if len(dsts) == 1 {
+		return d.internal
+	}
+	return &svcFanOut{BatchConn: d.internal, dsts: dsts}
+}
+
+// svcFanOut is the connection used for packets to a multicast SVC address. It
+// writes a copy of each message to every backend of the service. It must be
+// used as a pointer, because the connection is used as a map key, e.g., to look
+// up the output metrics.
+type svcFanOut struct {
+	BatchConn
+	dsts []net.Addr
+}
+
+// WriteBatch writes each message to all destinations. It returns the number of
+// written copies.
+func (c *svcFanOut) WriteBatch(msgs underlayconn.Messages) (int, error) {
+	out := make(underlayconn.Messages, 0, len(msgs)*len(c.dsts))
+	for _, m := range msgs {
+		for _, dst := range c.dsts {
+			m.Addr = dst
+			out = append(out, m)
+		}
+	}
+	return c.BatchConn.WriteBatch(out)
+}
+
+// sameUnderlayAddr returns whether the two addresses are the same underlay
+// address. In contrast to comparing the string representations, an IPv4-mapped
+// IPv6 address is the same as the IPv4 address, and a missing IPv6 zone matches
+// any zone.
+func sameUnderlayAddr(a, b net.Addr) bool {
+	ua, okA := a.(*net.UDPAddr)
+	ub, okB := b.(*net.UDPAddr)
+	if !okA || !okB {
+		return a.String() == b.String()
+	}
+	if ua.Zone != "" && ub.Zone != "" && ua.Zone != ub.Zone {
+		return false
+	}
+	return ua.IP.Equal(ub.IP) && ua.Port == ub.Port
 }
 
 func addEndhostPort(dst net.Addr) net.Addr {
@@ -1122,7 +1861,7 @@ func (s scmpPacker) prepareSCMP(scmpH *slayers.SCMP, scmpP gopacket.Serializable
 	}
 	if incPath {
 		infoField := decPath.InfoFields[decPath.PathMeta.CurrINF]
-		if infoField.ConsDir {
+		if infoField.ConsDir && !isPeeringHop(decPath.PathMeta, infoField) {
 			hopField := decPath.HopFields[decPath.PathMeta.CurrHF]
 			infoField.UpdateSegID(hopField.Mac)
 		}
@@ -1162,6 +1901,9 @@ func (s scmpPacker) prepareSCMP(scmpH *slayers.SCMP, scmpP gopacket.Serializable
 		switch scmpH.TypeCode.Type() {
 		case slayers.SCMPTypeExternalInterfaceDown:
 			hdrLen += 20
+			if r, ok := scmpP.(*revokedInterfaceDown); ok {
+				hdrLen += r.Revocation.Len()
+			}
 		case slayers.SCMPTypeInternalConnectivityDown:
 			hdrLen += 28
 		default:
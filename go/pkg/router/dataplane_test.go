@@ -16,6 +16,7 @@ package router_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"sync"
@@ -25,6 +26,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/ipv4"
@@ -32,6 +34,7 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/slayers"
 	"github.com/scionproto/scion/go/lib/slayers/path"
 	"github.com/scionproto/scion/go/lib/slayers/path/onehop"
@@ -79,35 +82,131 @@ func TestDataPlaneAddInternalInterface(t *testing.T) {
 }
 
 func TestDataPlaneSetKey(t *testing.T) {
-	t.Run("fails after serve", func(t *testing.T) {
+	t.Run("works after serve", func(t *testing.T) {
 		d := &router.DataPlane{}
 		d.FakeStart()
-		assert.Error(t, d.SetKey([]byte("dummy")))
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
 	})
 	t.Run("setting nil value is not allowed", func(t *testing.T) {
 		d := &router.DataPlane{}
 		d.FakeStart()
-		assert.Error(t, d.SetKey(nil))
+		assert.Error(t, d.SetKey(0, nil))
+	})
+	t.Run("invalid key is not allowed", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.Error(t, d.SetKey(0, []byte("dummy")))
 	})
 	t.Run("single set works", func(t *testing.T) {
 		d := &router.DataPlane{}
-		assert.NoError(t, d.SetKey([]byte("dummy key xxxxxx")))
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
 	})
-	t.Run("double set fails", func(t *testing.T) {
+	t.Run("double set replaces", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
+		assert.NoError(t, d.SetKey(0, []byte("dummy key yyyyyy")))
+	})
+	t.Run("previous key works", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
+		assert.NoError(t, d.SetKey(1, []byte("dummy key yyyyyy")))
+	})
+	t.Run("index out of range fails", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.Error(t, d.SetKey(-1, []byte("dummy key xxxxxx")))
+		assert.Error(t, d.SetKey(router.NumKeys, []byte("dummy key xxxxxx")))
+	})
+	t.Run("previous key can be deleted", func(t *testing.T) {
 		d := &router.DataPlane{}
-		assert.NoError(t, d.SetKey([]byte("dummy key xxxxxx")))
-		assert.Error(t, d.SetKey([]byte("dummy key xxxxxx")))
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
+		assert.NoError(t, d.SetKey(1, []byte("dummy key yyyyyy")))
+		assert.NoError(t, d.DelKey(1))
+	})
+	t.Run("current key can not be deleted", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.NoError(t, d.SetKey(0, []byte("dummy key xxxxxx")))
+		assert.Error(t, d.DelKey(0))
+		assert.Error(t, d.DelKey(router.NumKeys))
 	})
 }
 
+func TestDataPlaneKeyRollover(t *testing.T) {
+	local := xtest.MustParseIA("1-ff00:0:110")
+	current := []byte("testkey_current_")
+	previous := []byte("testkey_previous")
+	unknown := []byte("testkey_unknown_")
+
+	testCases := map[string]struct {
+		key       []byte
+		delete    bool
+		assertErr assert.ErrorAssertionFunc
+	}{
+		"current key": {
+			key:       current,
+			assertErr: assert.NoError,
+		},
+		"previous key": {
+			key:       previous,
+			assertErr: assert.NoError,
+		},
+		"unknown key": {
+			key:       unknown,
+			assertErr: assert.Error,
+		},
+		"deleted previous key": {
+			key:       previous,
+			delete:    true,
+			assertErr: assert.Error,
+		},
+		"current key after deleting previous key": {
+			key:       current,
+			delete:    true,
+			assertErr: assert.NoError,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			external := mock_router.NewMockBatchConn(ctrl)
+			dp := router.NewDP(map[uint16]router.BatchConn{2: external},
+				nil, nil, nil, local, current)
+			require.NoError(t, dp.SetKey(1, previous))
+			if tc.delete {
+				require.NoError(t, dp.DelKey(1))
+			}
+
+			m, s, raw := prepEgressMsg(t, local, tc.key)
+			_, err := dp.ProcessPkt(0, m, s, raw, gopacket.NewSerializeBuffer())
+			tc.assertErr(t, err)
+		})
+	}
+}
+
 func TestDataPlaneAddExternalInterface(t *testing.T) {
-	t.Run("fails after serve", func(t *testing.T) {
+	t.Run("works after serve", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		closed := make(chan struct{})
+		c := mock_router.NewMockBatchConn(ctrl)
+		c.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(underlayconn.Messages, []underlayconn.ReadMeta) (int, error) {
+				<-closed
+				return 0, serrors.New("closed")
+			},
+		).AnyTimes()
+
 		d := &router.DataPlane{}
 		d.FakeStart()
-		assert.Error(t, d.AddExternalInterface(42, mock_conn.NewMockConn(ctrl)))
+		require.NoError(t, d.AddExternalInterface(42, c))
+		c.EXPECT().Close().DoAndReturn(func() error {
+			close(closed)
+			return nil
+		})
+		assert.NoError(t, d.DelExternalInterface(42))
 	})
 	t.Run("setting nil value is not allowed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -135,10 +234,10 @@ func TestDataPlaneAddExternalInterface(t *testing.T) {
 }
 
 func TestDataPlaneAddSVC(t *testing.T) {
-	t.Run("fails after serve", func(t *testing.T) {
+	t.Run("works after serve", func(t *testing.T) {
 		d := &router.DataPlane{}
 		d.FakeStart()
-		assert.Error(t, d.AddSvc(addr.SvcCS, &net.IPAddr{}))
+		assert.NoError(t, d.AddSvc(addr.SvcCS, &net.IPAddr{}))
 	})
 	t.Run("adding nil value is not allowed", func(t *testing.T) {
 		d := &router.DataPlane{}
@@ -157,10 +256,10 @@ func TestDataPlaneAddSVC(t *testing.T) {
 }
 
 func TestDataPlaneAddNextHop(t *testing.T) {
-	t.Run("fails after serve", func(t *testing.T) {
+	t.Run("works after serve", func(t *testing.T) {
 		d := &router.DataPlane{}
 		d.FakeStart()
-		assert.Error(t, d.AddNextHop(45, &net.IPAddr{}))
+		assert.NoError(t, d.AddNextHop(45, &net.IPAddr{}))
 	})
 	t.Run("setting nil value is not allowed", func(t *testing.T) {
 		d := &router.DataPlane{}
@@ -178,6 +277,118 @@ func TestDataPlaneAddNextHop(t *testing.T) {
 	})
 }
 
+func TestDataPlaneAddNeighborIA(t *testing.T) {
+	t.Run("works after serve", func(t *testing.T) {
+		d := &router.DataPlane{}
+		d.FakeStart()
+		assert.NoError(t, d.AddNeighborIA(45, xtest.MustParseIA("1-ff00:0:111")))
+	})
+	t.Run("setting zero value is not allowed", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.Error(t, d.AddNeighborIA(45, addr.IA{}))
+	})
+	t.Run("normal add works", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.NoError(t, d.AddNeighborIA(45, xtest.MustParseIA("1-ff00:0:111")))
+		assert.NoError(t, d.AddNeighborIA(43, xtest.MustParseIA("1-ff00:0:112")))
+	})
+	t.Run("overwrite fails", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.NoError(t, d.AddNeighborIA(45, xtest.MustParseIA("1-ff00:0:111")))
+		assert.Error(t, d.AddNeighborIA(45, xtest.MustParseIA("1-ff00:0:112")))
+	})
+}
+
+func TestDataPlaneDelSVC(t *testing.T) {
+	a1 := &net.UDPAddr{IP: net.ParseIP("10.0.200.200").To4(), Port: 30041}
+	a2 := &net.UDPAddr{IP: net.ParseIP("10.0.200.201").To4(), Port: 30041}
+	t.Run("delete known address works", func(t *testing.T) {
+		d := &router.DataPlane{}
+		d.FakeStart()
+		require.NoError(t, d.AddSvc(addr.SvcCS, a1))
+		require.NoError(t, d.AddSvc(addr.SvcCS, a2))
+		assert.NoError(t, d.DelSvc(addr.SvcCS, a1))
+		assert.NoError(t, d.DelSvc(addr.SvcCS, a2))
+	})
+	t.Run("delete unknown address fails", func(t *testing.T) {
+		d := &router.DataPlane{}
+		require.NoError(t, d.AddSvc(addr.SvcCS, a1))
+		assert.Error(t, d.DelSvc(addr.SvcCS, a2))
+		assert.Error(t, d.DelSvc(addr.SvcSIG, a1))
+	})
+}
+
+func TestDataPlaneDelNextHop(t *testing.T) {
+	t.Run("delete known next hop works", func(t *testing.T) {
+		d := &router.DataPlane{}
+		d.FakeStart()
+		require.NoError(t, d.AddNextHop(45, &net.IPAddr{}))
+		assert.NoError(t, d.DelNextHop(45))
+		assert.NoError(t, d.AddNextHop(45, &net.IPAddr{}))
+	})
+	t.Run("delete unknown next hop fails", func(t *testing.T) {
+		d := &router.DataPlane{}
+		assert.Error(t, d.DelNextHop(45))
+	})
+}
+
+func TestDataPlaneReconfiguration(t *testing.T) {
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	nextHop := &net.UDPAddr{IP: net.ParseIP("10.0.200.200").To4(), Port: 30043}
+
+	t.Run("aborted changes are not visible", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		external := mock_router.NewMockBatchConn(ctrl)
+		dp := router.NewDP(nil, nil, nil, nil, local, key)
+
+		r := dp.BeginReconfiguration()
+		require.NoError(t, r.AddExternalInterface(2, external))
+		r.Abort()
+
+		m, s, raw := prepEgressMsg(t, local, key)
+		conn, err := dp.ProcessPkt(0, m, s, raw, gopacket.NewSerializeBuffer())
+		assert.Error(t, err)
+		assert.Nil(t, conn)
+	})
+	t.Run("committed changes are visible at once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		external := mock_router.NewMockBatchConn(ctrl)
+		dp := router.NewDP(nil, nil, nil, nil, local, key)
+
+		r := dp.BeginReconfiguration()
+		require.NoError(t, r.AddExternalInterface(2, external))
+		require.NoError(t, r.AddNextHop(3, nextHop))
+		require.NoError(t, r.Commit())
+		assert.Error(t, r.Commit())
+
+		m, s, raw := prepEgressMsg(t, local, key)
+		conn, err := dp.ProcessPkt(0, m, s, raw, gopacket.NewSerializeBuffer())
+		assert.NoError(t, err)
+		assert.Equal(t, external, conn)
+	})
+	t.Run("removed interface is closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		external := mock_router.NewMockBatchConn(ctrl)
+		dp := router.NewDP(map[uint16]router.BatchConn{2: external}, nil, nil, nil, local, key)
+
+		external.EXPECT().Close()
+		require.NoError(t, dp.DelExternalInterface(2))
+		assert.Error(t, dp.DelExternalInterface(2))
+
+		m, s, raw := prepEgressMsg(t, local, key)
+		conn, err := dp.ProcessPkt(0, m, s, raw, gopacket.NewSerializeBuffer())
+		assert.Error(t, err)
+		assert.Nil(t, conn)
+	})
+}
+
 func TestDataPlaneRun(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
@@ -249,7 +460,7 @@ func TestDataPlaneRun(t *testing.T) {
 				_ = ret.AddExternalInterface(1, mExternal)
 
 				_ = ret.SetIA(local)
-				_ = ret.SetKey(key)
+				_ = ret.SetKey(0, key)
 				return ret
 			},
 		},
@@ -361,7 +572,7 @@ func TestDataPlaneRun(t *testing.T) {
 			dp := tc.prepareDP(ctrl, ch)
 			errors := make(chan error)
 			go func() {
-				errors <- dp.Run()
+				errors <- dp.Run(context.Background())
 			}()
 
 			for done := false; !done; {
@@ -378,6 +589,218 @@ func TestDataPlaneRun(t *testing.T) {
 	}
 }
 
+func TestDataPlaneShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The internal connection supports read deadlines, it must only be closed
+	// after its reader has stopped.
+	internal := &deadlineConn{
+		MockBatchConn: mock_router.NewMockBatchConn(ctrl),
+		deadline:      make(chan struct{}),
+	}
+	internal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(underlayconn.Messages, []underlayconn.ReadMeta) (int, error) {
+			<-internal.deadline
+			return 0, serrors.New("i/o timeout")
+		},
+	).AnyTimes()
+	internal.EXPECT().Close().DoAndReturn(func() error {
+		select {
+		case <-internal.deadline:
+		default:
+			t.Error("internal connection closed before the read deadline was set")
+		}
+		return nil
+	})
+
+	// The external connection does not support read deadlines, it is closed to
+	// stop its reader.
+	closed := make(chan struct{})
+	external := mock_router.NewMockBatchConn(ctrl)
+	external.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(underlayconn.Messages, []underlayconn.ReadMeta) (int, error) {
+			<-closed
+			return 0, serrors.New("closed")
+		},
+	).AnyTimes()
+	external.EXPECT().WriteBatch(gomock.Any()).Return(1, nil).AnyTimes()
+	external.EXPECT().Close().DoAndReturn(func() error {
+		close(closed)
+		return nil
+	})
+
+	dp := &router.DataPlane{}
+	require.NoError(t, dp.AddInternalInterface(internal, net.ParseIP("10.0.200.1").To4()))
+	require.NoError(t, dp.SetIA(xtest.MustParseIA("1-ff00:0:110")))
+	require.NoError(t, dp.AddExternalInterface(1, external))
+	require.NoError(t, dp.AddExternalInterfaceBFD(1, external))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- dp.Run(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errs:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("time out")
+	}
+	assert.Error(t, dp.Run(context.Background()), "a stopped dataplane can not be run")
+}
+
+// deadlineConn is a connection with support for read deadlines, a read
+// deadline unblocks all reads.
+type deadlineConn struct {
+	*mock_router.MockBatchConn
+	deadline chan struct{}
+	once     sync.Once
+}
+
+func (c *deadlineConn) SetReadDeadline(time.Time) error {
+	c.once.Do(func() { close(c.deadline) })
+	return nil
+}
+
+func TestDataPlaneMetrics(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	neighbor := xtest.MustParseIA("1-ff00:0:111")
+	metrics := router.NewUnregisteredMetrics()
+	dp := &router.DataPlane{Metrics: metrics}
+
+	mInternal := mock_router.NewMockBatchConn(ctrl)
+	mInternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+	mInternal.EXPECT().WriteBatch(gomock.Any()).Return(1, nil).AnyTimes()
+
+	mExternal := mock_router.NewMockBatchConn(ctrl)
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(m underlayconn.Messages, meta []underlayconn.ReadMeta) (int, error) {
+			// one valid packet to the local AS, and one with an invalid MAC.
+			for i, mac := range [][]byte{nil, {1, 2, 3, 4, 5, 6}} {
+				spkt, dpath := prepBaseMsg()
+				spkt.DstIA = local
+				_ = spkt.SetDstAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+				dpath.HopFields = []*path.HopField{
+					{ConsIngress: 41, ConsEgress: 40},
+					{ConsIngress: 31, ConsEgress: 30},
+					{ConsIngress: 1, ConsEgress: 0},
+				}
+				dpath.Base.PathMeta.CurrHF = 2
+				dpath.HopFields[2].Mac = computeMAC(t, key,
+					dpath.InfoFields[0], dpath.HopFields[2])
+				if mac != nil {
+					dpath.HopFields[2].Mac = mac
+				}
+				raw := toMsg(t, spkt, dpath).Buffers[0]
+				copy(m[i].Buffers[0], raw)
+				m[i].N = len(raw)
+			}
+			return 2, nil
+		},
+	).Times(1)
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+	mExternal.EXPECT().WriteBatch(gomock.Any()).Return(1, nil).AnyTimes()
+
+	require.NoError(t, dp.AddInternalInterface(mInternal, net.ParseIP("10.0.200.1").To4()))
+	require.NoError(t, dp.AddExternalInterface(1, mExternal))
+	require.NoError(t, dp.AddNeighborIA(1, neighbor))
+	require.NoError(t, dp.SetIA(local))
+	require.NoError(t, dp.SetKey(0, key))
+	go func() {
+		_ = dp.Run(context.Background())
+	}()
+
+	external := []string{"1", local.String(), neighbor.String()}
+	internal := []string{"internal", local.String(), local.String()}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.OutputPacketsTotal.WithLabelValues(external...)) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, float64(2),
+		testutil.ToFloat64(metrics.InputPacketsTotal.WithLabelValues(external...)))
+	assert.Equal(t, float64(1),
+		testutil.ToFloat64(metrics.OutputPacketsTotal.WithLabelValues(internal...)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DroppedPacketsTotal.
+		WithLabelValues(append(external, "invalid_mac")...)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DroppedPacketsTotal.
+		WithLabelValues(append(external, "expired_hop")...)))
+}
+
+func TestDataPlaneMulticastSVC(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	neighbor := xtest.MustParseIA("1-ff00:0:111")
+	backends := []net.Addr{
+		&net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()},
+		&net.IPAddr{IP: net.ParseIP("10.0.200.201").To4()},
+	}
+	metrics := router.NewUnregisteredMetrics()
+	dp := &router.DataPlane{Metrics: metrics}
+
+	mInternal := mock_router.NewMockBatchConn(ctrl)
+	mInternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+	mInternal.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(
+		func(msgs underlayconn.Messages) (int, error) {
+			return len(msgs), nil
+		},
+	).AnyTimes()
+
+	mExternal := mock_router.NewMockBatchConn(ctrl)
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(m underlayconn.Messages, meta []underlayconn.ReadMeta) (int, error) {
+			spkt, dpath := prepBaseMsg()
+			spkt.DstIA = local
+			_ = spkt.SetDstAddr(addr.SvcCS.Multicast())
+			dpath.HopFields = []*path.HopField{
+				{ConsIngress: 41, ConsEgress: 40},
+				{ConsIngress: 31, ConsEgress: 30},
+				{ConsIngress: 1, ConsEgress: 0},
+			}
+			dpath.Base.PathMeta.CurrHF = 2
+			dpath.HopFields[2].Mac = computeMAC(t, key,
+				dpath.InfoFields[0], dpath.HopFields[2])
+			raw := toMsg(t, spkt, dpath).Buffers[0]
+			copy(m[0].Buffers[0], raw)
+			m[0].N = len(raw)
+			return 1, nil
+		},
+	).Times(1)
+	mExternal.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).Return(0, nil).AnyTimes()
+
+	require.NoError(t, dp.AddInternalInterface(mInternal, net.ParseIP("10.0.200.1").To4()))
+	require.NoError(t, dp.AddExternalInterface(1, mExternal))
+	require.NoError(t, dp.AddNeighborIA(1, neighbor))
+	for _, b := range backends {
+		require.NoError(t, dp.AddSvc(addr.SvcCS, b))
+	}
+	require.NoError(t, dp.SetIA(local))
+	require.NoError(t, dp.SetKey(0, key))
+	go func() {
+		_ = dp.Run(context.Background())
+	}()
+
+	// The packet is written once to every backend, all copies are accounted to
+	// the internal interface.
+	internal := []string{"internal", local.String(), local.String()}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(
+			metrics.OutputPacketsTotal.WithLabelValues(internal...)) == float64(len(backends))
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, float64(0),
+		testutil.ToFloat64(metrics.OutputErrorsTotal.WithLabelValues(internal...)))
+}
+
 func TestProcessPkt(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -528,6 +951,60 @@ func TestProcessPkt(t *testing.T) {
 			srcInterface: 1,
 			assertFunc:   assert.NoError,
 		},
+		"inbound ipv6": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(nil, mock_router.NewMockBatchConn(ctrl), nil,
+					nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, dpath := prepBaseMsg()
+				spkt.DstIA = xtest.MustParseIA("1-ff00:0:110")
+				dst := &net.IPAddr{IP: net.ParseIP("fd00:f00d:cafe::100")}
+				_ = spkt.SetDstAddr(dst)
+				dpath.HopFields = []*path.HopField{
+					{ConsIngress: 41, ConsEgress: 40},
+					{ConsIngress: 31, ConsEgress: 30},
+					{ConsIngress: 01, ConsEgress: 0},
+				}
+				dpath.Base.PathMeta.CurrHF = 2
+				dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[2])
+				ret := toMsg(t, spkt, dpath)
+				ret.Addr = &net.UDPAddr{IP: net.ParseIP("fd00:f00d:cafe::2"), Port: 40000}
+				if afterProcessing {
+					ret.Addr = &net.UDPAddr{IP: dst.IP, Port: topology.EndhostPort}
+					ret.Flags, ret.NN, ret.N, ret.OOB = 0, 0, 0, nil
+				}
+				return ret
+			},
+			srcInterface: 1,
+			assertFunc:   assert.NoError,
+		},
+		"astransit direct ipv6": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(nil, mock_router.NewMockBatchConn(ctrl),
+					map[uint16]net.Addr{
+						uint16(3): &net.UDPAddr{IP: net.ParseIP("fd00:f00d:cafe::200"),
+							Port: 30002},
+					}, nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, dpath := prepBaseMsg()
+				dpath.HopFields = []*path.HopField{
+					{ConsIngress: 31, ConsEgress: 30},
+					{ConsIngress: 1, ConsEgress: 3},
+					{ConsIngress: 50, ConsEgress: 51},
+				}
+				dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+				ret := toMsg(t, spkt, dpath)
+				if afterProcessing {
+					ret.Addr = &net.UDPAddr{IP: net.ParseIP("fd00:f00d:cafe::200"), Port: 30002}
+					ret.Flags, ret.NN, ret.N, ret.OOB = 0, 0, 0, nil
+				}
+				return ret
+			},
+			srcInterface: 1,
+			assertFunc:   assert.NoError,
+		},
 		"astransit xover": {
 			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
 				return router.NewDP(nil, mock_router.NewMockBatchConn(ctrl),
@@ -540,7 +1017,7 @@ func TestProcessPkt(t *testing.T) {
 				dpath := &scion.Decoded{
 					Base: scion.Base{
 						PathMeta: scion.MetaHdr{
-							CurrHF: 2,
+							CurrHF: 1,
 							SegLen: [3]uint8{2, 2, 0},
 						},
 						NumINF:  2,
@@ -553,17 +1030,17 @@ func TestProcessPkt(t *testing.T) {
 						{SegID: 0x222, ConsDir: false, Timestamp: util.TimeToSecs(time.Now())},
 					},
 					HopFields: []*path.HopField{
-						{ConsIngress: 0, ConsEgress: 1},  // IA 110
-						{ConsIngress: 31, ConsEgress: 0}, // Src
-						{ConsIngress: 0, ConsEgress: 51}, // Dst
+						{ConsIngress: 1, ConsEgress: 0},  // Src
+						{ConsIngress: 0, ConsEgress: 51}, // IA 110
 						{ConsIngress: 3, ConsEgress: 0},  // IA 110
+						{ConsIngress: 0, ConsEgress: 1},  // Dst
 					},
 				}
-				dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[2])
-				dpath.HopFields[3].Mac = computeMAC(t, key, dpath.InfoFields[1], dpath.HopFields[3])
+				dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+				dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[1], dpath.HopFields[2])
 
 				if !afterProcessing {
-					dpath.InfoFields[0].UpdateSegID(dpath.HopFields[2].Mac)
+					dpath.InfoFields[0].UpdateSegID(dpath.HopFields[1].Mac)
 					return toMsg(t, spkt, dpath)
 				}
 				require.NoError(t, dpath.IncPath())
@@ -814,6 +1291,113 @@ func TestProcessPkt(t *testing.T) {
 			srcInterface: 0,
 			assertFunc:   assert.NoError,
 		},
+		"peering consdir": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(map[uint16]router.BatchConn{
+					uint16(2): mock_router.NewMockBatchConn(ctrl),
+				}, nil, nil, nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, _ := prepPeeringMsg()
+				dpath := prepPeeringPath(2, []*path.HopField{
+					{ConsIngress: 31, ConsEgress: 0},  // Src
+					{ConsIngress: 10, ConsEgress: 30}, // peer of IA 110
+					{ConsIngress: 1, ConsEgress: 2},   // IA 110
+					{ConsIngress: 40, ConsEgress: 0},  // Dst
+				})
+				dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[1], dpath.HopFields[2])
+				if !afterProcessing {
+					return toMsg(t, spkt, dpath)
+				}
+				// The SegID is not updated, the next hop field is chained to
+				// the same SegID as the peering hop field.
+				require.NoError(t, dpath.IncPath())
+				ret := toMsg(t, spkt, dpath)
+				ret.Addr = nil
+				ret.Flags, ret.NN, ret.N, ret.OOB = 0, 0, 0, nil
+				return ret
+			},
+			srcInterface: 1,
+			assertFunc:   assert.NoError,
+		},
+		"peering non consdir": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(map[uint16]router.BatchConn{
+					uint16(2): mock_router.NewMockBatchConn(ctrl),
+				}, nil, nil, nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, _ := prepPeeringMsg()
+				dpath := prepPeeringPath(1, []*path.HopField{
+					{ConsIngress: 31, ConsEgress: 0},  // Src
+					{ConsIngress: 2, ConsEgress: 1},   // IA 110
+					{ConsIngress: 10, ConsEgress: 40}, // peer of IA 110
+					{ConsIngress: 50, ConsEgress: 0},  // Dst
+				})
+				dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+				if !afterProcessing {
+					return toMsg(t, spkt, dpath)
+				}
+				// The path switches to the second segment on the peering link,
+				// the SegIDs are not updated.
+				require.NoError(t, dpath.IncPath())
+				ret := toMsg(t, spkt, dpath)
+				ret.Addr = nil
+				ret.Flags, ret.NN, ret.N, ret.OOB = 0, 0, 0, nil
+				return ret
+			},
+			srcInterface: 1,
+			assertFunc:   assert.NoError,
+		},
+		"peering astransit": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(nil, mock_router.NewMockBatchConn(ctrl),
+					map[uint16]net.Addr{
+						uint16(2): &net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()},
+					}, nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, _ := prepPeeringMsg()
+				dpath := prepPeeringPath(1, []*path.HopField{
+					{ConsIngress: 31, ConsEgress: 0},  // Src
+					{ConsIngress: 2, ConsEgress: 1},   // IA 110
+					{ConsIngress: 10, ConsEgress: 40}, // peer of IA 110
+					{ConsIngress: 50, ConsEgress: 0},  // Dst
+				})
+				dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+				ret := toMsg(t, spkt, dpath)
+				if afterProcessing {
+					ret.Addr = &net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()}
+					ret.Flags, ret.NN, ret.N, ret.OOB = 0, 0, 0, nil
+				}
+				return ret
+			},
+			srcInterface: 1,
+			assertFunc:   assert.NoError,
+		},
+		"peering invalid mac": {
+			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
+				return router.NewDP(map[uint16]router.BatchConn{
+					uint16(2): mock_router.NewMockBatchConn(ctrl),
+				}, nil, nil, nil, xtest.MustParseIA("1-ff00:0:110"), key)
+			},
+			mockMsg: func(afterProcessing bool) *ipv4.Message {
+				spkt, _ := prepPeeringMsg()
+				dpath := prepPeeringPath(1, []*path.HopField{
+					{ConsIngress: 31, ConsEgress: 0},  // Src
+					{ConsIngress: 2, ConsEgress: 1},   // IA 110
+					{ConsIngress: 10, ConsEgress: 40}, // peer of IA 110
+					{ConsIngress: 50, ConsEgress: 0},  // Dst
+				})
+				// A MAC chained like a regular hop field against construction
+				// direction does not verify for a peering hop field.
+				dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+				dpath.InfoFields[0].UpdateSegID(dpath.HopFields[1].Mac)
+				return toMsg(t, spkt, dpath)
+			},
+			srcInterface: 1,
+			assertFunc:   assert.Error,
+		},
 		"invalid dest": {
 			prepareDP: func(ctrl *gomock.Controller) *router.DataPlane {
 				return router.NewDP(nil, mock_router.NewMockBatchConn(ctrl), nil,
@@ -863,6 +1447,698 @@ func TestProcessPkt(t *testing.T) {
 	}
 }
 
+func TestProcessPktSVC(t *testing.T) {
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	backends := []net.Addr{
+		&net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()},
+		&net.IPAddr{IP: net.ParseIP("10.0.200.201").To4()},
+		&net.IPAddr{IP: net.ParseIP("10.0.200.202").To4()},
+	}
+	udpAddr := func(a net.Addr) net.Addr {
+		return &net.UDPAddr{IP: a.(*net.IPAddr).IP, Port: topology.EndhostPort}
+	}
+	// prepare returns a packet from the given source host to the given SVC
+	// address in the local AS.
+	prepare := func(dst addr.HostSVC, src net.IP) (*ipv4.Message, []byte) {
+		spkt, dpath := prepBaseMsg()
+		require.NoError(t, spkt.SetDstAddr(dst))
+		require.NoError(t, spkt.SetSrcAddr(&net.IPAddr{IP: src}))
+		spkt.DstIA = local
+		dpath.HopFields = []*path.HopField{
+			{ConsIngress: 41, ConsEgress: 40},
+			{ConsIngress: 31, ConsEgress: 30},
+			{ConsIngress: 1, ConsEgress: 0},
+		}
+		dpath.Base.PathMeta.CurrHF = 2
+		dpath.HopFields[2].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[2])
+		m := toMsg(t, spkt, dpath)
+		orig := make([]byte, len(m.Buffers[0]))
+		copy(orig, m.Buffers[0])
+		return m, orig
+	}
+
+	t.Run("multicast is sent to all backends", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		internal := mock_router.NewMockBatchConn(ctrl)
+		dp := router.NewDP(nil, internal, nil, map[addr.HostSVC][]net.Addr{
+			addr.SvcCS: backends,
+		}, local, key)
+
+		m, orig := prepare(addr.SvcCS.Multicast(), net.ParseIP("10.0.100.1").To4())
+		c, err := dp.ProcessPkt(1, m, slayers.SCION{}, orig, gopacket.NewSerializeBuffer())
+		require.NoError(t, err)
+
+		internal.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(
+			func(msgs underlayconn.Messages) (int, error) {
+				require.Len(t, msgs, len(backends))
+				for i, msg := range msgs {
+					assert.Equal(t, udpAddr(backends[i]), msg.Addr)
+					assert.Equal(t, m.Buffers, msg.Buffers)
+				}
+				return len(msgs), nil
+			},
+		)
+		n, err := c.WriteBatch(underlayconn.Messages{*m})
+		assert.NoError(t, err)
+		assert.Equal(t, len(backends), n)
+	})
+	t.Run("anycast is sent to one backend per source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		internal := mock_router.NewMockBatchConn(ctrl)
+		dp := router.NewDP(nil, internal, nil, map[addr.HostSVC][]net.Addr{
+			addr.SvcCS: backends,
+		}, local, key)
+
+		chosen := make(map[string]struct{})
+		for i := 0; i < 32; i++ {
+			src := net.IPv4(10, 0, 100, byte(i)).To4()
+			var dsts []net.Addr
+			for j := 0; j < 2; j++ {
+				m, orig := prepare(addr.SvcCS, src)
+				c, err := dp.ProcessPkt(1, m, slayers.SCION{}, orig,
+					gopacket.NewSerializeBuffer())
+				require.NoError(t, err)
+				assert.Equal(t, internal, c)
+				dsts = append(dsts, m.Addr)
+			}
+			assert.Equal(t, dsts[0], dsts[1], "packets of a source reach the same backend")
+			assert.Contains(t, []net.Addr{udpAddr(backends[0]), udpAddr(backends[1]),
+				udpAddr(backends[2])}, dsts[0])
+			chosen[dsts[0].String()] = struct{}{}
+		}
+		assert.Greater(t, len(chosen), 1, "load is spread over the backends")
+	})
+}
+
+func TestProcessPktBFDNextHop(t *testing.T) {
+	testCases := map[string]struct {
+		nextHop *net.UDPAddr
+		src     *net.UDPAddr
+	}{
+		"ipv4": {
+			nextHop: &net.UDPAddr{IP: net.ParseIP("10.0.200.200").To4(), Port: 30002},
+			src:     &net.UDPAddr{IP: net.ParseIP("10.0.200.200").To4(), Port: 30002},
+		},
+		"ipv4 mapped": {
+			nextHop: &net.UDPAddr{IP: net.ParseIP("10.0.200.200").To4(), Port: 30002},
+			src:     &net.UDPAddr{IP: net.ParseIP("::ffff:10.0.200.200"), Port: 30002},
+		},
+		"ipv6": {
+			nextHop: &net.UDPAddr{IP: net.ParseIP("fd00:f00d:cafe::200"), Port: 30002},
+			src:     &net.UDPAddr{IP: net.ParseIP("fd00:f00d:cafe::200"), Port: 30002},
+		},
+		"ipv6 link local with zone": {
+			nextHop: &net.UDPAddr{IP: net.ParseIP("fe80::200"), Port: 30002},
+			src:     &net.UDPAddr{IP: net.ParseIP("fe80::200"), Port: 30002, Zone: "eth0"},
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dp := router.NewDP(nil, mock_router.NewMockBatchConn(ctrl), nil, nil,
+				xtest.MustParseIA("1-ff00:0:110"), []byte("testkey_xxxxxxxx"))
+			require.NoError(t, dp.AddNextHop(3, tc.nextHop))
+			require.NoError(t, dp.AddNextHopBFD(3, tc.nextHop))
+
+			scn := &slayers.SCION{
+				NextHdr:  common.L4BFD,
+				PathType: slayers.PathTypeEmpty,
+				Path:     &scion.Decoded{},
+			}
+			bfd := &layers.BFD{
+				Version:           1,
+				State:             layers.BFDStateDown,
+				DetectMultiplier:  3,
+				MyDiscriminator:   12345,
+				YourDiscriminator: 0,
+			}
+			buffer := gopacket.NewSerializeBuffer()
+			require.NoError(t, gopacket.SerializeLayers(buffer,
+				gopacket.SerializeOptions{FixLengths: true}, scn, bfd))
+			raw := buffer.Bytes()
+			m := &ipv4.Message{Buffers: [][]byte{raw}, N: len(raw), Addr: tc.src}
+
+			c, err := dp.ProcessPkt(0, m, slayers.SCION{}, raw, gopacket.NewSerializeBuffer())
+			assert.NoError(t, err)
+			assert.Nil(t, c)
+		})
+	}
+}
+
+func TestDataPlaneRevocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	prepareDP := func() *router.DataPlane {
+		dp := &router.DataPlane{}
+		require.NoError(t, dp.AddInternalInterface(mock_router.NewMockBatchConn(ctrl),
+			net.ParseIP("10.0.200.1").To4()))
+		require.NoError(t, dp.AddNextHop(3, &net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()}))
+		require.NoError(t, dp.SetIA(local))
+		require.NoError(t, dp.SetKey(0, key))
+		return dp
+	}
+	process := func(t *testing.T, dp *router.DataPlane) (*ipv4.Message, error) {
+		spkt, dpath := prepBaseMsg()
+		_ = spkt.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+		dpath.HopFields = []*path.HopField{
+			{ConsIngress: 31, ConsEgress: 30},
+			{ConsIngress: 1, ConsEgress: 3},
+			{ConsIngress: 50, ConsEgress: 51},
+		}
+		dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+		msg := toMsg(t, spkt, dpath)
+		origMsg := make([]byte, len(msg.Buffers[0]))
+		copy(origMsg, msg.Buffers[0])
+		_, err := dp.ProcessPkt(1, msg, slayers.SCION{}, origMsg,
+			gopacket.NewSerializeBuffer())
+		return msg, err
+	}
+
+	sRevInfo := []byte("signed revocation")
+
+	t.Run("revoked egress is answered with SCMP", func(t *testing.T) {
+		dp := prepareDP()
+		dp.FakeStart()
+		require.NoError(t, dp.SetRevocation(3, time.Now().Add(time.Minute), sRevInfo))
+		msg, err := process(t, dp)
+		assert.Error(t, err)
+		pkt := gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION, gopacket.Default)
+		scmpL := pkt.Layer(slayers.LayerTypeSCMP)
+		require.NotNil(t, scmpL)
+		assert.Equal(t, slayers.CreateSCMPTypeCode(slayers.SCMPTypeExternalInterfaceDown,
+			slayers.SCMPCodeRevokedInterface), scmpL.(*slayers.SCMP).TypeCode)
+		ifDown := pkt.Layer(slayers.LayerTypeSCMPExternalInterfaceDown)
+		require.NotNil(t, ifDown)
+		assert.Equal(t, local, ifDown.(*slayers.SCMPExternalInterfaceDown).IA)
+		assert.Equal(t, uint64(3), ifDown.(*slayers.SCMPExternalInterfaceDown).IfID)
+		var rev slayers.SCMPRevocation
+		require.NoError(t, rev.DecodeFromBytes(ifDown.LayerPayload(),
+			gopacket.NilDecodeFeedback))
+		assert.Equal(t, sRevInfo, rev.SignedRevInfo)
+		// The revocation is followed by the quote of the offending packet.
+		quote := gopacket.NewPacket(rev.Payload, slayers.LayerTypeSCION, gopacket.Default)
+		assert.NotNil(t, quote.Layer(slayers.LayerTypeSCION))
+	})
+	t.Run("deleted revocation is forwarded", func(t *testing.T) {
+		dp := prepareDP()
+		require.NoError(t, dp.SetRevocation(3, time.Now().Add(time.Minute), sRevInfo))
+		dp.DelRevocation(3)
+		_, err := process(t, dp)
+		assert.NoError(t, err)
+	})
+	t.Run("expired revocation is forwarded", func(t *testing.T) {
+		dp := prepareDP()
+		require.NoError(t, dp.SetRevocation(3, time.Now().Add(-time.Second), sRevInfo))
+		_, err := process(t, dp)
+		assert.NoError(t, err)
+	})
+	t.Run("expired revocations are removed", func(t *testing.T) {
+		dp := prepareDP()
+		require.NoError(t, dp.SetRevocation(3, time.Now().Add(-time.Second), sRevInfo))
+		require.NoError(t, dp.SetRevocation(4, time.Now().Add(time.Minute), sRevInfo))
+		assert.Equal(t, map[uint16]struct{}{4: {}}, dp.RevokedInterfaces())
+	})
+	t.Run("revoking the internal interface is not allowed", func(t *testing.T) {
+		dp := prepareDP()
+		assert.Error(t, dp.SetRevocation(0, time.Now().Add(time.Minute), sRevInfo))
+	})
+	t.Run("revocation is required", func(t *testing.T) {
+		dp := prepareDP()
+		assert.Error(t, dp.SetRevocation(3, time.Now().Add(time.Minute), nil))
+	})
+}
+
+func TestDataPlanePolicing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	prepareDP := func(policing router.Policing) *router.DataPlane {
+		dp := &router.DataPlane{Policing: policing}
+		require.NoError(t, dp.AddInternalInterface(mock_router.NewMockBatchConn(ctrl),
+			net.ParseIP("10.0.200.1").To4()))
+		require.NoError(t, dp.AddNextHop(3, &net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()}))
+		require.NoError(t, dp.SetIA(local))
+		require.NoError(t, dp.SetKey(0, key))
+		return dp
+	}
+	now := time.Now()
+	clock := func() time.Time { return now }
+	process := func(t *testing.T, dp *router.DataPlane, policer *router.IngressPolicer,
+		srcIA addr.IA) (gopacket.Packet, error) {
+
+		spkt, dpath := prepBaseMsg()
+		spkt.SrcIA = srcIA
+		_ = spkt.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+		dpath.HopFields = []*path.HopField{
+			{ConsIngress: 31, ConsEgress: 30},
+			{ConsIngress: 1, ConsEgress: 3},
+			{ConsIngress: 50, ConsEgress: 51},
+		}
+		dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+		msg := toMsg(t, spkt, dpath)
+		origMsg := make([]byte, len(msg.Buffers[0]))
+		copy(origMsg, msg.Buffers[0])
+		_, err := dp.ProcessPktPoliced(1, msg, slayers.SCION{}, origMsg,
+			gopacket.NewSerializeBuffer(), policer)
+		return gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION, gopacket.Default), err
+	}
+	src := xtest.MustParseIA("2-ff00:0:222")
+
+	t.Run("interface limit", func(t *testing.T) {
+		dp := prepareDP(router.Policing{Interface: router.RateLimit{Rate: 1, Burst: 2}})
+		policer := dp.NewPolicer(1, clock)
+		for i := 0; i < 2; i++ {
+			_, err := process(t, dp, policer, src)
+			assert.NoError(t, err)
+		}
+		pkt, err := process(t, dp, policer, src)
+		assert.Error(t, err)
+		assert.Nil(t, pkt.Layer(slayers.LayerTypeSCMP))
+
+		now = now.Add(time.Second)
+		_, err = process(t, dp, policer, src)
+		assert.NoError(t, err)
+	})
+	t.Run("interface override", func(t *testing.T) {
+		dp := prepareDP(router.Policing{
+			Interface:  router.RateLimit{Rate: 100},
+			Interfaces: map[uint16]router.RateLimit{1: {Rate: 1, Burst: 1}},
+		})
+		policer := dp.NewPolicer(1, clock)
+		_, err := process(t, dp, policer, src)
+		assert.NoError(t, err)
+		_, err = process(t, dp, policer, src)
+		assert.Error(t, err)
+	})
+	t.Run("source IA limit is shared by all interfaces", func(t *testing.T) {
+		dp := prepareDP(router.Policing{SourceIA: router.RateLimit{Rate: 1, Burst: 1}})
+		_, err := process(t, dp, dp.NewPolicer(1, clock), src)
+		assert.NoError(t, err)
+		_, err = process(t, dp, dp.NewPolicer(1, clock), src)
+		assert.Error(t, err)
+		_, err = process(t, dp, dp.NewPolicer(1, clock), xtest.MustParseIA("2-ff00:0:223"))
+		assert.NoError(t, err)
+	})
+	t.Run("over-limit packets are signaled with SCMP", func(t *testing.T) {
+		dp := prepareDP(router.Policing{
+			Interface:  router.RateLimit{Rate: 1, Burst: 1},
+			SCMP:       router.RateLimit{Rate: 1, Burst: 1},
+			SignalSCMP: true,
+		})
+		policer := dp.NewPolicer(1, clock)
+		_, err := process(t, dp, policer, src)
+		assert.NoError(t, err)
+
+		pkt, err := process(t, dp, policer, src)
+		assert.Error(t, err)
+		scmpL := pkt.Layer(slayers.LayerTypeSCMP)
+		require.NotNil(t, scmpL)
+		assert.Equal(t, slayers.CreateSCMPTypeCode(slayers.SCMPTypeDestinationUnreachable,
+			slayers.SCMPCodeAdminDeny), scmpL.(*slayers.SCMP).TypeCode)
+
+		// The SCMP rate limit is exceeded, the packet is dropped silently.
+		pkt, err = process(t, dp, policer, src)
+		assert.Error(t, err)
+		assert.Nil(t, pkt.Layer(slayers.LayerTypeSCMP))
+	})
+}
+
+func TestDataPlanePathValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	local := xtest.MustParseIA("1-ff00:0:110")
+	child := xtest.MustParseIA("1-ff00:0:111")
+	prepareDP := func() *router.DataPlane {
+		dp := &router.DataPlane{}
+		require.NoError(t, dp.AddInternalInterface(mock_router.NewMockBatchConn(ctrl),
+			net.ParseIP("10.0.200.1").To4()))
+		require.NoError(t, dp.AddExternalInterface(1, mock_router.NewMockBatchConn(ctrl)))
+		require.NoError(t, dp.AddNeighborIA(1, child))
+		require.NoError(t, dp.AddLinkType(1, topology.Child))
+		require.NoError(t, dp.AddNextHop(3, &net.IPAddr{IP: net.ParseIP("10.0.200.200").To4()}))
+		require.NoError(t, dp.SetIA(local))
+		require.NoError(t, dp.SetKey(0, key))
+		return dp
+	}
+	// The packets enter from the child on interface 1 against construction
+	// direction, and leave towards interface 3. The address header is 24 bytes
+	// long, so the path starts at byte 36.
+	prepareMsg := func(t *testing.T, modify func(*slayers.SCION, *scion.Decoded)) *ipv4.Message {
+		spkt, dpath := prepBaseMsg()
+		spkt.SrcIA = child
+		_ = spkt.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+		dpath.InfoFields[0].ConsDir = false
+		dpath.HopFields = []*path.HopField{
+			{ConsIngress: 40, ConsEgress: 0},
+			{ConsIngress: 3, ConsEgress: 1},
+			{ConsIngress: 0, ConsEgress: 41},
+		}
+		modify(spkt, dpath)
+		meta := dpath.PathMeta
+		if int(meta.CurrHF) < len(dpath.HopFields) && int(meta.CurrINF) < len(dpath.InfoFields) {
+			info, hop := dpath.InfoFields[meta.CurrINF], dpath.HopFields[meta.CurrHF]
+			hop.Mac = computeMAC(t, key, info, hop)
+			info.UpdateSegID(hop.Mac)
+		}
+		return toMsg(t, spkt, dpath)
+	}
+
+	testCases := map[string]struct {
+		Modify  func(*slayers.SCION, *scion.Decoded)
+		Code    slayers.SCMPCode
+		Pointer uint16
+		// Forwarded indicates that the packet is valid.
+		Forwarded bool
+		// Dropped indicates that the packet is dropped without an SCMP error.
+		Dropped bool
+	}{
+		"valid packet from child": {
+			Modify:    func(*slayers.SCION, *scion.Decoded) {},
+			Forwarded: true,
+		},
+		"source behind child": {
+			Modify: func(spkt *slayers.SCION, dpath *scion.Decoded) {
+				spkt.SrcIA = xtest.MustParseIA("1-ff00:0:112")
+				dpath.PathMeta.SegLen[0] = 4
+				dpath.PathMeta.CurrHF = 2
+				dpath.NumHops = 4
+				dpath.HopFields = append([]*path.HopField{{ConsIngress: 50}},
+					dpath.HopFields...)
+			},
+			Forwarded: true,
+		},
+		"source not child on first hop": {
+			Modify: func(spkt *slayers.SCION, _ *scion.Decoded) {
+				spkt.SrcIA = xtest.MustParseIA("1-ff00:0:112")
+			},
+			Code:    slayers.SCMPCodeInvalidSourceAddress,
+			Pointer: 20,
+		},
+		"source from other ISD": {
+			Modify: func(spkt *slayers.SCION, dpath *scion.Decoded) {
+				spkt.SrcIA = xtest.MustParseIA("2-ff00:0:222")
+				dpath.PathMeta.SegLen[0] = 4
+				dpath.PathMeta.CurrHF = 2
+				dpath.NumHops = 4
+				dpath.HopFields = append([]*path.HopField{{ConsIngress: 50}},
+					dpath.HopFields...)
+			},
+			Code:    slayers.SCMPCodeInvalidSourceAddress,
+			Pointer: 20,
+		},
+		"invalid source address": {
+			Modify: func(spkt *slayers.SCION, _ *scion.Decoded) {
+				spkt.SrcAddrLen = slayers.AddrLen8
+			},
+			Dropped: true,
+		},
+		"invalid destination address": {
+			Modify: func(spkt *slayers.SCION, dpath *scion.Decoded) {
+				spkt.DstIA = local
+				spkt.DstAddrLen = slayers.AddrLen8
+				dpath.HopFields[1] = &path.HopField{ConsIngress: 0, ConsEgress: 1}
+				dpath.HopFields = dpath.HopFields[:2]
+				dpath.PathMeta.SegLen[0] = 2
+				dpath.NumHops = 2
+			},
+			Code:    slayers.SCMPCodeInvalidDestinationAddress,
+			Pointer: 9,
+		},
+		"path ends before destination": {
+			Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+				dpath.HopFields[1] = &path.HopField{ConsIngress: 0, ConsEgress: 1}
+				dpath.HopFields = dpath.HopFields[:2]
+				dpath.PathMeta.SegLen[0] = 2
+				dpath.NumHops = 2
+			},
+			Code:    slayers.SCMPCodeNonLocalDelivery,
+			Pointer: 12,
+		},
+		"current info field does not match hop field": {
+			Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+				dpath.PathMeta.SegLen = [3]uint8{1, 2, 0}
+				dpath.NumINF = 2
+				dpath.InfoFields = append(dpath.InfoFields,
+					&path.InfoField{SegID: 0x222, Timestamp: dpath.InfoFields[0].Timestamp})
+			},
+			Code:    slayers.SCMPCodeInvalidPath,
+			Pointer: 36,
+		},
+		"peering flag on single segment": {
+			Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+				dpath.InfoFields[0].Peer = true
+			},
+			Code:    slayers.SCMPCodeInvalidPath,
+			Pointer: 40,
+		},
+		"current hop field out of range": {
+			Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+				dpath.PathMeta.CurrHF = 3
+			},
+			Dropped: true,
+		},
+		"too many hop fields": {
+			Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+				dpath.PathMeta.SegLen = [3]uint8{3, 62, 0}
+				dpath.NumINF = 2
+				dpath.NumHops = 65
+				dpath.InfoFields = append(dpath.InfoFields, &path.InfoField{})
+				for i := 0; i < 62; i++ {
+					dpath.HopFields = append(dpath.HopFields, &path.HopField{})
+				}
+			},
+			Dropped: true,
+		},
+		"segment lengths with holes": {
+			Modify:  segLenHoles,
+			Dropped: true,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			dp := prepareDP()
+			msg := prepareMsg(t, tc.Modify)
+			origMsg := append([]byte(nil), msg.Buffers[0]...)
+			_, err := dp.ProcessPkt(1, msg, slayers.SCION{}, origMsg,
+				gopacket.NewSerializeBuffer())
+			if tc.Forwarded {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			pkt := gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION, gopacket.Default)
+			if tc.Dropped {
+				assert.Nil(t, pkt.Layer(slayers.LayerTypeSCMP))
+				return
+			}
+			scmpL := pkt.Layer(slayers.LayerTypeSCMP)
+			require.NotNil(t, scmpL)
+			assert.Equal(t, slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				tc.Code), scmpL.(*slayers.SCMP).TypeCode)
+			pp := pkt.Layer(slayers.LayerTypeSCMPParameterProblem)
+			require.NotNil(t, pp)
+			assert.Equal(t, tc.Pointer, pp.(*slayers.SCMPParameterProblem).Pointer)
+		})
+	}
+
+	t.Run("from local host is answered over an empty path", func(t *testing.T) {
+		localCases := map[string]struct {
+			Modify  func(*slayers.SCION, *scion.Decoded)
+			Code    slayers.SCMPCode
+			Pointer uint16
+			Dst     string
+		}{
+			"current hop field out of range": {
+				Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+					dpath.PathMeta.CurrHF = 3
+				},
+				Code:    slayers.SCMPCodeInvalidPath,
+				Pointer: 36,
+				Dst:     "10.0.100.100",
+			},
+			"segment lengths exceed hop fields": {
+				Modify: func(_ *slayers.SCION, dpath *scion.Decoded) {
+					dpath.PathMeta.SegLen = [3]uint8{3, 62, 0}
+					dpath.NumINF = 2
+					dpath.NumHops = 65
+					dpath.InfoFields = append(dpath.InfoFields, &path.InfoField{})
+					for i := 0; i < 62; i++ {
+						dpath.HopFields = append(dpath.HopFields, &path.HopField{})
+					}
+				},
+				Code:    slayers.SCMPCodeInvalidPath,
+				Pointer: 36,
+				Dst:     "10.0.100.100",
+			},
+			"segment lengths with holes": {
+				Modify:  segLenHoles,
+				Code:    slayers.SCMPCodeInvalidPath,
+				Pointer: 36,
+				Dst:     "10.0.100.100",
+			},
+			"invalid source address": {
+				Modify: func(spkt *slayers.SCION, _ *scion.Decoded) {
+					spkt.SrcAddrLen = slayers.AddrLen8
+				},
+				Code:    slayers.SCMPCodeInvalidSourceAddress,
+				Pointer: 9,
+				// The host is addressed with the underlay address.
+				Dst: "10.0.100.101",
+			},
+		}
+		for name, tc := range localCases {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				dp := prepareDP()
+				msg := prepareMsg(t, func(spkt *slayers.SCION, dpath *scion.Decoded) {
+					spkt.SrcIA = local
+					tc.Modify(spkt, dpath)
+				})
+				msg.Addr = &net.UDPAddr{IP: net.ParseIP("10.0.100.101"), Port: 30041}
+				origMsg := append([]byte(nil), msg.Buffers[0]...)
+				_, err := dp.ProcessPkt(0, msg, slayers.SCION{}, origMsg,
+					gopacket.NewSerializeBuffer())
+				require.Error(t, err)
+				pkt := gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION,
+					gopacket.Default)
+				scionL := pkt.Layer(slayers.LayerTypeSCION)
+				require.NotNil(t, scionL)
+				assert.Equal(t, slayers.PathTypeEmpty, scionL.(*slayers.SCION).PathType)
+				dst, err := scionL.(*slayers.SCION).DstAddr()
+				require.NoError(t, err)
+				assert.Equal(t, tc.Dst, dst.String())
+				scmpL := pkt.Layer(slayers.LayerTypeSCMP)
+				require.NotNil(t, scmpL)
+				assert.Equal(t, slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+					tc.Code), scmpL.(*slayers.SCMP).TypeCode)
+				pp := pkt.Layer(slayers.LayerTypeSCMPParameterProblem)
+				require.NotNil(t, pp)
+				assert.Equal(t, tc.Pointer, pp.(*slayers.SCMPParameterProblem).Pointer)
+			})
+		}
+	})
+
+	t.Run("reversed one-hop path", func(t *testing.T) {
+		prepareOHP := func(t *testing.T) *ipv4.Message {
+			spkt, _ := prepBaseMsg()
+			spkt.PathType = slayers.PathTypeOneHop
+			spkt.SrcIA = local
+			_ = spkt.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+			dpath := &onehop.Path{
+				Info: path.InfoField{
+					ConsDir:   false,
+					SegID:     0x222,
+					Timestamp: util.TimeToSecs(time.Now()),
+				},
+				FirstHop: path.HopField{ExpTime: 63, ConsEgress: 1},
+			}
+			dpath.FirstHop.Mac = computeMAC(t, key, &dpath.Info, &dpath.FirstHop)
+			return toMsg(t, spkt, dpath)
+		}
+		t.Run("from local host is answered over an empty path", func(t *testing.T) {
+			dp := prepareDP()
+			msg := prepareOHP(t)
+			origMsg := append([]byte(nil), msg.Buffers[0]...)
+			_, err := dp.ProcessPkt(0, msg, slayers.SCION{}, origMsg,
+				gopacket.NewSerializeBuffer())
+			require.Error(t, err)
+			pkt := gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION, gopacket.Default)
+			scionL := pkt.Layer(slayers.LayerTypeSCION)
+			require.NotNil(t, scionL)
+			assert.Equal(t, slayers.PathTypeEmpty, scionL.(*slayers.SCION).PathType)
+			assert.Equal(t, local, scionL.(*slayers.SCION).DstIA)
+			dst, err := scionL.(*slayers.SCION).DstAddr()
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.100.100", dst.String())
+			scmpL := pkt.Layer(slayers.LayerTypeSCMP)
+			require.NotNil(t, scmpL)
+			assert.Equal(t, slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodeInvalidPath), scmpL.(*slayers.SCMP).TypeCode)
+			pp := pkt.Layer(slayers.LayerTypeSCMPParameterProblem)
+			require.NotNil(t, pp)
+			assert.Equal(t, uint16(36), pp.(*slayers.SCMPParameterProblem).Pointer)
+		})
+		t.Run("from neighbor is dropped", func(t *testing.T) {
+			dp := prepareDP()
+			msg := prepareOHP(t)
+			origMsg := append([]byte(nil), msg.Buffers[0]...)
+			_, err := dp.ProcessPkt(1, msg, slayers.SCION{}, origMsg,
+				gopacket.NewSerializeBuffer())
+			assert.Error(t, err)
+			assert.Equal(t, origMsg, msg.Buffers[0])
+		})
+	})
+}
+
+func TestProcessPktPeeringSCMP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := []byte("testkey_xxxxxxxx")
+	dp := &router.DataPlane{}
+	require.NoError(t, dp.AddInternalInterface(mock_router.NewMockBatchConn(ctrl),
+		net.ParseIP("10.0.200.1").To4()))
+	require.NoError(t, dp.AddExternalInterface(1, mock_router.NewMockBatchConn(ctrl)))
+	require.NoError(t, dp.SetIA(xtest.MustParseIA("1-ff00:0:110")))
+	require.NoError(t, dp.SetKey(0, key))
+
+	// The packet enters from the child on interface 1 and should leave over
+	// the unknown peering interface 2.
+	spkt, _ := prepPeeringMsg()
+	_ = spkt.SetSrcAddr(&net.IPAddr{IP: net.ParseIP("10.0.100.100").To4()})
+	dpath := prepPeeringPath(1, []*path.HopField{
+		{ConsIngress: 31, ConsEgress: 0},  // Src
+		{ConsIngress: 2, ConsEgress: 1},   // IA 110
+		{ConsIngress: 10, ConsEgress: 40}, // peer of IA 110
+		{ConsIngress: 50, ConsEgress: 0},  // Dst
+	})
+	dpath.HopFields[1].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[1])
+	segID := dpath.InfoFields[0].SegID
+	msg := toMsg(t, spkt, dpath)
+	origMsg := append([]byte(nil), msg.Buffers[0]...)
+
+	_, err := dp.ProcessPkt(1, msg, slayers.SCION{}, origMsg, gopacket.NewSerializeBuffer())
+	require.Error(t, err)
+	pkt := gopacket.NewPacket(msg.Buffers[0], slayers.LayerTypeSCION, gopacket.Default)
+	require.NotNil(t, pkt.Layer(slayers.LayerTypeSCMP))
+	scionL := pkt.Layer(slayers.LayerTypeSCION).(*slayers.SCION)
+	reply, err := scionL.Path.(*scion.Raw).ToDecoded()
+	require.NoError(t, err)
+
+	// The reply travels back to the child over the reversed path. The SegID is
+	// not updated by the peering hop field, the child hop field is chained to
+	// the same SegID.
+	assert.Equal(t, uint8(1), reply.PathMeta.CurrINF)
+	assert.Equal(t, uint8(3), reply.PathMeta.CurrHF)
+	assert.True(t, reply.InfoFields[1].ConsDir)
+	assert.Equal(t, segID, reply.InfoFields[1].SegID)
+}
+
+// segLenHoles sets segment lengths with a hole in the path. Such a path can not
+// be decoded.
+func segLenHoles(_ *slayers.SCION, dpath *scion.Decoded) {
+	dpath.PathMeta.SegLen = [3]uint8{2, 0, 1}
+	dpath.NumINF = 3
+	dpath.InfoFields = append(dpath.InfoFields, &path.InfoField{}, &path.InfoField{})
+}
+
 func toMsg(t *testing.T, spkt *slayers.SCION, dpath slayers.Path) *ipv4.Message {
 	t.Helper()
 	ret := &ipv4.Message{}
@@ -911,6 +2187,67 @@ func prepBaseMsg() (*slayers.SCION, *scion.Decoded) {
 	return spkt, dpath
 }
 
+// prepPeeringMsg returns a SCION header for a packet from 1-ff00:0:111 to
+// 1-ff00:0:112.
+func prepPeeringMsg() (*slayers.SCION, *scion.Decoded) {
+	spkt, dpath := prepBaseMsg()
+	spkt.SrcIA = xtest.MustParseIA("1-ff00:0:111")
+	spkt.DstIA = xtest.MustParseIA("1-ff00:0:112")
+	return spkt, dpath
+}
+
+// prepPeeringPath returns a path over a peering link that consists of an up
+// and a down segment with two hop fields each. The peering link is between the
+// hop fields 1 and 2.
+func prepPeeringPath(currHF uint8, hops []*path.HopField) *scion.Decoded {
+	ts := util.TimeToSecs(time.Now())
+	dpath := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: currHF,
+				SegLen: [3]uint8{2, 2, 0},
+			},
+			NumINF:  2,
+			NumHops: 4,
+		},
+		InfoFields: []*path.InfoField{
+			// up seg
+			{SegID: 0x111, ConsDir: false, Peer: true, Timestamp: ts},
+			// down seg
+			{SegID: 0x222, ConsDir: true, Peer: true, Timestamp: ts},
+		},
+		HopFields: hops,
+	}
+	if currHF >= 2 {
+		dpath.PathMeta.CurrINF = 1
+	}
+	return dpath
+}
+
+// prepEgressMsg returns a packet that arrives on the internal interface and
+// leaves the AS through interface 2, with the hop field MAC computed with the
+// given key.
+func prepEgressMsg(t *testing.T, local addr.IA,
+	key []byte) (*ipv4.Message, slayers.SCION, []byte) {
+
+	spkt, dpath := prepBaseMsg()
+	spkt.SrcIA = local
+	dpath.HopFields = []*path.HopField{
+		{ConsIngress: 0, ConsEgress: 2},
+		{ConsIngress: 31, ConsEgress: 30},
+		{ConsIngress: 41, ConsEgress: 40},
+	}
+	dpath.Base.PathMeta.CurrHF = 0
+	dpath.HopFields[0].Mac = computeMAC(t, key, dpath.InfoFields[0], dpath.HopFields[0])
+	spkt.Path = dpath
+	buffer := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true},
+		spkt, gopacket.Payload([]byte("actualpayloadbytes")))
+	require.NoError(t, err)
+	raw := buffer.Bytes()
+	return &ipv4.Message{Buffers: [][]byte{raw}, N: len(raw)}, *spkt, raw
+}
+
 func computeMAC(t *testing.T, key []byte, info *path.InfoField, hf *path.HopField) []byte {
 	mac, err := scrypto.InitMac(key)
 	require.NoError(t, err)
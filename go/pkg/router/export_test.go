@@ -4,8 +4,10 @@ package router
 
 import (
 	"net"
+	"time"
 
 	"github.com/google/gopacket"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"golang.org/x/net/ipv4"
 
 	"github.com/scionproto/scion/go/lib/addr"
@@ -21,21 +23,65 @@ func NewDP(
 	key []byte) *DataPlane {
 
 	dp := &DataPlane{
-		localIA:          local,
+		localIA:  local,
+		internal: i,
+	}
+	dp.state.Store(&forwardingState{
 		external:         e,
 		internalNextHops: iNextHops,
 		svc:              svc,
-		internal:         i,
-	}
-	dp.SetKey(key)
+	})
+	dp.SetKey(0, key)
 	return dp
 }
 
+// NewUnregisteredMetrics returns metrics that are not registered with any
+// registry, so that tests can create them multiple times.
+func NewUnregisteredMetrics() *Metrics {
+	return newMetrics(promauto.With(nil))
+}
+
+// RevokedInterfaces returns the interfaces with a revocation, including the
+// expired ones that were not removed yet.
+func (d *DataPlane) RevokedInterfaces() map[uint16]struct{} {
+	ifIDs := make(map[uint16]struct{})
+	d.revocations.Range(func(k, _ interface{}) bool {
+		ifIDs[k.(uint16)] = struct{}{}
+		return true
+	})
+	return ifIDs
+}
+
 func (d *DataPlane) FakeStart() {
 	d.running = true
+	d.metrics = NewUnregisteredMetrics()
+	s := d.forwarding().copy()
+	d.initMetrics(s)
+	d.state.Store(s)
 }
 
 func (d *DataPlane) ProcessPkt(ifID uint16, m *ipv4.Message, s slayers.SCION,
 	origPacket []byte, b gopacket.SerializeBuffer) (BatchConn, error) {
-	return d.processPkt(ifID, m, s, origPacket, b)
+	return d.processPkt(ifID, m, s, origPacket, b, nil)
+}
+
+type IngressPolicer = ingressPolicer
+
+// NewPolicer returns the policer of the given ingress interface. The returned
+// policer uses the given function as clock. The source ISD-AS policer is shared
+// by all policers of the dataplane.
+func (d *DataPlane) NewPolicer(ifID uint16, now func() time.Time) *IngressPolicer {
+	if d.iaPolicer == nil {
+		d.iaPolicer = newIAPolicer(d.Policing.SourceIA)
+	}
+	p := d.newIngressPolicer(ifID)
+	p.ingress = newTokenBucket(d.Policing.ingressLimit(ifID), now())
+	p.scmp = newTokenBucket(d.Policing.SCMP, now())
+	p.now = now
+	return p
+}
+
+func (d *DataPlane) ProcessPktPoliced(ifID uint16, m *ipv4.Message, s slayers.SCION,
+	origPacket []byte, b gopacket.SerializeBuffer, p *IngressPolicer) (BatchConn, error) {
+	return d.processPkt(ifID, m, s, origPacket, b, p)
 }
@@ -15,8 +15,12 @@
 package router
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/scionproto/scion/go/lib/addr"
 )
 
 // Metrics defines the data-plane metrics for the BR.
@@ -28,60 +32,173 @@ type Metrics struct {
 	InputErrorsTotal         *prometheus.CounterVec
 	OutputErrorsTotal        *prometheus.CounterVec
 	InputDroppedPacketsTotal *prometheus.CounterVec
+	DroppedPacketsTotal      *prometheus.CounterVec
 }
 
 // NewMetrics initializes the metrics for the Border Router, and registers them
 // with the default registry.
 func NewMetrics() *Metrics {
+	return newMetrics(promauto.With(prometheus.DefaultRegisterer))
+}
+
+func newMetrics(f promauto.Factory) *Metrics {
 	return &Metrics{
-		InputBytesTotal: promauto.NewCounterVec(
+		InputBytesTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_input_bytes_total",
 				Help: "Total number of bytes received",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		OutputBytesTotal: promauto.NewCounterVec(
+		OutputBytesTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_output_bytes_total",
 				Help: "Total number of bytes sent.",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		InputPacketsTotal: promauto.NewCounterVec(
+		InputPacketsTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_input_pkts_total",
 				Help: "Total number of packets received",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		OutputPacketsTotal: promauto.NewCounterVec(
+		OutputPacketsTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_output_pkts_total",
 				Help: "Total number of packets sent.",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		InputErrorsTotal: promauto.NewCounterVec(
+		InputErrorsTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_input_read_errors_total",
 				Help: "Total number of input socket read errors",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		OutputErrorsTotal: promauto.NewCounterVec(
+		OutputErrorsTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_output_write_errors_total",
 				Help: "Total number of output socket write errors.",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
-		InputDroppedPacketsTotal: promauto.NewCounterVec(
+		InputDroppedPacketsTotal: f.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "router_input_dropped_pkts_total",
 				Help: "Total number of packets dropped by kernel due to receive buffer overflow",
 			},
 			[]string{"interface", "isd_as", "neighbor_isd_as"},
 		),
+		DroppedPacketsTotal: f.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "router_dropped_pkts_total",
+				Help: "Total number of packets dropped by the router processing, by reason.",
+			},
+			[]string{"interface", "isd_as", "neighbor_isd_as", "reason"},
+		),
+	}
+}
+
+// Reasons for which the router drops packets during processing. They are used
+// as the value of the reason label of the router_dropped_pkts_total metric.
+const (
+	// dropReasonInvalidMAC is used if the MAC of the current hop field does not
+	// verify.
+	dropReasonInvalidMAC = "invalid_mac"
+	// dropReasonExpiredHop is used if the current hop field is expired.
+	dropReasonExpiredHop = "expired_hop"
+	// dropReasonUnknownEgress is used if the egress interface can not be
+	// resolved.
+	dropReasonUnknownEgress = "unknown_egress"
+	// dropReasonBFDDown is used if the BFD session of the egress interface is
+	// down.
+	dropReasonBFDDown = "bfd_down"
+	// dropReasonRevokedInterface is used if the egress interface is revoked.
+	dropReasonRevokedInterface = "revoked_interface"
+	// dropReasonPolicedInterface is used if the packet exceeds the rate limit
+	// of its ingress interface.
+	dropReasonPolicedInterface = "policed_interface"
+	// dropReasonPolicedSourceIA is used if the packet exceeds the rate limit of
+	// its source ISD-AS.
+	dropReasonPolicedSourceIA = "policed_source_ia"
+	// dropReasonInvalidPath is used if the path violates the SCION path
+	// specification.
+	dropReasonInvalidPath = "invalid_path"
+	// dropReasonInvalidAddress is used if the source or destination host
+	// address has an invalid type or length.
+	dropReasonInvalidAddress = "invalid_address"
+	// dropReasonInvalidSourceIA is used if a packet from a child AS has a
+	// source ISD-AS that the child AS can not have sent it from.
+	dropReasonInvalidSourceIA = "invalid_source_ia"
+	// dropReasonSCMPRateLimited is used if the packet should have been answered
+	// with an SCMP message, but the SCMP rate limit was exceeded.
+	dropReasonSCMPRateLimited = "scmp_rate_limited"
+	// dropReasonSCMPGenerated is used if the packet was answered with an SCMP
+	// error for any other reason.
+	dropReasonSCMPGenerated = "scmp_generated"
+	// dropReasonProcessingError is used for all other processing errors.
+	dropReasonProcessingError = "processing_error"
+)
+
+var dropReasons = []string{
+	dropReasonInvalidMAC,
+	dropReasonExpiredHop,
+	dropReasonUnknownEgress,
+	dropReasonBFDDown,
+	dropReasonRevokedInterface,
+	dropReasonPolicedInterface,
+	dropReasonPolicedSourceIA,
+	dropReasonInvalidPath,
+	dropReasonInvalidAddress,
+	dropReasonInvalidSourceIA,
+	dropReasonSCMPRateLimited,
+	dropReasonSCMPGenerated,
+	dropReasonProcessingError,
+}
+
+// interfaceMetrics are the metrics of a single interface, with the labels
+// already resolved so that the packet loop doesn't have to look them up for
+// every packet.
+type interfaceMetrics struct {
+	InputBytesTotal          prometheus.Counter
+	OutputBytesTotal         prometheus.Counter
+	InputPacketsTotal        prometheus.Counter
+	OutputPacketsTotal       prometheus.Counter
+	InputErrorsTotal         prometheus.Counter
+	OutputErrorsTotal        prometheus.Counter
+	InputDroppedPacketsTotal prometheus.Counter
+	DroppedPacketsTotal      map[string]prometheus.Counter
+}
+
+func newInterfaceMetrics(m *Metrics, ifID uint16, localIA, neighborIA addr.IA) interfaceMetrics {
+	labels := prometheus.Labels{
+		"interface":       interfaceToMetricLabel(ifID),
+		"isd_as":          localIA.String(),
+		"neighbor_isd_as": neighborIA.String(),
+	}
+	im := interfaceMetrics{
+		InputBytesTotal:          m.InputBytesTotal.With(labels),
+		OutputBytesTotal:         m.OutputBytesTotal.With(labels),
+		InputPacketsTotal:        m.InputPacketsTotal.With(labels),
+		OutputPacketsTotal:       m.OutputPacketsTotal.With(labels),
+		InputErrorsTotal:         m.InputErrorsTotal.With(labels),
+		OutputErrorsTotal:        m.OutputErrorsTotal.With(labels),
+		InputDroppedPacketsTotal: m.InputDroppedPacketsTotal.With(labels),
+		DroppedPacketsTotal:      make(map[string]prometheus.Counter, len(dropReasons)),
+	}
+	for _, reason := range dropReasons {
+		im.DroppedPacketsTotal[reason] = m.DroppedPacketsTotal.MustCurryWith(labels).
+			WithLabelValues(reason)
+	}
+	return im
+}
+
+func interfaceToMetricLabel(ifID uint16) string {
+	if ifID == 0 {
+		return "internal"
 	}
+	return strconv.FormatUint(uint64(ifID), 10)
 }
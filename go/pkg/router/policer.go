@@ -0,0 +1,232 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+// maxPolicedIAs is the maximum number of source ISD-ASes that are policed
+// individually. The source IA is not authenticated, so the number of buckets
+// must be bounded.
+const maxPolicedIAs = 4096
+
+// RateLimit is the configuration of a token bucket.
+type RateLimit struct {
+	// Rate is the sustained rate in packets per second. A zero or negative
+	// rate disables the limit.
+	Rate float64
+	// Burst is the size of the bucket in packets. If it is zero, the bucket
+	// holds the packets of one second at the sustained rate.
+	Burst int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0
+}
+
+// Policing is the admission control configuration of the dataplane. The zero
+// value disables policing.
+type Policing struct {
+	// Interface is the limit that is applied to each ingress interface that is
+	// not listed in Interfaces.
+	Interface RateLimit
+	// Interfaces contains the limits of individual ingress interfaces. The
+	// internal interface has ID 0.
+	Interfaces map[uint16]RateLimit
+	// SourceIA is the limit that is applied to each source ISD-AS.
+	SourceIA RateLimit
+	// SCMP is the limit for the SCMP messages that are generated in response
+	// to the packets of an ingress interface.
+	SCMP RateLimit
+	// SignalSCMP indicates that over-limit packets are answered with an SCMP
+	// destination unreachable message instead of being dropped silently.
+	SignalSCMP bool
+}
+
+func (p Policing) ingressLimit(ifID uint16) RateLimit {
+	if l, ok := p.Interfaces[ifID]; ok {
+		return l
+	}
+	return p.Interface
+}
+
+// tokenBucket is a token bucket that counts packets. It is not safe for
+// concurrent use. A nil bucket never limits.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket for the given limit, or nil if the limit
+// is disabled.
+func newTokenBucket(l RateLimit, now time.Time) *tokenBucket {
+	if !l.enabled() {
+		return nil
+	}
+	burst := float64(l.Burst)
+	if burst == 0 {
+		burst = math.Max(1, math.Ceil(l.Rate))
+	}
+	return &tokenBucket{
+		rate:   l.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// allow takes a token from the bucket if one is available.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full indicates whether the bucket is full, i.e., it was not used for at
+// least burst/rate seconds.
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// iaPolicer polices packets per source ISD-AS. It is shared by all readers and
+// is safe for concurrent use.
+type iaPolicer struct {
+	limit RateLimit
+
+	mtx     sync.Mutex
+	buckets map[addr.IA]*tokenBucket
+	// overflow is shared by all ISD-ASes that can not get a bucket of their
+	// own because the maximum number of buckets is reached.
+	overflow  *tokenBucket
+	lastEvict time.Time
+}
+
+// newIAPolicer returns a policer for the given limit, or nil if the limit is
+// disabled.
+func newIAPolicer(l RateLimit) *iaPolicer {
+	if !l.enabled() {
+		return nil
+	}
+	return &iaPolicer{
+		limit:   l,
+		buckets: make(map[addr.IA]*tokenBucket),
+	}
+}
+
+func (p *iaPolicer) allow(ia addr.IA, now time.Time) bool {
+	if p == nil {
+		return true
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if b, ok := p.buckets[ia]; ok {
+		return b.allow(now)
+	}
+	if len(p.buckets) >= maxPolicedIAs {
+		p.evict(now)
+	}
+	if len(p.buckets) >= maxPolicedIAs {
+		if p.overflow == nil {
+			p.overflow = newTokenBucket(p.limit, now)
+		}
+		return p.overflow.allow(now)
+	}
+	b := newTokenBucket(p.limit, now)
+	p.buckets[ia] = b
+	return b.allow(now)
+}
+
+// evict removes the buckets of idle ISD-ASes. To bound the work done on the
+// packet path, it does so at most once per second.
+func (p *iaPolicer) evict(now time.Time) {
+	if now.Sub(p.lastEvict) < time.Second {
+		return
+	}
+	p.lastEvict = now
+	for ia, b := range p.buckets {
+		if b.full(now) {
+			delete(p.buckets, ia)
+		}
+	}
+}
+
+// ingressPolicer polices the packets that are read from a single ingress
+// interface. It is owned by the reader of that interface. A nil policer never
+// limits.
+type ingressPolicer struct {
+	ingress  *tokenBucket
+	scmp     *tokenBucket
+	sourceIA *iaPolicer
+	// signalSCMP indicates that over-limit packets are answered with SCMP.
+	signalSCMP bool
+	// now returns the current time, it can be replaced in tests.
+	now func() time.Time
+}
+
+func (d *DataPlane) newIngressPolicer(ifID uint16) *ingressPolicer {
+	now := time.Now()
+	return &ingressPolicer{
+		ingress:    newTokenBucket(d.Policing.ingressLimit(ifID), now),
+		scmp:       newTokenBucket(d.Policing.SCMP, now),
+		sourceIA:   d.iaPolicer,
+		signalSCMP: d.Policing.SignalSCMP,
+		now:        time.Now,
+	}
+}
+
+// admit checks the packet against the ingress and the source ISD-AS limits. It
+// returns nil if the packet is admitted.
+func (p *ingressPolicer) admit(srcIA addr.IA) error {
+	if p == nil {
+		return nil
+	}
+	now := p.now()
+	if !p.ingress.allow(now) {
+		return policedInterface
+	}
+	if !p.sourceIA.allow(srcIA, now) {
+		return policedSourceIA
+	}
+	return nil
+}
+
+// allowSCMP indicates whether an SCMP message may be generated.
+func (p *ingressPolicer) allowSCMP() bool {
+	if p == nil {
+		return true
+	}
+	return p.scmp.allow(p.now())
+}
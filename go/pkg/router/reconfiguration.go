@@ -0,0 +1,447 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"crypto/rand"
+	"hash"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/pkg/router/bfd"
+)
+
+// forwardingState is the part of the dataplane configuration that is used to
+// forward packets and that can be changed on a running dataplane. An installed
+// forwardingState is never modified. Changes are applied to a copy, which then
+// replaces the installed state atomically.
+type forwardingState struct {
+	external         map[uint16]BatchConn
+	neighborIAs      map[uint16]addr.IA
+	linkTypes        map[uint16]topology.LinkType
+	internalNextHops map[uint16]net.Addr
+	svc              map[addr.HostSVC][]net.Addr
+	// macFactories is the keyring used for hop field MACs, ordered from the
+	// current to the oldest key. Unset keys are nil.
+	macFactories [NumKeys]func() hash.Hash
+	bfdSessions  map[uint16]bfdSession
+	// metrics are the metrics per connection. They are only set on the state
+	// of a running dataplane.
+	metrics map[BatchConn]interfaceMetrics
+}
+
+// copy returns a copy of the state that can be modified without affecting s.
+// The SVC address lists are shared, they must be copied before they are
+// modified.
+func (s *forwardingState) copy() *forwardingState {
+	c := &forwardingState{
+		external:         make(map[uint16]BatchConn, len(s.external)),
+		neighborIAs:      make(map[uint16]addr.IA, len(s.neighborIAs)),
+		linkTypes:        make(map[uint16]topology.LinkType, len(s.linkTypes)),
+		internalNextHops: make(map[uint16]net.Addr, len(s.internalNextHops)),
+		svc:              make(map[addr.HostSVC][]net.Addr, len(s.svc)),
+		macFactories:     s.macFactories,
+		bfdSessions:      make(map[uint16]bfdSession, len(s.bfdSessions)),
+	}
+	for k, v := range s.external {
+		c.external[k] = v
+	}
+	for k, v := range s.neighborIAs {
+		c.neighborIAs[k] = v
+	}
+	for k, v := range s.linkTypes {
+		c.linkTypes[k] = v
+	}
+	for k, v := range s.internalNextHops {
+		c.internalNextHops[k] = v
+	}
+	for k, v := range s.svc {
+		c.svc[k] = v
+	}
+	for k, v := range s.bfdSessions {
+		c.bfdSessions[k] = v
+	}
+	return c
+}
+
+// newMAC returns a MAC with the current key. New hop fields are always created
+// with the current key.
+func (s *forwardingState) newMAC() hash.Hash {
+	return s.macFactories[0]()
+}
+
+// verifyMAC verifies the MAC of the hop field, trying the keys of the keyring
+// from the current to the oldest key. If no key matches, the error of the
+// current key is returned.
+func (s *forwardingState) verifyMAC(info *path.InfoField, hop *path.HopField) error {
+	var firstErr error
+	for _, f := range s.macFactories {
+		if f == nil {
+			continue
+		}
+		err := path.VerifyMAC(f(), info, hop)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return serrors.New("no MAC key set")
+	}
+	return firstErr
+}
+
+// neighborIA returns the IA of the neighbor on the given interface. For the
+// internal interface, and interfaces without a known neighbor, local is
+// returned.
+func (s *forwardingState) neighborIA(ifID uint16, local addr.IA) addr.IA {
+	if ia, ok := s.neighborIAs[ifID]; ok {
+		return ia
+	}
+	return local
+}
+
+// outputMetrics returns the metrics of the given connection. Packets sent on a
+// connection that is no longer part of the state are accounted to the internal
+// interface.
+func (s *forwardingState) outputMetrics(c, internal BatchConn) interfaceMetrics {
+	if m, ok := s.metrics[c]; ok {
+		return m
+	}
+	return s.metrics[internal]
+}
+
+// interfaceID returns the ID of the interface of the given connection. Packets
+// sent on any other connection than an external one are sent on the internal
+// interface.
+func (s *forwardingState) interfaceID(c BatchConn) uint16 {
+	for ifID, ext := range s.external {
+		if ext == c {
+			return ifID
+		}
+	}
+	return 0
+}
+
+// Reconfiguration is a set of changes to the forwarding configuration of a
+// dataplane. The changes only become visible to packet processing once the
+// reconfiguration is committed, and then all at once. A reconfiguration can be
+// used on a running dataplane.
+//
+// Only a single reconfiguration can be in progress at any time, a
+// reconfiguration must therefore always be committed or aborted. While a
+// reconfiguration is in progress, the other configuration methods of the
+// dataplane block.
+type Reconfiguration struct {
+	d     *DataPlane
+	state *forwardingState
+	done  bool
+}
+
+// BeginReconfiguration starts a new reconfiguration of the dataplane. It blocks
+// until a reconfiguration that is already in progress is finished.
+func (d *DataPlane) BeginReconfiguration() *Reconfiguration {
+	d.mtx.Lock()
+	return &Reconfiguration{
+		d:     d,
+		state: d.forwarding().copy(),
+	}
+}
+
+// Commit installs the changes of the reconfiguration. On a running dataplane,
+// the connections of added external interfaces are read from, and added BFD
+// sessions are started. The connections of removed external interfaces, and
+// removed BFD sessions are closed.
+func (r *Reconfiguration) Commit() error {
+	if r.done {
+		return serrors.New("reconfiguration already finished")
+	}
+	defer r.finish()
+
+	d := r.d
+	old, s := d.forwarding(), r.state
+	if d.running {
+		d.initMetrics(s)
+	}
+	d.state.Store(s)
+
+	if d.running {
+		for ifID, c := range s.external {
+			if old.external[ifID] != c {
+				d.runReader(ifID, c)
+			}
+		}
+		for ifID, c := range s.bfdSessions {
+			if !hasBFDSession(old, c) {
+				d.runBFDSession(ifID, c)
+			}
+		}
+	}
+	for ifID, c := range old.external {
+		if s.external[ifID] != c {
+			if err := c.Close(); err != nil {
+				log.Info("Closing removed interface", "if_id", ifID, "err", err)
+			}
+		}
+	}
+	for ifID, c := range old.bfdSessions {
+		if !hasBFDSession(s, c) {
+			if err := c.Close(); err != nil {
+				log.Info("Closing removed BFD session", "if_id", ifID, "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Abort discards the changes of the reconfiguration.
+func (r *Reconfiguration) Abort() {
+	if r.done {
+		return
+	}
+	r.finish()
+}
+
+func (r *Reconfiguration) finish() {
+	r.done = true
+	r.d.mtx.Unlock()
+}
+
+// hasBFDSession returns whether the BFD session is used by any interface of the
+// state. Sessions can be shared by multiple interfaces.
+func hasBFDSession(s *forwardingState, session bfdSession) bool {
+	for _, v := range s.bfdSessions {
+		if v == session {
+			return true
+		}
+	}
+	return false
+}
+
+// SetKey sets the key at the given index of the keyring, replacing the key
+// that is currently set at that index. Index 0 is the current key, higher
+// indices are previous keys. The key provided here should already be derived
+// as in scrypto.HFMacFactory.
+func (r *Reconfiguration) SetKey(index int, key []byte) error {
+	if index < 0 || index >= NumKeys {
+		return serrors.New("key index out of range", "index", index, "max", NumKeys-1)
+	}
+	if len(key) == 0 {
+		return emptyValue
+	}
+	// First check for MAC creation errors.
+	if _, err := scrypto.InitMac(key); err != nil {
+		return err
+	}
+	r.state.macFactories[index] = func() hash.Hash {
+		mac, _ := scrypto.InitMac(key)
+		return mac
+	}
+	return nil
+}
+
+// DelKey removes the key at the given index of the keyring. The current key at
+// index 0 can only be replaced, not removed. Removing an unset key is a no-op.
+func (r *Reconfiguration) DelKey(index int) error {
+	if index < 1 || index >= NumKeys {
+		return serrors.New("key index out of range", "index", index, "min", 1,
+			"max", NumKeys-1)
+	}
+	r.state.macFactories[index] = nil
+	return nil
+}
+
+// AddExternalInterface adds the inter AS connection for the given interface ID.
+// If a connection for the given ID is already set this method will return an
+// error.
+func (r *Reconfiguration) AddExternalInterface(ifID uint16, conn BatchConn) error {
+	if conn == nil {
+		return emptyValue
+	}
+	if _, exists := r.state.external[ifID]; exists {
+		return serrors.WithCtx(alreadySet, "ifID", ifID)
+	}
+	r.state.external[ifID] = conn
+	return nil
+}
+
+// DelExternalInterface removes the inter AS connection for the given interface
+// ID, together with its BFD session, neighbor IA and link type. The connection
+// is closed when the reconfiguration is committed.
+func (r *Reconfiguration) DelExternalInterface(ifID uint16) error {
+	if _, exists := r.state.external[ifID]; !exists {
+		return serrors.WithCtx(notFound, "ifID", ifID)
+	}
+	delete(r.state.external, ifID)
+	delete(r.state.neighborIAs, ifID)
+	delete(r.state.linkTypes, ifID)
+	delete(r.state.bfdSessions, ifID)
+	return nil
+}
+
+// AddNeighborIA adds the neighboring IA for a given interface ID. If an IA is
+// already set for the given ID this method will return an error.
+func (r *Reconfiguration) AddNeighborIA(ifID uint16, remote addr.IA) error {
+	if remote.IsZero() {
+		return emptyValue
+	}
+	if _, exists := r.state.neighborIAs[ifID]; exists {
+		return serrors.WithCtx(alreadySet, "ifID", ifID)
+	}
+	r.state.neighborIAs[ifID] = remote
+	return nil
+}
+
+// AddLinkType adds the type of the link of a given interface ID. If a link
+// type is already set for the given ID this method will return an error.
+func (r *Reconfiguration) AddLinkType(ifID uint16, linkTo topology.LinkType) error {
+	if linkTo == topology.Unset {
+		return emptyValue
+	}
+	if _, exists := r.state.linkTypes[ifID]; exists {
+		return serrors.WithCtx(alreadySet, "ifID", ifID)
+	}
+	r.state.linkTypes[ifID] = linkTo
+	return nil
+}
+
+// AddExternalInterfaceBFD adds the inter AS connection BFD session.
+func (r *Reconfiguration) AddExternalInterfaceBFD(ifID uint16, conn BatchConn) error {
+	if conn == nil {
+		return emptyValue
+	}
+	s := &bfdSend{
+		conn: conn,
+		addr: nil,
+	}
+	return r.addBFDController(ifID, s)
+}
+
+func (r *Reconfiguration) addBFDController(ifID uint16, s *bfdSend) error {
+	// TODO(karampok). add extra argument as BFD params{} to set the timers.
+	// TODO(karampok). make the local discriminator random.
+
+	// Generate random discriminator. It can't be zero.
+	discInt, err := rand.Int(rand.Reader, big.NewInt(0xfffffffe))
+	if err != nil {
+		return err
+	}
+	disc := layers.BFDDiscriminator(uint32(discInt.Uint64()) + 1)
+
+	r.state.bfdSessions[ifID] = &bfd.Session{
+		Sender:                s,
+		DetectMult:            3,
+		Logger:                log.New("component", "BFD"),
+		DesiredMinTxInterval:  1 * time.Millisecond,
+		RequiredMinRxInterval: 25 * time.Millisecond,
+		LocalDiscriminator:    disc,
+		ReceiveQueueSize:      10,
+	}
+	return nil
+}
+
+// AddSvc adds the address for the given SVC. This can be called multiple times
+// for the same service, with the address added to the list of addresses that
+// provide the service.
+func (r *Reconfiguration) AddSvc(svc addr.HostSVC, a net.Addr) error {
+	if a == nil {
+		return emptyValue
+	}
+	addrs := r.state.svc[svc]
+	r.state.svc[svc] = append(addrs[:len(addrs):len(addrs)], a)
+	return nil
+}
+
+// DelSvc removes the address for the given SVC.
+func (r *Reconfiguration) DelSvc(svc addr.HostSVC, a net.Addr) error {
+	if a == nil {
+		return emptyValue
+	}
+	addrs := r.state.svc[svc]
+	for i, v := range addrs {
+		if v.String() != a.String() {
+			continue
+		}
+		remaining := make([]net.Addr, 0, len(addrs)-1)
+		remaining = append(remaining, addrs[:i]...)
+		remaining = append(remaining, addrs[i+1:]...)
+		if len(remaining) == 0 {
+			delete(r.state.svc, svc)
+		} else {
+			r.state.svc[svc] = remaining
+		}
+		return nil
+	}
+	return serrors.WithCtx(notFound, "svc", svc, "addr", a)
+}
+
+// AddNextHop sets the next hop address for the given interface ID. If the
+// interface ID already has an address associated this operation fails.
+func (r *Reconfiguration) AddNextHop(ifID uint16, a net.Addr) error {
+	if a == nil {
+		return emptyValue
+	}
+	if _, exists := r.state.internalNextHops[ifID]; exists {
+		return serrors.WithCtx(alreadySet, "ifID", ifID)
+	}
+	r.state.internalNextHops[ifID] = a
+	return nil
+}
+
+// DelNextHop removes the next hop address for the given interface ID, together
+// with its BFD session.
+func (r *Reconfiguration) DelNextHop(ifID uint16) error {
+	if _, exists := r.state.internalNextHops[ifID]; !exists {
+		return serrors.WithCtx(notFound, "ifID", ifID)
+	}
+	delete(r.state.internalNextHops, ifID)
+	delete(r.state.bfdSessions, ifID)
+	return nil
+}
+
+// AddNextHopBFD adds the BFD session for the next hop address.
+// If the remote ifID belongs to an existing address, the existing
+// BFD session will be re-used.
+func (r *Reconfiguration) AddNextHopBFD(ifID uint16, a net.Addr) error {
+	if a == nil {
+		return emptyValue
+	}
+
+	for k, v := range r.state.internalNextHops {
+		if sameUnderlayAddr(v, a) {
+			if c, ok := r.state.bfdSessions[k]; ok {
+				r.state.bfdSessions[ifID] = c
+				return nil
+			}
+		}
+	}
+
+	s := &bfdSend{
+		conn: r.d.internal,
+		addr: a,
+	}
+	return r.addBFDController(ifID, s)
+}
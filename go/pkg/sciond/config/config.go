@@ -18,6 +18,7 @@ package config
 import (
 	"fmt"
 	"io"
+	"net"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/config"
@@ -30,7 +31,9 @@ import (
 )
 
 var (
-	DefaultQueryInterval = 5 * time.Minute
+	DefaultQueryInterval       = 5 * time.Minute
+	DefaultProbeInterval       = 30 * time.Second
+	DefaultProbeDestinationTTL = 10 * time.Minute
 )
 
 var _ config.Config = (*Config)(nil)
@@ -105,6 +108,27 @@ type SDConfig struct {
 	// QueryInterval specifies after how much time segments
 	// for a destination should be refetched.
 	QueryInterval util.DurWrap `toml:"query_interval,omitempty"`
+	// PathPolicies is the file that contains the named path policies that path
+	// requests can refer to. The file is parsed as YAML if it has the extension
+	// .yml or .yaml, and as JSON otherwise.
+	PathPolicies string `toml:"path_policies,omitempty"`
+	// ProbePaths enables probing the paths to recently requested destinations
+	// with SCMP echo requests. The health of the paths is reported in the path
+	// responses. Probing requires the SCION header v2.
+	ProbePaths bool `toml:"probe_paths,omitempty"`
+	// ProbeInterval is the interval at which the paths are probed.
+	ProbeInterval util.DurWrap `toml:"probe_interval,omitempty"`
+	// ProbeDestinationTTL is the time after the last request for which the
+	// paths to a destination are probed.
+	ProbeDestinationTTL util.DurWrap `toml:"probe_destination_ttl,omitempty"`
+	// ProbeLocalIP is the source IP address of the probes. It must not be a
+	// loopback address. If it is empty, the IP address of Address is used, or
+	// the local IP address that is used to reach a border router if Address is
+	// a loopback address.
+	ProbeLocalIP string `toml:"probe_local_ip,omitempty"`
+	// DemoteFailedPaths moves the paths whose last probe was lost to the end
+	// of the path responses.
+	DemoteFailedPaths bool `toml:"demote_failed_paths,omitempty"`
 }
 
 func (cfg *SDConfig) InitDefaults() {
@@ -114,12 +138,28 @@ func (cfg *SDConfig) InitDefaults() {
 	if cfg.QueryInterval.Duration == 0 {
 		cfg.QueryInterval.Duration = DefaultQueryInterval
 	}
+	if cfg.ProbeInterval.Duration == 0 {
+		cfg.ProbeInterval.Duration = DefaultProbeInterval
+	}
+	if cfg.ProbeDestinationTTL.Duration == 0 {
+		cfg.ProbeDestinationTTL.Duration = DefaultProbeDestinationTTL
+	}
 }
 
 func (cfg *SDConfig) Validate() error {
 	if cfg.QueryInterval.Duration == 0 {
 		return serrors.New("QueryInterval must not be zero")
 	}
+	if cfg.ProbeLocalIP != "" {
+		ip := net.ParseIP(cfg.ProbeLocalIP)
+		if ip == nil {
+			return serrors.New("invalid probe_local_ip", "ip", cfg.ProbeLocalIP)
+		}
+		// The replies to the probes are sent to this address.
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return serrors.New("probe_local_ip must be routable", "ip", cfg.ProbeLocalIP)
+		}
+	}
 	return nil
 }
 
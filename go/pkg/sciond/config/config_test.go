@@ -46,6 +46,10 @@ func InitTestConfig(cfg *Config) {
 
 func InitTestSDConfig(cfg *SDConfig) {
 	cfg.Address = "garbage"
+	cfg.PathPolicies = "garbage"
+	cfg.ProbePaths = true
+	cfg.ProbeLocalIP = "garbage"
+	cfg.DemoteFailedPaths = true
 }
 
 func CheckTestConfig(t *testing.T, cfg *Config, id string) {
@@ -59,4 +63,34 @@ func CheckTestConfig(t *testing.T, cfg *Config, id string) {
 func CheckTestSDConfig(t *testing.T, cfg *SDConfig, id string) {
 	assert.Equal(t, sciond.DefaultSCIONDAddress, cfg.Address)
 	assert.Equal(t, DefaultQueryInterval, cfg.QueryInterval.Duration)
+	assert.Empty(t, cfg.PathPolicies)
+	assert.False(t, cfg.ProbePaths)
+	assert.Equal(t, DefaultProbeInterval, cfg.ProbeInterval.Duration)
+	assert.Equal(t, DefaultProbeDestinationTTL, cfg.ProbeDestinationTTL.Duration)
+	assert.Empty(t, cfg.ProbeLocalIP)
+	assert.False(t, cfg.DemoteFailedPaths)
+}
+
+func TestSDConfigValidate(t *testing.T) {
+	testCases := map[string]struct {
+		ProbeLocalIP string
+		Assertion    assert.ErrorAssertionFunc
+	}{
+		"default":       {Assertion: assert.NoError},
+		"routable IP":   {ProbeLocalIP: "10.0.0.1", Assertion: assert.NoError},
+		"invalid IP":    {ProbeLocalIP: "garbage", Assertion: assert.Error},
+		"IPv4 loopback": {ProbeLocalIP: "127.0.0.1", Assertion: assert.Error},
+		"IPv6 loopback": {ProbeLocalIP: "::1", Assertion: assert.Error},
+		"unspecified":   {ProbeLocalIP: "0.0.0.0", Assertion: assert.Error},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var cfg SDConfig
+			cfg.InitDefaults()
+			cfg.ProbeLocalIP = tc.ProbeLocalIP
+			tc.Assertion(t, cfg.Validate())
+		})
+	}
 }
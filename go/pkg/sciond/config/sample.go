@@ -22,4 +22,31 @@ address = "127.0.0.1:30255"
 
 # The time after which segments for a destination are refetched. (default 5m)
 query_interval = "5m"
+
+# File with the named path policies that path requests can refer to. The file
+# is parsed as YAML if it has the extension .yml or .yaml, and as JSON
+# otherwise. (default "", i.e., no named path policies)
+path_policies = ""
+
+# Probe the paths to recently requested destinations with SCMP echo requests
+# and report their health in the path responses. Requires the SCION header
+# v2. (default false)
+probe_paths = false
+
+# The interval at which the paths are probed. (default 30s)
+probe_interval = "30s"
+
+# The time after the last request for which the paths to a destination are
+# probed. (default 10m)
+probe_destination_ttl = "10m"
+
+# The source IP address of the probes. It must not be a loopback address.
+# (default "", i.e., the IP address of the address of the SCION Daemon server
+# API, or the local IP address that is used to reach a border router of the
+# local AS if the former is a loopback address)
+probe_local_ip = ""
+
+# Move the paths whose last probe was lost to the end of the path responses.
+# (default false)
+demote_failed_paths = false
 `
@@ -108,7 +108,8 @@ func NewFetcher(cfg FetcherConfig) Fetcher {
 	}
 }
 
-// GetPaths fulfills the path request described by req.
+// GetPaths fulfills the path request described by req. The policy, the sort
+// key and the path limit of the request flags are applied to the paths.
 func (f *fetcher) GetPaths(ctx context.Context, req *sciond.PathReq) (*sciond.PathReply, error) {
 	// Check context
 	if _, ok := ctx.Deadline(); !ok {
@@ -131,6 +132,12 @@ func (f *fetcher) GetPaths(ctx context.Context, req *sciond.PathReq) (*sciond.Pa
 	default:
 		return &sciond.PathReply{ErrorCode: sciond.ErrorInternal}, err
 	}
+	// The limit is applied after the translation, which might drop paths.
+	cPaths = Rank(cPaths, req.Flags.Policy, req.Flags.Sort, 0)
+	if len(cPaths) == 0 {
+		// The policy removed all paths, this is a valid answer.
+		return &sciond.PathReply{ErrorCode: sciond.ErrorOk}, nil
+	}
 	var paths []sciond.PathReplyEntry
 	var errs serrors.List
 	for _, path := range cPaths {
@@ -147,6 +154,9 @@ func (f *fetcher) GetPaths(ctx context.Context, req *sciond.PathReq) (*sciond.Pa
 	if len(paths) == 0 {
 		return nil, serrors.New("no paths after translation", "errs", errs.ToError())
 	}
+	if req.Flags.MaxPaths > 0 && len(paths) > req.Flags.MaxPaths {
+		paths = paths[:req.Flags.MaxPaths]
+	}
 	return &sciond.PathReply{ErrorCode: sciond.ErrorOk, Entries: paths}, nil
 }
 
@@ -186,6 +196,7 @@ func (f *fetcher) translate(path *combinator.Path) (sciond.PathReplyEntry, error
 			Mtu:        path.Mtu,
 			Interfaces: path.Interfaces,
 			ExpTime:    uint32(path.ComputeExpTime().Unix()),
+			Hops:       HopMetadata(path.Interfaces, path.StaticInfo),
 			HeaderV2:   path.HeaderV2,
 		},
 		HostInfo: hostinfo.FromUDPAddr(*nextHop),
@@ -16,7 +16,9 @@ package fetcher
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/infra/modules/combinator"
 	"github.com/scionproto/scion/go/lib/pathpol"
@@ -34,6 +36,50 @@ func Filter(paths []*combinator.Path, policy Policy) []*combinator.Path {
 	return psToPaths(policy.Filter(pathsToPs(paths)))
 }
 
+// Rank filters the given paths with the given policy and orders them by the
+// sort key. If the sort key is empty, the sort attribute of the policy is used.
+// Paths that are ranked equally keep their order. At most maxPaths paths are
+// returned, zero means no limit. A nil policy does not remove any paths.
+func Rank(paths []*combinator.Path, policy *pathpol.Policy, key pathpol.SortKey,
+	maxPaths int) []*combinator.Path {
+
+	wrapped := make([]pathWrap, 0, len(paths))
+	for _, path := range paths {
+		wrapped = append(wrapped, newPathWrap(path))
+	}
+	if policy != nil {
+		ps := make(pathpol.PathSet, len(wrapped))
+		for _, wp := range wrapped {
+			ps[wp.key] = wp
+		}
+		ps = policy.Filter(ps)
+		kept := wrapped[:0]
+		for _, wp := range wrapped {
+			if _, ok := ps[wp.key]; ok {
+				kept = append(kept, wp)
+			}
+		}
+		wrapped = kept
+		if key == "" {
+			key = policy.Sort
+		}
+	}
+	if key != "" {
+		sorter := &pathpol.Policy{Sort: key}
+		sort.SliceStable(wrapped, func(i, j int) bool {
+			return sorter.Less(wrapped[i], wrapped[j])
+		})
+	}
+	if maxPaths > 0 && len(wrapped) > maxPaths {
+		wrapped = wrapped[:maxPaths]
+	}
+	result := make([]*combinator.Path, 0, len(wrapped))
+	for _, wp := range wrapped {
+		result = append(result, wp.origPath)
+	}
+	return result
+}
+
 func pathsToPs(paths []*combinator.Path) pathpol.PathSet {
 	ps := make(pathpol.PathSet, len(paths))
 	for _, path := range paths {
@@ -54,6 +100,7 @@ func psToPaths(ps pathpol.PathSet) []*combinator.Path {
 type pathWrap struct {
 	key      snet.PathFingerprint
 	intfs    []snet.PathInterface
+	meta     pathMeta
 	origPath *combinator.Path
 }
 
@@ -65,10 +112,27 @@ func newPathWrap(p *combinator.Path) pathWrap {
 		keyParts = append(keyParts, fmt.Sprintf("%s#%d", intf.IA(), intf.ID()))
 	}
 	return pathWrap{
-		key:      snet.PathFingerprint(strings.Join(keyParts, " ")),
-		intfs:    intfs,
+		key:   snet.PathFingerprint(strings.Join(keyParts, " ")),
+		intfs: intfs,
+		meta: pathMeta{
+			mtu:    p.Mtu,
+			expiry: p.ComputeExpTime(),
+			hops:   HopMetadata(p.Interfaces, p.StaticInfo),
+		},
 		origPath: p,
 	}
 }
 
 func (p pathWrap) Interfaces() []snet.PathInterface { return p.intfs }
+func (p pathWrap) Metadata() snet.PathMetadata      { return p.meta }
+
+// pathMeta exposes the metadata of a combinator path to path policies.
+type pathMeta struct {
+	mtu    uint16
+	expiry time.Time
+	hops   []snet.HopMetadata
+}
+
+func (m pathMeta) MTU() uint16              { return m.mtu }
+func (m pathMeta) Expiry() time.Time        { return m.expiry }
+func (m pathMeta) Hops() []snet.HopMetadata { return m.hops }
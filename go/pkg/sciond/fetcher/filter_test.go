@@ -15,11 +15,13 @@
 package fetcher_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
@@ -82,3 +84,52 @@ func TestFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestRank(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	g := graph.NewDefaultGraph(ctrl)
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	seg110To120 := g.Beacon([]common.IFIDType{graph.If_110_X_120_A})
+	seg110To130 := g.Beacon([]common.IFIDType{graph.If_110_X_130_A})
+	seg120To111 := g.Beacon([]common.IFIDType{graph.If_120_X_111_B})
+	seg130To111 := g.Beacon([]common.IFIDType{graph.If_130_B_111_A})
+
+	paths := combinator.Combine(ia111, ia110,
+		[]*seg.PathSegment{seg120To111, seg130To111},
+		[]*seg.PathSegment{seg110To120, seg110To130},
+		nil)
+	require.Len(t, paths, 2)
+	// The paths to 1-ff00:0:120 are shorter than the paths to 1-ff00:0:110.
+	short := combinator.Combine(ia111, xtest.MustParseIA("1-ff00:0:120"),
+		[]*seg.PathSegment{seg120To111}, nil, nil)
+	require.Len(t, short, 1)
+	without120 := combinator.Combine(ia111, ia110,
+		[]*seg.PathSegment{seg130To111},
+		[]*seg.PathSegment{seg110To130},
+		nil)
+	var deny120 pathpol.Policy
+	require.NoError(t, json.Unmarshal([]byte(`{"acl": ["- 1-ff00:0:120#0", "+"]}`), &deny120))
+
+	t.Run("no policy", func(t *testing.T) {
+		assert.Equal(t, paths, fetcher.Rank(paths, nil, "", 0))
+	})
+	t.Run("max paths", func(t *testing.T) {
+		assert.Equal(t, paths[:1], fetcher.Rank(paths, nil, "", 1))
+	})
+	t.Run("policy", func(t *testing.T) {
+		assert.ElementsMatch(t, without120, fetcher.Rank(paths, &deny120, "", 0))
+	})
+	t.Run("sort", func(t *testing.T) {
+		mixed := append(append([]*combinator.Path{}, paths...), short...)
+		expected := append(append([]*combinator.Path{}, short...), paths...)
+		assert.Equal(t, expected, fetcher.Rank(mixed, nil, pathpol.SortByHops, 0))
+		policy := &pathpol.Policy{Sort: pathpol.SortByHops}
+		assert.Equal(t, expected, fetcher.Rank(mixed, policy, "", 0),
+			"the sort attribute of the policy is used")
+		policy = &pathpol.Policy{Sort: pathpol.SortByBandwidth}
+		assert.Equal(t, expected, fetcher.Rank(mixed, policy, pathpol.SortByHops, 0),
+			"the sort key takes precedence")
+	})
+}
@@ -3,9 +3,12 @@ package fetcher
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/infra/modules/combinator"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
 )
 
 // PathMetadata is the condensed form of metadata retaining only the most important values.
@@ -135,6 +138,85 @@ func Condensemetadata(data *combinator.PathMetadata) *PathMetadata {
 	return ret
 }
 
+// HopMetadata returns the static metadata of the ASes on the path described by
+// the interfaces, in path order. ASes for which the metadata contains no
+// information are omitted.
+func HopMetadata(interfaces []sciond.PathInterface,
+	data *combinator.PathMetadata) []snet.HopMetadata {
+
+	if data == nil {
+		return nil
+	}
+	var hops []snet.HopMetadata
+	for _, ia := range pathIAs(interfaces) {
+		hop := snet.HopMetadata{IA: ia}
+		var found bool
+		if lat, ok := data.ASLatencies[ia]; ok {
+			hop.InternalLatency = time.Duration(lat.IntraLatency) * time.Millisecond
+			hop.LinkLatency = time.Duration(lat.InterLatency) * time.Millisecond
+			hop.PeeringLatency = time.Duration(lat.PeerLatency) * time.Millisecond
+			found = true
+		}
+		if bw, ok := data.ASBandwidths[ia]; ok {
+			hop.InternalBandwidth = uint64(bw.IntraBW)
+			hop.LinkBandwidth = uint64(bw.InterBW)
+			found = true
+		}
+		if link, ok := data.Links[ia]; ok {
+			hop.LinkType = linkType(link.InterLinkType)
+			// The zero value encodes a direct link, thus only consider the
+			// peering link type if the path crosses a peering link.
+			if link.PeerLinkType != 0 || hop.PeeringLatency != 0 {
+				hop.PeeringLinkType = linkType(link.PeerLinkType)
+			}
+			found = true
+		}
+		if h, ok := data.ASHops[ia]; ok {
+			hop.InternalHops = uint32(h.Hops)
+			found = true
+		}
+		if geo, ok := data.Geo[ia]; ok {
+			for _, loc := range geo.Locations {
+				hop.Geo = append(hop.Geo, snet.GeoCoordinates{
+					Latitude:  loc.Latitude,
+					Longitude: loc.Longitude,
+					Address:   loc.Address,
+				})
+			}
+			found = true
+		}
+		if note, ok := data.Notes[ia]; ok {
+			hop.Note = note.Note
+			found = true
+		}
+		if found {
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// pathIAs returns the ISD-ASes that the interfaces belong to, in path order.
+func pathIAs(interfaces []sciond.PathInterface) []addr.IA {
+	var ias []addr.IA
+	for _, intf := range interfaces {
+		ia := intf.IA()
+		if len(ias) == 0 || ias[len(ias)-1] != ia {
+			ias = append(ias, ia)
+		}
+	}
+	return ias
+}
+
+// linkType converts the link type as it is encoded in the StaticInfo beacon
+// extension (direct=0, multihop=1, opennet=2) to the snet link type.
+func linkType(t uint16) snet.LinkType {
+	if t > 2 {
+		return snet.LinkTypeUnset
+	}
+	return snet.LinkType(t + 1)
+}
+
 func min(a, b uint32) uint32 {
 	if a < b {
 		return a
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -12,6 +13,8 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
 	"github.com/scionproto/scion/go/lib/infra/modules/combinator"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/xtest"
 	"github.com/scionproto/scion/go/lib/xtest/graph"
 )
@@ -503,6 +506,67 @@ func TestCollectMetadata(t *testing.T) {
 	}
 }
 
+func TestHopMetadata(t *testing.T) {
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	ia112 := xtest.MustParseIA("1-ff00:0:112")
+	interfaces := []sciond.PathInterface{
+		{RawIsdas: ia110.IAInt(), IfID: 1},
+		{RawIsdas: ia111.IAInt(), IfID: 2},
+		{RawIsdas: ia111.IAInt(), IfID: 3},
+		{RawIsdas: ia112.IAInt(), IfID: 4},
+	}
+	assert.Nil(t, HopMetadata(interfaces, nil))
+	data := &combinator.PathMetadata{
+		ASLatencies: map[addr.IA]combinator.ASLatency{
+			ia110: {InterLatency: 10},
+			ia111: {IntraLatency: 2, InterLatency: 20, PeerLatency: 5},
+		},
+		ASBandwidths: map[addr.IA]combinator.ASBandwidth{
+			ia112: {IntraBW: 1000, InterBW: 500},
+		},
+		ASHops: map[addr.IA]combinator.ASHops{
+			ia111: {Hops: 3},
+		},
+		Geo: map[addr.IA]combinator.ASGeo{
+			ia110: {Locations: []combinator.GeoLoc{{Latitude: 47.37, Longitude: 8.54,
+				Address: "Zürich, CH"}}},
+		},
+		Links: map[addr.IA]combinator.ASLink{
+			ia110: {InterLinkType: 0},
+			ia111: {InterLinkType: 1, PeerLinkType: 2},
+		},
+		Notes: map[addr.IA]combinator.ASnote{
+			ia112: {Note: "destination"},
+		},
+	}
+	expected := []snet.HopMetadata{
+		{
+			IA:          ia110,
+			LinkLatency: 10 * time.Millisecond,
+			LinkType:    snet.LinkTypeDirect,
+			Geo: []snet.GeoCoordinates{{Latitude: 47.37, Longitude: 8.54,
+				Address: "Zürich, CH"}},
+		},
+		{
+			IA:              ia111,
+			InternalLatency: 2 * time.Millisecond,
+			LinkLatency:     20 * time.Millisecond,
+			PeeringLatency:  5 * time.Millisecond,
+			LinkType:        snet.LinkTypeMultihop,
+			PeeringLinkType: snet.LinkTypeOpennet,
+			InternalHops:    3,
+		},
+		{
+			IA:                ia112,
+			InternalBandwidth: 1000,
+			LinkBandwidth:     500,
+			Note:              "destination",
+		},
+	}
+	assert.Equal(t, expected, HopMetadata(interfaces, data))
+}
+
 func calcBWmin(ifids []common.IFIDType) uint32 {
 	var BW uint32 = math.MaxUint32
 	for _, val := range ifids {
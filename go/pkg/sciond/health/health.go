@@ -0,0 +1,155 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health keeps track of the health of the paths served by SCIOND.
+//
+// The destinations of path requests are recorded in the Cache. The Prober
+// periodically probes the paths to the recently requested destinations and
+// records the round trip time, the loss and the last time the paths were alive
+// in the Cache, from where they are added to the path responses.
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+)
+
+// Status is the health of a path.
+type Status struct {
+	// RTT is the smoothed round trip time of the probes. It is zero if no
+	// probe reply was received yet.
+	RTT time.Duration
+	// Loss is the smoothed ratio of lost probes, between 0 and 1.
+	Loss float64
+	// LastAlive is the last time a probe reply was received over the path.
+	LastAlive time.Time
+	// LastProbed is the last time the path was probed.
+	LastProbed time.Time
+	// ConsecutiveLost is the number of probes lost since the last reply.
+	ConsecutiveLost int
+	// Probes is the number of probes sent over the path.
+	Probes int
+}
+
+// Failed returns whether the last probe over the path was lost.
+func (s Status) Failed() bool {
+	return s.ConsecutiveLost > 0
+}
+
+// update adds the result of a probe to the status. The RTT and the loss are
+// smoothed like the path statistics of the multipath sender.
+func (s *Status) update(result multipath.ProbeResult, now time.Time) {
+	s.Probes++
+	s.LastProbed = now
+	lost := 0.0
+	if result.Err != nil {
+		lost = 1
+		s.ConsecutiveLost++
+	} else {
+		s.ConsecutiveLost = 0
+		s.LastAlive = now
+		if s.RTT == 0 {
+			s.RTT = result.RTT
+		} else {
+			s.RTT = s.RTT - s.RTT/8 + result.RTT/8
+		}
+	}
+	if s.Probes == 1 {
+		s.Loss = lost
+	} else {
+		s.Loss = s.Loss*7/8 + lost/8
+	}
+}
+
+// Cache stores the health of paths, identified by their fingerprint, and the
+// destinations that were recently requested. It is safe for concurrent use.
+// The zero value is ready for use.
+type Cache struct {
+	mtx   sync.Mutex
+	paths map[snet.PathFingerprint]*Status
+	dsts  map[addr.IA]time.Time
+}
+
+// Requested records that paths to the destination were requested.
+func (c *Cache) Requested(dst addr.IA) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.dsts == nil {
+		c.dsts = make(map[addr.IA]time.Time)
+	}
+	c.dsts[dst] = time.Now()
+}
+
+// Destinations returns the destinations that were requested and not yet
+// expired.
+func (c *Cache) Destinations() []addr.IA {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	dsts := make([]addr.IA, 0, len(c.dsts))
+	for dst := range c.dsts {
+		dsts = append(dsts, dst)
+	}
+	return dsts
+}
+
+// Status returns the health of the path with the given fingerprint. The
+// second return value is false if the path was not probed.
+func (c *Cache) Status(fingerprint snet.PathFingerprint) (Status, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	s, ok := c.paths[fingerprint]
+	if !ok {
+		return Status{}, false
+	}
+	return *s, true
+}
+
+// Update adds the result of probing the path with the given fingerprint at
+// the given time.
+func (c *Cache) Update(fingerprint snet.PathFingerprint, result multipath.ProbeResult,
+	now time.Time) {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.paths == nil {
+		c.paths = make(map[snet.PathFingerprint]*Status)
+	}
+	s, ok := c.paths[fingerprint]
+	if !ok {
+		s = &Status{}
+		c.paths[fingerprint] = s
+	}
+	s.update(result, now)
+}
+
+// Expire removes the destinations that were last requested before the given
+// time, and the paths that were last probed before the given time.
+func (c *Cache) Expire(before time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for dst, requested := range c.dsts {
+		if requested.Before(before) {
+			delete(c.dsts, dst)
+		}
+	}
+	for fingerprint, s := range c.paths {
+		if s.LastProbed.Before(before) {
+			delete(c.paths, fingerprint)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/sciond/health"
+)
+
+func TestCache(t *testing.T) {
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	fp := snet.PathFingerprint("a")
+
+	var c health.Cache
+	_, ok := c.Status(fp)
+	assert.False(t, ok)
+
+	start := time.Now()
+	c.Update(fp, multipath.ProbeResult{RTT: 80 * time.Millisecond}, start)
+	c.Update(fp, multipath.ProbeResult{RTT: 160 * time.Millisecond}, start.Add(time.Second))
+	status, ok := c.Status(fp)
+	require.True(t, ok)
+	assert.Equal(t, 90*time.Millisecond, status.RTT)
+	assert.Equal(t, 0.0, status.Loss)
+	assert.Equal(t, start.Add(time.Second), status.LastAlive)
+	assert.False(t, status.Failed())
+
+	c.Update(fp, multipath.ProbeResult{Err: multipath.ErrProbeTimeout}, start.Add(2*time.Second))
+	status, ok = c.Status(fp)
+	require.True(t, ok)
+	assert.Equal(t, 90*time.Millisecond, status.RTT)
+	assert.Equal(t, 0.125, status.Loss)
+	assert.Equal(t, start.Add(time.Second), status.LastAlive)
+	assert.Equal(t, start.Add(2*time.Second), status.LastProbed)
+	assert.True(t, status.Failed())
+
+	c.Requested(ia110)
+	c.Requested(ia111)
+	assert.ElementsMatch(t, []addr.IA{ia110, ia111}, c.Destinations())
+
+	// Nothing is expired yet.
+	c.Expire(start)
+	assert.Len(t, c.Destinations(), 2)
+	_, ok = c.Status(fp)
+	assert.True(t, ok)
+
+	c.Expire(time.Now().Add(time.Hour))
+	assert.Empty(t, c.Destinations())
+	_, ok = c.Status(fp)
+	assert.False(t, ok)
+}
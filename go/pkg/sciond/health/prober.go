@@ -0,0 +1,118 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+	"github.com/scionproto/scion/go/pkg/sciond/fetcher"
+)
+
+const (
+	// DefaultDestinationTTL is the default time after the last request for
+	// which the paths to a destination are probed.
+	DefaultDestinationTTL = 10 * time.Minute
+)
+
+var _ periodic.Task = (*Prober)(nil)
+
+// Prober periodically probes the paths to the recently requested destinations
+// and records the results in the cache.
+type Prober struct {
+	// Cache contains the requested destinations and stores the results.
+	Cache *Cache
+	// Fetcher is used to get the paths to the destinations.
+	Fetcher fetcher.Fetcher
+	// Prober probes the paths. The remote passed to the prober only contains
+	// the destination ISD-AS, the prober must choose the destination host,
+	// e.g., the control service of the destination AS.
+	Prober multipath.Prober
+	// DestinationTTL is the time after the last request for which the paths
+	// to a destination are probed. If it is zero, DefaultDestinationTTL is
+	// used.
+	DestinationTTL time.Duration
+}
+
+// Name returns the name of the task.
+func (p *Prober) Name() string {
+	return "sd_path_prober"
+}
+
+// Run probes the paths to all recently requested destinations once.
+func (p *Prober) Run(ctx context.Context) {
+	ttl := p.DestinationTTL
+	if ttl == 0 {
+		ttl = DefaultDestinationTTL
+	}
+	p.Cache.Expire(time.Now().Add(-ttl))
+
+	logger := log.FromCtx(ctx)
+	var wg sync.WaitGroup
+	for _, dst := range p.Cache.Destinations() {
+		dst := dst
+		wg.Add(1)
+		go func() {
+			defer log.HandlePanic()
+			defer wg.Done()
+			if err := p.probe(ctx, dst); err != nil {
+				logger.Info("Failed to probe paths", "dst", dst, "err", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Prober) probe(ctx context.Context, dst addr.IA) error {
+	reply, err := p.Fetcher.GetPaths(ctx, &sciond.PathReq{Dst: dst.IAInt()})
+	if err != nil {
+		return serrors.WrapStr("fetching paths", err)
+	}
+	paths, err := sciond.PathReplyToPaths(reply, dst)
+	if err != nil {
+		return err
+	}
+	// Empty paths, i.e., paths in the local AS, are not probed.
+	probed := paths[:0]
+	for _, path := range paths {
+		if len(path.Interfaces()) > 0 {
+			probed = append(probed, path)
+		}
+	}
+	if len(probed) == 0 {
+		return nil
+	}
+	results, err := p.Prober.Probe(ctx, &snet.UDPAddr{IA: dst}, probed)
+	if err != nil {
+		return serrors.WrapStr("probing paths", err)
+	}
+	if len(results) != len(probed) {
+		return serrors.New("prober returned wrong number of results",
+			"expected", len(probed), "actual", len(results))
+	}
+	now := time.Now()
+	for i, path := range probed {
+		p.Cache.Update(snet.Fingerprint(path), results[i], now)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/pkg/sciond/fetcher/mock_fetcher"
+	"github.com/scionproto/scion/go/pkg/sciond/health"
+)
+
+func TestProberRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	entry := func(ifID int) sciond.PathReplyEntry {
+		return sciond.PathReplyEntry{
+			Path: &sciond.FwdPathMeta{
+				FwdPath: []byte{byte(ifID)},
+				Interfaces: []sciond.PathInterface{
+					{RawIsdas: ia110.IAInt(), IfID: 1},
+					{RawIsdas: ia111.IAInt(), IfID: 1},
+				},
+				HeaderV2: true,
+			},
+			HostInfo: hostinfo.FromUDPAddr(net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}),
+		}
+	}
+	up, down := entry(1), entry(2)
+	down.Path.Interfaces[1].IfID = 2
+	local := sciond.PathReplyEntry{Path: &sciond.FwdPathMeta{}}
+
+	f := mock_fetcher.NewMockFetcher(ctrl)
+	f.EXPECT().GetPaths(gomock.Any(), &sciond.PathReq{Dst: ia111.IAInt()}).Return(
+		&sciond.PathReply{
+			ErrorCode: sciond.ErrorOk,
+			Entries:   []sciond.PathReplyEntry{up, local, down},
+		}, nil,
+	)
+	prober := &fakeProber{results: []multipath.ProbeResult{
+		{RTT: 10 * time.Millisecond},
+		{Err: multipath.ErrProbeTimeout},
+	}}
+	cache := &health.Cache{}
+	cache.Requested(ia111)
+	p := &health.Prober{
+		Cache:   cache,
+		Fetcher: f,
+		Prober:  prober,
+	}
+	p.Run(context.Background())
+
+	// The empty path is not probed.
+	require.Len(t, prober.paths, 2)
+	assert.Equal(t, ia111, prober.remote.IA)
+	status, ok := cache.Status(snet.Fingerprint(prober.paths[0]))
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Millisecond, status.RTT)
+	assert.False(t, status.Failed())
+	status, ok = cache.Status(snet.Fingerprint(prober.paths[1]))
+	require.True(t, ok)
+	assert.True(t, status.Failed())
+	assert.True(t, status.LastAlive.IsZero())
+
+	// Destinations that were not requested recently are not probed anymore.
+	p.DestinationTTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	p.Run(context.Background())
+	assert.Empty(t, cache.Destinations())
+}
+
+type fakeProber struct {
+	results []multipath.ProbeResult
+	remote  *snet.UDPAddr
+	paths   []snet.Path
+}
+
+func (p *fakeProber) Probe(_ context.Context, remote *snet.UDPAddr,
+	paths []snet.Path) ([]multipath.ProbeResult, error) {
+
+	p.remote, p.paths = remote, paths
+	return p.results, nil
+}
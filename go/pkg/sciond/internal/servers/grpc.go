@@ -16,34 +16,65 @@ package servers
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	timestamppb "github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/revcache"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/lib/util"
 	sdpb "github.com/scionproto/scion/go/pkg/proto/daemon"
 	"github.com/scionproto/scion/go/pkg/sciond/fetcher"
+	"github.com/scionproto/scion/go/pkg/sciond/health"
 	"github.com/scionproto/scion/go/pkg/trust"
 	"github.com/scionproto/scion/go/proto"
 )
 
+const (
+	// watchExpiryMargin is the time before the expiration of a path at which
+	// the paths of a path watcher are reevaluated.
+	watchExpiryMargin = time.Minute
+	// watchRetryInterval is the time after which the paths of a path watcher
+	// are reevaluated if they could not be fetched or if there are none.
+	watchRetryInterval = 10 * time.Second
+)
+
 // DaemonServer handles gRPC requests to the SCION daemon.
 type DaemonServer struct {
 	Fetcher      fetcher.Fetcher
 	TopoProvider topology.Provider
 	RevCache     revcache.RevCache
 	ASInspector  trust.Inspector
+	// PathUpdates notifies about changes of the paths. If it is nil, path
+	// watches are not supported.
+	PathUpdates *pathdb.Notifier
+	// PathPolicies are the named path policies that path requests can refer
+	// to.
+	PathPolicies pathpol.PolicyMap
+	// PathHealth records the requested destinations and contains the health
+	// of the probed paths. If it is nil, the health of the paths is not
+	// reported.
+	PathHealth *health.Cache
+	// DemoteFailedPaths moves the paths whose last probe was lost to the end
+	// of the path responses.
+	DemoteFailedPaths bool
 
 	Metrics Metrics
 }
@@ -70,14 +101,25 @@ func (s DaemonServer) paths(ctx context.Context,
 		ctx, cancelF = context.WithTimeout(ctx, 10*time.Second)
 		defer cancelF()
 	}
+	flags, err := s.pathReqFlags(req)
+	if err != nil {
+		log.FromCtx(ctx).Info("Invalid path request", "err", err, "req", req)
+		return nil, metricsError{err: err, result: prom.ErrInvalidReq}
+	}
 	srcIA, dstIA := addr.IAInt(req.SourceIsdAs).IA(), addr.IAInt(req.DestinationIsdAs).IA()
+	maxPaths := flags.MaxPaths
+	demote := s.PathHealth != nil && s.DemoteFailedPaths
+	if demote {
+		// The limit is applied after the failed paths are demoted.
+		flags.MaxPaths = 0
+	}
 	iReq := &sciond.PathReq{
-		Src: srcIA.IAInt(),
-		Dst: dstIA.IAInt(),
-		Flags: sciond.PathReqFlags{
-			Hidden:  req.Hidden,
-			Refresh: req.Refresh,
-		},
+		Src:   srcIA.IAInt(),
+		Dst:   dstIA.IAInt(),
+		Flags: flags,
+	}
+	if s.PathHealth != nil {
+		s.PathHealth.Requested(dstIA)
 	}
 	go func() {
 		defer log.HandlePanic()
@@ -93,8 +135,15 @@ func (s DaemonServer) paths(ctx context.Context,
 			"code", getPathsReply.ErrorCode, "req", req)
 		return nil, serrors.New("error code", "code", getPathsReply.ErrorCode)
 	}
+	entries := getPathsReply.Entries
+	if demote {
+		entries = s.demoteFailed(entries)
+		if maxPaths > 0 && len(entries) > maxPaths {
+			entries = entries[:maxPaths]
+		}
+	}
 	reply := &sdpb.PathsResponse{}
-	for _, p := range getPathsReply.Entries {
+	for _, p := range entries {
 		var interfaces []*sdpb.PathInterface
 		for _, intf := range p.Path.Interfaces {
 			interfaces = append(interfaces, &sdpb.PathInterface{
@@ -110,12 +159,248 @@ func (s DaemonServer) paths(ctx context.Context,
 			Interfaces: interfaces,
 			Mtu:        uint32(p.Path.Mtu),
 			Expiration: &timestamppb.Timestamp{Seconds: int64(p.Path.ExpTime)},
+			Hops:       hopsToPB(p.Path.Hops),
+			Health:     s.pathHealth(p),
 			HeaderV2:   p.Path.HeaderV2,
 		})
 	}
 	return reply, nil
 }
 
+// pathHealth returns the health of the path, or nil if the path was not
+// probed.
+func (s DaemonServer) pathHealth(entry sciond.PathReplyEntry) *sdpb.PathHealth {
+	if s.PathHealth == nil {
+		return nil
+	}
+	status, ok := s.PathHealth.Status(snet.Fingerprint(replyInterfaces(entry.Path.Interfaces)))
+	if !ok {
+		return nil
+	}
+	h := &sdpb.PathHealth{
+		Loss:       status.Loss,
+		LastProbed: toTimestamp(status.LastProbed),
+		Failed:     status.Failed(),
+	}
+	if status.RTT != 0 {
+		h.Rtt = ptypes.DurationProto(status.RTT)
+	}
+	if !status.LastAlive.IsZero() {
+		h.LastAlive = toTimestamp(status.LastAlive)
+	}
+	return h
+}
+
+// demoteFailed moves the paths whose last probe was lost to the end. The
+// order of the paths is kept otherwise.
+func (s DaemonServer) demoteFailed(entries []sciond.PathReplyEntry) []sciond.PathReplyEntry {
+	result := make([]sciond.PathReplyEntry, 0, len(entries))
+	var failed []sciond.PathReplyEntry
+	for _, entry := range entries {
+		status, ok := s.PathHealth.Status(
+			snet.Fingerprint(replyInterfaces(entry.Path.Interfaces)))
+		if ok && status.Failed() {
+			failed = append(failed, entry)
+			continue
+		}
+		result = append(result, entry)
+	}
+	return append(result, failed...)
+}
+
+// replyInterfaces allows computing the fingerprint of a path reply entry.
+type replyInterfaces []sciond.PathInterface
+
+func (r replyInterfaces) Interfaces() []snet.PathInterface {
+	intfs := make([]snet.PathInterface, 0, len(r))
+	for _, intf := range r {
+		intfs = append(intfs, intf)
+	}
+	return intfs
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	return &timestamppb.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// WatchPaths streams the paths to the requested destination. The paths are
+// sent whenever they change, i.e., when new segments are inserted in the path
+// DB, when an interface is reported down, or when paths are about to expire.
+func (s DaemonServer) WatchPaths(req *sdpb.WatchPathsRequest,
+	stream sdpb.DaemonService_WatchPathsServer) error {
+
+	if s.PathUpdates == nil {
+		return status.Error(codes.Unimplemented, "path watches not supported")
+	}
+	pathsReq := &sdpb.PathsRequest{
+		SourceIsdAs:      req.SourceIsdAs,
+		DestinationIsdAs: req.DestinationIsdAs,
+		Hidden:           req.Hidden,
+		Policy:           req.Policy,
+		PolicyName:       req.PolicyName,
+		MaxPaths:         req.MaxPaths,
+		Sort:             req.Sort,
+	}
+	// Reject invalid requests immediately instead of retrying them.
+	if _, err := s.pathReqFlags(pathsReq); err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	updates, cancel := s.PathUpdates.Subscribe()
+	defer cancel()
+
+	var last *sdpb.WatchPathsResponse
+	for {
+		wait := watchRetryInterval
+		reqCtx, cancelF := context.WithTimeout(ctx, 10*time.Second)
+		reply, err := s.paths(reqCtx, pathsReq)
+		cancelF()
+		if err != nil {
+			log.FromCtx(ctx).Info("Fetching paths for watch", "err", err, "req", req)
+		} else {
+			response := &sdpb.WatchPathsResponse{Paths: reply.Paths}
+			// Changes of the path health alone do not trigger an update.
+			if last == nil || !protobuf.Equal(withoutHealth(last), withoutHealth(response)) {
+				if err := stream.Send(response); err != nil {
+					return err
+				}
+				last = response
+			}
+			wait = nextPathsUpdate(reply.Paths, time.Now())
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-updates:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// withoutHealth returns a copy of the response without the health of the
+// paths.
+func withoutHealth(response *sdpb.WatchPathsResponse) *sdpb.WatchPathsResponse {
+	c := protobuf.Clone(response).(*sdpb.WatchPathsResponse)
+	for _, path := range c.Paths {
+		path.Health = nil
+	}
+	return c
+}
+
+// pathReqFlags returns the flags of the path request. The path policy of the
+// request is compiled, it may extend the configured path policies.
+func (s DaemonServer) pathReqFlags(req *sdpb.PathsRequest) (sciond.PathReqFlags, error) {
+	flags := sciond.PathReqFlags{
+		Hidden:   req.Hidden,
+		Refresh:  req.Refresh,
+		MaxPaths: int(req.MaxPaths),
+	}
+	sortKey, ok := sortKeys[req.Sort]
+	if !ok {
+		return sciond.PathReqFlags{}, status.Errorf(codes.InvalidArgument,
+			"unsupported sort key: %v", req.Sort)
+	}
+	flags.Sort = sortKey
+	if len(req.Policy) == 0 && req.PolicyName == "" {
+		return flags, nil
+	}
+	ext := &pathpol.ExtPolicy{}
+	if len(req.Policy) > 0 {
+		if err := json.Unmarshal(req.Policy, ext); err != nil {
+			return sciond.PathReqFlags{}, status.Errorf(codes.InvalidArgument,
+				"parsing path policy: %v", err)
+		}
+	}
+	if req.PolicyName != "" {
+		if _, ok := s.PathPolicies[req.PolicyName]; !ok {
+			return sciond.PathReqFlags{}, status.Errorf(codes.NotFound,
+				"unknown path policy: %s", req.PolicyName)
+		}
+		// The attributes of the request policy take precedence.
+		ext.Extends = append([]string{req.PolicyName}, ext.Extends...)
+	}
+	policy, err := s.PathPolicies.Compile(ext)
+	if err != nil {
+		return sciond.PathReqFlags{}, status.Errorf(codes.InvalidArgument,
+			"compiling path policy: %v", err)
+	}
+	flags.Policy = policy
+	return flags, nil
+}
+
+var sortKeys = map[sdpb.PathSortKey]pathpol.SortKey{
+	sdpb.PathSortKey_PATH_SORT_KEY_UNSPECIFIED: "",
+	sdpb.PathSortKey_PATH_SORT_KEY_HOPS:        pathpol.SortByHops,
+	sdpb.PathSortKey_PATH_SORT_KEY_MTU:         pathpol.SortByMTU,
+	sdpb.PathSortKey_PATH_SORT_KEY_EXPIRY:      pathpol.SortByExpiry,
+	sdpb.PathSortKey_PATH_SORT_KEY_LATENCY:     pathpol.SortByLatency,
+	sdpb.PathSortKey_PATH_SORT_KEY_BANDWIDTH:   pathpol.SortByBandwidth,
+}
+
+// nextPathsUpdate returns the time until the paths must be reevaluated because
+// one of them is about to expire.
+func nextPathsUpdate(paths []*sdpb.Path, now time.Time) time.Duration {
+	var earliest time.Time
+	for _, path := range paths {
+		expiry := time.Unix(path.GetExpiration().GetSeconds(), 0)
+		if earliest.IsZero() || expiry.Before(earliest) {
+			earliest = expiry
+		}
+	}
+	update := earliest.Add(-watchExpiryMargin)
+	if !update.After(now) {
+		update = earliest
+	}
+	if !update.After(now) {
+		return watchRetryInterval
+	}
+	return update.Sub(now)
+}
+
+func hopsToPB(hops []snet.HopMetadata) []*sdpb.HopMetadata {
+	var pbHops []*sdpb.HopMetadata
+	for _, hop := range hops {
+		var geo []*sdpb.GeoCoordinates
+		for _, loc := range hop.Geo {
+			geo = append(geo, &sdpb.GeoCoordinates{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Address:   loc.Address,
+			})
+		}
+		pbHops = append(pbHops, &sdpb.HopMetadata{
+			IsdAs:             uint64(hop.IA.IAInt()),
+			InternalLatency:   ptypes.DurationProto(hop.InternalLatency),
+			LinkLatency:       ptypes.DurationProto(hop.LinkLatency),
+			PeeringLatency:    ptypes.DurationProto(hop.PeeringLatency),
+			InternalBandwidth: hop.InternalBandwidth,
+			LinkBandwidth:     hop.LinkBandwidth,
+			LinkType:          linkTypeToPB(hop.LinkType),
+			PeeringLinkType:   linkTypeToPB(hop.PeeringLinkType),
+			InternalHops:      hop.InternalHops,
+			Geo:               geo,
+			Note:              hop.Note,
+		})
+	}
+	return pbHops
+}
+
+func linkTypeToPB(t snet.LinkType) sdpb.LinkType {
+	switch t {
+	case snet.LinkTypeDirect:
+		return sdpb.LinkType_LINK_TYPE_DIRECT
+	case snet.LinkTypeMultihop:
+		return sdpb.LinkType_LINK_TYPE_MULTI_HOP
+	case snet.LinkTypeOpennet:
+		return sdpb.LinkType_LINK_TYPE_OPEN_NET
+	default:
+		return sdpb.LinkType_LINK_TYPE_UNSPECIFIED
+	}
+}
+
 func (s DaemonServer) backgroundPaths(origCtx context.Context, req *sciond.PathReq) {
 	backgroundTimeout := 5 * time.Second
 	deadline, ok := origCtx.Deadline()
@@ -278,5 +563,8 @@ func (s DaemonServer) notifyInterfaceDown(ctx context.Context,
 			result: prom.ErrDB,
 		}
 	}
+	if s.PathUpdates != nil {
+		s.PathUpdates.Notify()
+	}
 	return &sdpb.NotifyInterfaceDownResponse{}, nil
 }
@@ -30,12 +30,14 @@ import (
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/metrics"
 	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/revcache"
 	"github.com/scionproto/scion/go/lib/serrors"
 	libgrpc "github.com/scionproto/scion/go/pkg/grpc"
 	sdpb "github.com/scionproto/scion/go/pkg/proto/daemon"
 	"github.com/scionproto/scion/go/pkg/sciond/fetcher"
+	"github.com/scionproto/scion/go/pkg/sciond/health"
 	"github.com/scionproto/scion/go/pkg/sciond/internal/servers"
 	"github.com/scionproto/scion/go/pkg/trust"
 	trustgrpc "github.com/scionproto/scion/go/pkg/trust/grpc"
@@ -94,6 +96,19 @@ type ServerCfg struct {
 	PathDB   pathdb.PathDB
 	RevCache revcache.RevCache
 	Engine   trust.Engine
+	// PathUpdates notifies about changes of the paths. It must be notified by
+	// the path DB, see pathdb.WithNotifier. If it is nil, path watches are
+	// not supported.
+	PathUpdates *pathdb.Notifier
+	// PathPolicies are the named path policies that path requests can refer
+	// to.
+	PathPolicies pathpol.PolicyMap
+	// PathHealth contains the health of the paths, see health.Prober. If it
+	// is nil, the health of the paths is not reported.
+	PathHealth *health.Cache
+	// DemoteFailedPaths moves the paths whose last probe was lost to the end
+	// of the path responses.
+	DemoteFailedPaths bool
 }
 
 // GRPCServer creates function that will serve the SCION daemon API via gRPC.
@@ -111,10 +126,14 @@ func GRPCServer(listen string, cfg ServerCfg) func() error {
 			),
 		)
 		sdpb.RegisterDaemonServiceServer(server, servers.DaemonServer{
-			Fetcher:      cfg.Fetcher,
-			ASInspector:  cfg.Engine.Inspector,
-			RevCache:     cfg.RevCache,
-			TopoProvider: itopo.Provider(),
+			Fetcher:           cfg.Fetcher,
+			ASInspector:       cfg.Engine.Inspector,
+			RevCache:          cfg.RevCache,
+			TopoProvider:      itopo.Provider(),
+			PathUpdates:       cfg.PathUpdates,
+			PathPolicies:      cfg.PathPolicies,
+			PathHealth:        cfg.PathHealth,
+			DemoteFailedPaths: cfg.DemoteFailedPaths,
 			Metrics: servers.Metrics{
 				PathsRequests: servers.RequestMetrics{
 					Requests: metrics.NewPromCounterFrom(prometheus.CounterOpts{
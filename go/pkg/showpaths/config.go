@@ -16,6 +16,8 @@ package showpaths
 
 import (
 	"net"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
 )
 
 // DefaultMaxPaths is the maximum number of paths that are displayed by default.
@@ -38,4 +40,14 @@ type Config struct {
 	// Sequence is a string of space separated Hop Predicates that is used for
 	// filtering.
 	Sequence string
+	// Extended configures whether the static metadata of the paths, e.g., the
+	// latency and bandwidth per hop, is included in the result.
+	Extended bool
+	// Policy is a path policy that the SCION Daemon applies to the paths.
+	Policy *pathpol.Policy
+	// PolicyName is the name of a path policy configured in the SCION Daemon
+	// that is applied to the paths.
+	PolicyName string
+	// Sort is the property by which the SCION Daemon orders the paths.
+	Sort pathpol.SortKey
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/util"
 	"github.com/scionproto/scion/go/pkg/app"
 )
 
@@ -53,6 +54,9 @@ type Path struct {
 	Status      string    `json:"status,omitempty"`
 	StatusInfo  string    `json:"status_info,omitempty"`
 	Local       net.IP    `json:"local_ip,omitempty"`
+	// Metadata is the static metadata of the ASes on the path. It is only
+	// set if the extended information is requested.
+	Metadata []HopMetadata `json:"metadata,omitempty"`
 }
 
 // Hop represents an hop on the path.
@@ -61,6 +65,103 @@ type Hop struct {
 	IA   addr.IA         `json:"isd_as"`
 }
 
+// HopMetadata is the static metadata of an AS on the path. Values that are not
+// advertised by the AS are omitted.
+type HopMetadata struct {
+	IA                addr.IA          `json:"isd_as"`
+	InternalLatency   *util.DurWrap    `json:"internal_latency,omitempty"`
+	LinkLatency       *util.DurWrap    `json:"link_latency,omitempty"`
+	PeeringLatency    *util.DurWrap    `json:"peering_latency,omitempty"`
+	InternalBandwidth uint64           `json:"internal_bandwidth_kbps,omitempty"`
+	LinkBandwidth     uint64           `json:"link_bandwidth_kbps,omitempty"`
+	LinkType          string           `json:"link_type,omitempty"`
+	PeeringLinkType   string           `json:"peering_link_type,omitempty"`
+	InternalHops      uint32           `json:"internal_hops,omitempty"`
+	Geo               []GeoCoordinates `json:"geo,omitempty"`
+	Note              string           `json:"note,omitempty"`
+}
+
+// GeoCoordinates is the location of a router.
+type GeoCoordinates struct {
+	Latitude  float32 `json:"latitude"`
+	Longitude float32 `json:"longitude"`
+	Address   string  `json:"address,omitempty"`
+}
+
+func newHopMetadata(hop snet.HopMetadata) HopMetadata {
+	latency := func(d time.Duration) *util.DurWrap {
+		if d == 0 {
+			return nil
+		}
+		return &util.DurWrap{Duration: d}
+	}
+	linkType := func(t snet.LinkType) string {
+		if t == snet.LinkTypeUnset {
+			return ""
+		}
+		return t.String()
+	}
+	md := HopMetadata{
+		IA:                hop.IA,
+		InternalLatency:   latency(hop.InternalLatency),
+		LinkLatency:       latency(hop.LinkLatency),
+		PeeringLatency:    latency(hop.PeeringLatency),
+		InternalBandwidth: hop.InternalBandwidth,
+		LinkBandwidth:     hop.LinkBandwidth,
+		LinkType:          linkType(hop.LinkType),
+		PeeringLinkType:   linkType(hop.PeeringLinkType),
+		InternalHops:      hop.InternalHops,
+		Note:              hop.Note,
+	}
+	for _, loc := range hop.Geo {
+		md.Geo = append(md.Geo, GeoCoordinates{
+			Latitude:  loc.Latitude,
+			Longitude: loc.Longitude,
+			Address:   loc.Address,
+		})
+	}
+	return md
+}
+
+// entries returns the human readable key value pairs of the metadata.
+func (m HopMetadata) entries() [][2]string {
+	var entries [][2]string
+	add := func(key string, value interface{}) {
+		entries = append(entries, [2]string{key, fmt.Sprint(value)})
+	}
+	if m.InternalLatency != nil {
+		add("InternalLatency", m.InternalLatency)
+	}
+	if m.LinkLatency != nil {
+		add("LinkLatency", m.LinkLatency)
+	}
+	if m.PeeringLatency != nil {
+		add("PeeringLatency", m.PeeringLatency)
+	}
+	if m.InternalBandwidth != 0 {
+		add("InternalBandwidth", fmt.Sprintf("%dKbit/s", m.InternalBandwidth))
+	}
+	if m.LinkBandwidth != 0 {
+		add("LinkBandwidth", fmt.Sprintf("%dKbit/s", m.LinkBandwidth))
+	}
+	if m.LinkType != "" {
+		add("LinkType", m.LinkType)
+	}
+	if m.PeeringLinkType != "" {
+		add("PeeringLinkType", m.PeeringLinkType)
+	}
+	if m.InternalHops != 0 {
+		add("InternalHops", m.InternalHops)
+	}
+	for _, loc := range m.Geo {
+		add("Geo", fmt.Sprintf("%.4f,%.4f %q", loc.Latitude, loc.Longitude, loc.Address))
+	}
+	if m.Note != "" {
+		add("Note", fmt.Sprintf("%q", m.Note))
+	}
+	return entries
+}
+
 // Human writes human readable output to the writer.
 func (r Result) Human(w io.Writer, showExpiration, colored bool) {
 	if colored {
@@ -85,6 +186,19 @@ func (r Result) Human(w io.Writer, showExpiration, colored bool) {
 			fmt.Fprintf(w, " Status: %s LocalIP: %s", path.Status, path.Local)
 		}
 		fmt.Fprintln(w)
+		if path.Metadata == nil {
+			continue
+		}
+		if len(path.Metadata) == 0 {
+			fmt.Fprintln(w, "     No metadata available")
+		}
+		for _, hop := range path.Metadata {
+			var entries []string
+			for _, e := range hop.entries() {
+				entries = append(entries, fmt.Sprintf("%s: %s", e[0], e[1]))
+			}
+			fmt.Fprintf(w, "     %s %s\n", hop.IA, strings.Join(entries, " "))
+		}
 	}
 }
 
@@ -120,6 +234,21 @@ func (r Result) coloredHuman(w io.Writer, showExpiration bool) {
 			)
 		}
 		fmt.Fprintf(w, "[%2d] %s\n", i, strings.Join(entries, " "))
+		if path.Metadata == nil {
+			continue
+		}
+		if len(path.Metadata) == 0 {
+			fmt.Fprintf(w, "     %s\n", values.Sprint("No metadata available"))
+		}
+		for _, hop := range path.Metadata {
+			var entries []string
+			for _, e := range hop.entries() {
+				entries = append(entries, fmt.Sprintf("%s: %s",
+					keys.Sprint(e[0]), values.Sprint(e[1])))
+			}
+			fmt.Fprintf(w, "     %s %s\n", color.New(color.FgHiMagenta).Sprint(hop.IA),
+				strings.Join(entries, " "))
+		}
 	}
 }
 
@@ -131,48 +260,30 @@ func (r Result) JSON(w io.Writer) error {
 	return enc.Encode(r)
 }
 
+// sortPaths sorts the paths, unless the SCION Daemon already ordered them
+// according to the requested sort key or path policy.
+func sortPaths(paths []snet.Path, cfg Config) {
+	if cfg.Sort != "" || cfg.Policy != nil || cfg.PolicyName != "" {
+		return
+	}
+	app.SortPaths(paths)
+}
+
 // Run lists the paths to the specified ISD-AS to stdout.
 func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 	sdConn, err := sciond.NewService(cfg.SCIOND).Connect(ctx)
 	if err != nil {
 		return nil, serrors.WrapStr("error connecting to SCIOND", err)
 	}
+	defer sdConn.Close(ctx)
 	localIA, err := sdConn.LocalIA(ctx)
 	if err != nil {
 		return nil, serrors.WrapStr("error determining local ISD-AS", err)
 	}
-
-	// TODO(lukedirtwalker): Replace this with snet.Router once we have the
-	// possibility to have the same functionality, i.e. refresh, fetch all paths.
-	// https://github.com/scionproto/scion/issues/3348
-	allPaths, err := sdConn.Paths(ctx, dst, addr.IA{},
-		sciond.PathReqFlags{Refresh: cfg.Refresh})
-	if err != nil {
-		return nil, serrors.WrapStr("failed to retrieve paths from SCIOND", err)
-	}
-
-	s, err := pathpol.NewSequence(cfg.Sequence)
+	paths, err := Fetch(ctx, sdConn, dst, cfg)
 	if err != nil {
 		return nil, err
 	}
-	pathsToPs := func(paths []snet.Path) pathpol.PathSet {
-		ps := make(pathpol.PathSet, len(paths))
-		for _, p := range paths {
-			ps[snet.Fingerprint(p)] = p
-		}
-		return ps
-	}
-	keep := s.Eval(pathsToPs(allPaths))
-
-	paths := make([]snet.Path, 0, len(allPaths))
-	for _, p := range allPaths {
-		if _, ok := keep[snet.Fingerprint(p)]; ok {
-			paths = append(paths, p)
-		}
-	}
-	if cfg.MaxPaths != 0 && len(paths) > cfg.MaxPaths {
-		paths = paths[:cfg.MaxPaths]
-	}
 
 	var statuses map[string]pathprobe.Status
 	var localIP net.IP
@@ -196,7 +307,7 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 			serrors.WrapStr("failed to get status", err)
 		}
 	}
-	app.SortPaths(paths)
+	sortPaths(paths, cfg)
 	res := &Result{Destination: dst}
 	for _, path := range paths {
 		fingerprint := "local"
@@ -220,6 +331,12 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 		for _, hop := range path.Interfaces() {
 			rpath.Hops = append(rpath.Hops, Hop{IA: hop.IA(), IfID: hop.ID()})
 		}
+		if cfg.Extended {
+			rpath.Metadata = []HopMetadata{}
+			for _, hop := range path.Metadata().Hops() {
+				rpath.Metadata = append(rpath.Metadata, newHopMetadata(hop))
+			}
+		}
 		if status, ok := statuses[pathprobe.PathKey(path)]; ok {
 			rpath.Status = strings.ToLower(string(status.Status))
 			rpath.StatusInfo = status.AdditionalInfo
@@ -229,6 +346,51 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 	return res, nil
 }
 
+// Fetch returns the paths to the specified ISD-AS that match the sequence and
+// the path limit of the configuration. The paths are requested over the given
+// SCION Daemon connection, the SCIOND address of the configuration is ignored.
+func Fetch(ctx context.Context, sdConn sciond.Connector, dst addr.IA,
+	cfg Config) ([]snet.Path, error) {
+
+	// TODO(lukedirtwalker): Replace this with snet.Router once we have the
+	// possibility to have the same functionality, i.e. refresh, fetch all paths.
+	// https://github.com/scionproto/scion/issues/3348
+	allPaths, err := sdConn.Paths(ctx, dst, addr.IA{},
+		sciond.PathReqFlags{
+			Refresh:    cfg.Refresh,
+			Policy:     cfg.Policy,
+			PolicyName: cfg.PolicyName,
+			Sort:       cfg.Sort,
+		})
+	if err != nil {
+		return nil, serrors.WrapStr("failed to retrieve paths from SCIOND", err)
+	}
+
+	s, err := pathpol.NewSequence(cfg.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	pathsToPs := func(paths []snet.Path) pathpol.PathSet {
+		ps := make(pathpol.PathSet, len(paths))
+		for _, p := range paths {
+			ps[snet.Fingerprint(p)] = p
+		}
+		return ps
+	}
+	keep := s.Eval(pathsToPs(allPaths))
+
+	paths := make([]snet.Path, 0, len(allPaths))
+	for _, p := range allPaths {
+		if _, ok := keep[snet.Fingerprint(p)]; ok {
+			paths = append(paths, p)
+		}
+	}
+	if cfg.MaxPaths != 0 && len(paths) > cfg.MaxPaths {
+		paths = paths[:cfg.MaxPaths]
+	}
+	return paths, nil
+}
+
 // TODO(matzf): this is a simple, hopefully temporary, workaround to not having
 // wildcard addresses in snet.
 // Here we just use a seemingly sensible default IP, but in the general case
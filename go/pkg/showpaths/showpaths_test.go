@@ -0,0 +1,67 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package showpaths
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+)
+
+func TestSortPaths(t *testing.T) {
+	testCases := map[string]struct {
+		Config   Config
+		Reversed bool
+	}{
+		"no daemon order": {
+			Config:   Config{},
+			Reversed: true,
+		},
+		"sort key": {
+			Config: Config{Sort: pathpol.SortByLatency},
+		},
+		"policy": {
+			Config: Config{Policy: &pathpol.Policy{}},
+		},
+		"policy name": {
+			Config: Config{PolicyName: "default"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// The daemon order puts the longer path first.
+			long := mock_snet.NewMockPath(ctrl)
+			long.EXPECT().Interfaces().Return(make([]snet.PathInterface, 2)).AnyTimes()
+			short := mock_snet.NewMockPath(ctrl)
+			short.EXPECT().Interfaces().Return(nil).AnyTimes()
+
+			paths := []snet.Path{long, short}
+			sortPaths(paths, tc.Config)
+			if tc.Reversed {
+				assert.Equal(t, []snet.Path{short, long}, paths)
+			} else {
+				assert.Equal(t, []snet.Path{long, short}, paths)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/pkg/app"
+)
+
+// pathSelectionFlags are the flags that restrict the paths that a command
+// chooses from.
+type pathSelectionFlags struct {
+	interactive bool
+	refresh     bool
+	sequence    string
+	policy      string
+}
+
+func (f *pathSelectionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&f.interactive, "interactive", "i", false, "interactive mode")
+	cmd.Flags().BoolVar(&f.refresh, "refresh", false, "set refresh flag for path request")
+	cmd.Flags().StringVar(&f.sequence, "sequence", "",
+		"sequence of space separated hop predicates the path must match")
+	cmd.Flags().StringVar(&f.policy, "policy", "",
+		"file with a path policy that the SCION Daemon applies")
+}
+
+func (f *pathSelectionFlags) query() (app.PathQuery, error) {
+	q := app.PathQuery{
+		Refresh:     f.refresh,
+		Sequence:    f.sequence,
+		Interactive: f.interactive,
+	}
+	if f.sequence != "" {
+		if _, err := pathpol.NewSequence(f.sequence); err != nil {
+			return app.PathQuery{}, serrors.WrapStr("parsing sequence", err)
+		}
+	}
+	if f.policy != "" {
+		policy, err := pathpol.LoadPolicy(f.policy)
+		if err != nil {
+			return app.PathQuery{}, err
+		}
+		q.Policy = policy
+	}
+	return q, nil
+}
+
+// Output formats.
+const (
+	formatHuman = "human"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+func validateFormat(format string) error {
+	switch format {
+	case formatHuman, formatJSON, formatYAML:
+		return nil
+	default:
+		return serrors.New("format not supported", "format", format)
+	}
+}
+
+// encode writes v to w in the machine readable format.
+func encode(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return serrors.New("format not supported", "format", format)
+	}
+}
+
+// pathInfo describes a path in the machine readable output.
+type pathInfo struct {
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Hops        []pathHop `json:"hops" yaml:"hops"`
+	NextHop     string    `json:"next_hop" yaml:"next_hop"`
+	MTU         uint16    `json:"mtu" yaml:"mtu"`
+	Expiry      time.Time `json:"expiry" yaml:"expiry"`
+}
+
+// pathHop is an interface on a path in the machine readable output.
+type pathHop struct {
+	IA   string `json:"isd_as" yaml:"isd_as"`
+	IfID uint64 `json:"ifid" yaml:"ifid"`
+}
+
+func newPathInfo(path snet.Path) pathInfo {
+	info := pathInfo{
+		Fingerprint: "local",
+		Hops:        []pathHop{},
+		MTU:         path.Metadata().MTU(),
+		Expiry:      path.Metadata().Expiry(),
+	}
+	if len(path.Interfaces()) > 0 {
+		info.Fingerprint = snet.Fingerprint(path).String()[:16]
+	}
+	if nextHop := path.UnderlayNextHop(); nextHop != nil {
+		info.NextHop = nextHop.String()
+	}
+	for _, intf := range path.Interfaces() {
+		info.Hops = append(info.Hops, pathHop{IA: intf.IA().String(), IfID: uint64(intf.ID())})
+	}
+	return info
+}
+
+// durationMs returns the duration in milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
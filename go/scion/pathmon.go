@@ -0,0 +1,192 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/pkg/app"
+	"github.com/scionproto/scion/go/pkg/pathmon"
+)
+
+func newPathmon(pather CommandPather) *cobra.Command {
+	var flags struct {
+		cfg        pathmon.Config
+		policy     string
+		metrics    string
+		dispatcher string
+		noColor    bool
+
+		features []string
+	}
+
+	var cmd = &cobra.Command{
+		Use:   "pathmon [flags] <remote> [<remote>...]",
+		Short: "Continuously monitor the paths to remote SCION hosts",
+		Example: fmt.Sprintf(`  %[1]s pathmon 1-ff00:0:110,10.0.0.1 1-ff00:0:111,10.0.0.2
+  %[1]s pathmon 1-ff00:0:110,10.0.0.1 --sequence="0* 1-ff00:0:112 0*" --interval 5s
+  %[1]s pathmon 1-ff00:0:110,10.0.0.1 --policy policy.yml --metrics 127.0.0.1:9090`,
+			pather.CommandPath()),
+		Long: `'pathmon' continuously monitors the paths to remote SCION hosts.
+
+All paths that the SCION Daemon returns for the remotes are pinged once per
+interval with SCMP echo requests, and a table with the state of every path is
+shown. The table is redrawn after every round of pings. The paths are
+refetched regularly, paths that are not available anymore are dropped and new
+paths are added.
+
+The monitored paths can be restricted with a sequence of hop predicates
+(--sequence), see 'showpaths --help', and with a path policy that the SCION
+Daemon applies, either from a file (--policy) or one that is configured in the
+SCION Daemon (--policy-name).
+
+For every path, the table shows the round trip time of the last reply, the
+loss among the last 10 pings and whether the path is up. A path is down if no
+reply was received for the last 3 pings.
+
+With --metrics, the state of the paths is exported on the given address for
+prometheus on /metrics. The metrics are labeled with the remote and the
+fingerprint of the path.
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				remote, err := snet.ParseUDPAddr(arg)
+				if err != nil {
+					return serrors.WrapStr("parsing remote", err, "remote", arg)
+				}
+				flags.cfg.Remotes = append(flags.cfg.Remotes, remote)
+			}
+			features, err := parseFeatures(flags.features)
+			if err != nil {
+				return err
+			}
+			if flags.cfg.Paths.Sequence != "" {
+				if _, err := pathpol.NewSequence(flags.cfg.Paths.Sequence); err != nil {
+					return serrors.WrapStr("parsing sequence", err)
+				}
+			}
+			if flags.policy != "" {
+				if flags.cfg.Paths.Policy, err = pathpol.LoadPolicy(flags.policy); err != nil {
+					return err
+				}
+			}
+			cmd.SilenceUsage = true
+
+			ctx := app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
+			flags.cfg.Dispatcher = reliable.NewDispatcher(flags.dispatcher)
+			flags.cfg.HeaderV2 = features.HeaderV2
+			flags.cfg.UpdateHandler = func(statuses []pathmon.PathStatus) {
+				printPathmon(os.Stdout, statuses, isatty.IsTerminal(os.Stdout.Fd()),
+					app.WithDisableColor(flags.noColor))
+			}
+			flags.cfg.ErrHandler = func(err error) {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			}
+			monitor := pathmon.New(flags.cfg)
+
+			if flags.metrics != "" {
+				registry := prometheus.NewRegistry()
+				if err := registry.Register(pathmon.NewCollector(monitor)); err != nil {
+					return err
+				}
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+				server := &http.Server{Addr: flags.metrics, Handler: mux}
+				go func() {
+					defer log.HandlePanic()
+					if err := server.ListenAndServe(); err != http.ErrServerClosed {
+						fmt.Fprintf(os.Stderr, "ERROR: serving metrics: %s\n", err)
+					}
+				}()
+				defer server.Close()
+			}
+			return monitor.Run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.cfg.Paths.SCIOND, "sciond", sciond.DefaultSCIONDAddress,
+		"SCIOND address")
+	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
+		"dispatcher socket")
+	cmd.Flags().IPVar(&flags.cfg.Paths.Local, "local", nil, "IP address to listen on")
+	cmd.Flags().StringVar(&flags.cfg.Paths.Sequence, "sequence", "",
+		"sequence of space separated hop predicates the paths must match")
+	cmd.Flags().StringVar(&flags.policy, "policy", "",
+		"file with a path policy that the SCION Daemon applies")
+	cmd.Flags().StringVar(&flags.cfg.Paths.PolicyName, "policy-name", "",
+		"name of a path policy configured in the SCION Daemon")
+	cmd.Flags().IntVarP(&flags.cfg.Paths.MaxPaths, "maxpaths", "m", 0,
+		"maximum number of monitored paths per remote (0 means all paths)")
+	cmd.Flags().DurationVar(&flags.cfg.Interval, "interval", time.Second,
+		"time between pings over a path")
+	cmd.Flags().DurationVar(&flags.cfg.Timeout, "timeout", time.Second, "timeout per ping")
+	cmd.Flags().DurationVar(&flags.cfg.PathRefresh, "path-refresh", pathmon.DefaultPathRefresh,
+		"interval at which the paths are refetched")
+	cmd.Flags().StringVar(&flags.metrics, "metrics", "",
+		"address to export prometheus metrics on, e.g., 127.0.0.1:9090")
+	cmd.Flags().BoolVar(&flags.noColor, "no_color", false, "disable colored output")
+	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
+		"enable development features "+features{}.supported())
+
+	return cmd
+}
+
+// printPathmon writes the table with the state of the paths. If clearScreen is
+// set, the terminal is cleared first.
+func printPathmon(w io.Writer, statuses []pathmon.PathStatus, clearScreen bool,
+	opts ...app.ColorOption) {
+
+	if clearScreen {
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+	}
+	fmt.Fprintf(w, "%s\n\n", time.Now().Format(time.RFC3339))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REMOTE\tFINGERPRINT\tSTATE\tRTT\tLOSS\tSENT\tRECEIVED\tPATH")
+	for _, s := range statuses {
+		state, rtt := "down", "-"
+		if s.Up() {
+			state = "up"
+		}
+		if s.Received > 0 {
+			rtt = s.RTT.Round(time.Microsecond).String()
+		}
+		fingerprint := "local"
+		if len(s.Path.Interfaces()) > 0 {
+			fingerprint = s.Fingerprint.String()[:16]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.0f%%\t%d\t%d\t%s\n", s.Remote, fingerprint, state,
+			rtt, s.Loss*100, s.Sent, s.Received, app.ColorPath(s.Path, opts...))
+	}
+	tw.Flush()
+}
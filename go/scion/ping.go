@@ -36,26 +36,43 @@ import (
 
 func newPing(pather CommandPather) *cobra.Command {
 	var flags struct {
-		count       uint16
-		interval    time.Duration
-		size        uint
-		interactive bool
-		local       net.IP
-		refresh     bool
-		sciond      string
-		dispatcher  string
-		timeout     time.Duration
-		maxMTU      bool
-		noColor     bool
+		count      uint16
+		interval   time.Duration
+		size       uint
+		local      net.IP
+		sciond     string
+		dispatcher string
+		timeout    time.Duration
+		maxMTU     bool
+		noColor    bool
+		format     string
+		paths      pathSelectionFlags
 
 		features []string
 	}
 
 	var cmd = &cobra.Command{
-		Use:     "ping [flags] <remote>",
-		Short:   "Test connectivity to a remote SCION host using SCMP echo packets",
-		Example: fmt.Sprintf("  %[1]s ping 1-ff00:0:110,10.0.0.1", pather.CommandPath()),
-		Args:    cobra.ExactArgs(1),
+		Use:   "ping [flags] <remote>",
+		Short: "Test connectivity to a remote SCION host using SCMP echo packets",
+		Example: fmt.Sprintf(`  %[1]s ping 1-ff00:0:110,10.0.0.1
+  %[1]s ping 1-ff00:0:110,10.0.0.1 --sequence="0* 1-ff00:0:112 0*"
+  %[1]s ping 1-ff00:0:110,10.0.0.1 --policy policy.yml -c 10 --format json`,
+			pather.CommandPath()),
+		Long: `'ping' sends SCMP echo requests to a remote SCION host over a single path.
+
+The path is chosen randomly from the paths that the SCION Daemon returns. The
+paths can be restricted with a sequence of hop predicates (--sequence), see
+'showpaths --help', and with a path policy file (--policy) that the SCION
+Daemon applies. If a policy is given, the first path in the order of the
+policy is used. With --interactive the path is chosen on the terminal.
+
+The summary contains the packet loss and the minimum, average, maximum and
+the 50th, 90th, 95th and 99th percentile of the round trip times on the path.
+With --format json or --format yaml, the replies and the summary are written
+in a machine readable format once pinging is done. The round trip times are
+given in milliseconds and the packet loss as a ratio between 0 and 1.
+`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			remote, err := snet.ParseUDPAddr(args[0])
 			if err != nil {
@@ -65,7 +82,15 @@ func newPing(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := validateFormat(flags.format); err != nil {
+				return err
+			}
+			query, err := flags.paths.query()
+			if err != nil {
+				return err
+			}
 			cmd.SilenceUsage = true
+			human := flags.format == formatHuman
 
 			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
 			defer cancelF()
@@ -78,8 +103,8 @@ func newPing(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			path, err := app.ChoosePath(context.Background(), sd, remote.IA,
-				flags.interactive, flags.refresh, app.WithDisableColor(flags.noColor))
+			path, err := app.ChoosePath(context.Background(), sd, remote.IA, query,
+				app.WithDisableColor(flags.noColor))
 			if err != nil {
 				return err
 			}
@@ -96,26 +121,37 @@ func newPing(pather CommandPather) *cobra.Command {
 					return serrors.WrapStr("resolving local address", err)
 
 				}
-				fmt.Printf("Resolved local address:\n  %s\n", localIP)
+				if human {
+					fmt.Printf("Resolved local address:\n  %s\n", localIP)
+				}
+			}
+			if human {
+				fmt.Printf("Using path:\n  %s\n\n", path)
 			}
-			fmt.Printf("Using path:\n  %s\n\n", path)
 			local := &snet.UDPAddr{
 				IA:   info.IA,
 				Host: &net.UDPAddr{IP: localIP},
 			}
-			pldSize := int(flags.size)
-			if flags.maxMTU {
-				mtu := int(path.Metadata().MTU())
-				pldSize, err = calcMaxPldSize(local, remote, mtu, features.HeaderV2)
-				if err != nil {
-					return err
-				}
+			pldSize, err := payloadSize(flags.size, flags.maxMTU, local, remote,
+				int(path.Metadata().MTU()), features.HeaderV2)
+			if err != nil {
+				return err
 			}
 			pktSize, err := calcPktSize(local, remote, pldSize, features.HeaderV2)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("PING %s pld=%dB scion_pkt=%dB\n", remote, pldSize, pktSize)
+			if human {
+				fmt.Printf("PING %s pld=%dB scion_pkt=%dB\n", remote, pldSize, pktSize)
+			}
+			result := pingResult{
+				Destination: remote.String(),
+				Local:       local.String(),
+				Path:        newPathInfo(path),
+				PayloadSize: pldSize,
+				PacketSize:  pktSize,
+				Replies:     []pingReply{},
+			}
 
 			start := time.Now()
 			ctx = app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -130,11 +166,22 @@ func newPing(pather CommandPather) *cobra.Command {
 				Timeout:     flags.timeout,
 				Local:       local,
 				Remote:      remote,
-				PayloadSize: int(flags.size),
+				PayloadSize: pldSize,
 				ErrHandler: func(err error) {
 					fmt.Fprintf(os.Stderr, "ERROR: %s", err)
 				},
 				UpdateHandler: func(update ping.Update) {
+					if !human {
+						result.Replies = append(result.Replies, pingReply{
+							Sequence: update.Sequence,
+							Source: fmt.Sprintf("%s,%s",
+								update.Source.IA, update.Source.Host),
+							Size:  update.Size,
+							RTT:   durationMs(update.RTT),
+							State: pingStates[update.State],
+						})
+						return
+					}
 					var additional string
 					switch update.State {
 					case ping.AfterTimeout:
@@ -150,7 +197,14 @@ func newPing(pather CommandPather) *cobra.Command {
 				},
 				HeaderV2: features.HeaderV2,
 			})
-			pingSummary(stats, remote, time.Since(start))
+			if !human {
+				result.Statistics = newPingStatistics(stats, time.Since(start))
+				if encErr := encode(os.Stdout, flags.format, result); encErr != nil {
+					return encErr
+				}
+			} else {
+				pingSummary(stats, remote, time.Since(start))
+			}
 			if err != nil {
 				return err
 			}
@@ -158,14 +212,13 @@ func newPing(pather CommandPather) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "interactive mode")
+	flags.paths.register(cmd)
 	cmd.Flags().BoolVar(&flags.noColor, "no_color", false, "disable colored output")
 	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Second, "timeout per packet")
 	cmd.Flags().IPVar(&flags.local, "local", nil, "IP address to listen on")
 	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
 	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
 		"dispatcher socket")
-	cmd.Flags().BoolVar(&flags.refresh, "refresh", false, "set refresh flag for path request")
 	cmd.Flags().DurationVar(&flags.interval, "interval", time.Second, "time between packets")
 	cmd.Flags().Uint16VarP(&flags.count, "count", "c", 0, "total number of packets to send")
 	cmd.Flags().UintVarP(&flags.size, "payload_size", "s", 0,
@@ -177,12 +230,26 @@ the SCION path.`,
 		`choose the payload size such that the sent SCION packet including the SCION Header,
 SCMP echo header and payload are equal to the MTU of the path. This flag overrides the
 'payload_size' flag.`)
+	cmd.Flags().StringVar(&flags.format, "format", formatHuman,
+		"output format (human|json|yaml)")
 	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
 		"enable development features "+features{}.supported())
 
 	return cmd
 }
 
+// payloadSize returns the size of the payload of the echo requests. With
+// maxMTU, the payload is chosen such that the SCION packet fills the MTU of the
+// path. Otherwise, the configured size is used.
+func payloadSize(size uint, maxMTU bool, local, remote *snet.UDPAddr, mtu int,
+	headerV2 bool) (int, error) {
+
+	if !maxMTU {
+		return int(size), nil
+	}
+	return calcMaxPldSize(local, remote, mtu, headerV2)
+}
+
 func calcMaxPldSize(local, remote *snet.UDPAddr, mtu int, headerV2 bool) (int, error) {
 	overhead, err := calcPktSize(local, remote, 0, headerV2)
 	if err != nil {
@@ -206,4 +273,69 @@ func pingSummary(stats ping.Stats, remote *snet.UDPAddr, run time.Duration) {
 	fmt.Printf("\n--- %s,%s statistics ---\n", remote.IA, remote.Host.IP)
 	fmt.Printf("%d packets transmitted, %d received, %d%% packet loss, time %v\n",
 		stats.Sent, stats.Received, pktLoss, run.Round(time.Microsecond))
+	if stats.Received == 0 {
+		return
+	}
+	fmt.Printf("rtt min/avg/max = %v/%v/%v\n", stats.Percentile(0),
+		stats.Average().Round(time.Microsecond), stats.Percentile(100))
+	fmt.Printf("rtt p50/p90/p95/p99 = %v/%v/%v/%v\n", stats.Percentile(50),
+		stats.Percentile(90), stats.Percentile(95), stats.Percentile(99))
+}
+
+// pingResult is the machine readable output of a ping run.
+type pingResult struct {
+	Destination string         `json:"destination" yaml:"destination"`
+	Local       string         `json:"local" yaml:"local"`
+	Path        pathInfo       `json:"path" yaml:"path"`
+	PayloadSize int            `json:"payload_size" yaml:"payload_size"`
+	PacketSize  int            `json:"scion_packet_size" yaml:"scion_packet_size"`
+	Replies     []pingReply    `json:"replies" yaml:"replies"`
+	Statistics  pingStatistics `json:"statistics" yaml:"statistics"`
+}
+
+// pingReply is a received echo reply.
+type pingReply struct {
+	Sequence int     `json:"scmp_seq" yaml:"scmp_seq"`
+	Source   string  `json:"source" yaml:"source"`
+	Size     int     `json:"size" yaml:"size"`
+	RTT      float64 `json:"rtt_ms" yaml:"rtt_ms"`
+	State    string  `json:"state" yaml:"state"`
+}
+
+// pingStatistics summarizes the ping run over the path.
+type pingStatistics struct {
+	Sent     int     `json:"sent" yaml:"sent"`
+	Received int     `json:"received" yaml:"received"`
+	Loss     float64 `json:"loss" yaml:"loss"`
+	Time     float64 `json:"time_ms" yaml:"time_ms"`
+	MinRTT   float64 `json:"min_rtt_ms" yaml:"min_rtt_ms"`
+	AvgRTT   float64 `json:"avg_rtt_ms" yaml:"avg_rtt_ms"`
+	MaxRTT   float64 `json:"max_rtt_ms" yaml:"max_rtt_ms"`
+	P50RTT   float64 `json:"p50_rtt_ms" yaml:"p50_rtt_ms"`
+	P90RTT   float64 `json:"p90_rtt_ms" yaml:"p90_rtt_ms"`
+	P95RTT   float64 `json:"p95_rtt_ms" yaml:"p95_rtt_ms"`
+	P99RTT   float64 `json:"p99_rtt_ms" yaml:"p99_rtt_ms"`
+}
+
+func newPingStatistics(stats ping.Stats, run time.Duration) pingStatistics {
+	return pingStatistics{
+		Sent:     stats.Sent,
+		Received: stats.Received,
+		Loss:     stats.Loss(),
+		Time:     durationMs(run),
+		MinRTT:   durationMs(stats.Percentile(0)),
+		AvgRTT:   durationMs(stats.Average()),
+		MaxRTT:   durationMs(stats.Percentile(100)),
+		P50RTT:   durationMs(stats.Percentile(50)),
+		P90RTT:   durationMs(stats.Percentile(90)),
+		P95RTT:   durationMs(stats.Percentile(95)),
+		P99RTT:   durationMs(stats.Percentile(99)),
+	}
+}
+
+var pingStates = map[ping.State]string{
+	ping.Success:      "success",
+	ping.AfterTimeout: "after_timeout",
+	ping.OutOfOrder:   "out_of_order",
+	ping.Duplicate:    "duplicate",
 }
@@ -0,0 +1,55 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestPayloadSize(t *testing.T) {
+	// The hosts are in the same AS, so that no path is needed.
+	local := &snet.UDPAddr{
+		IA:   xtest.MustParseIA("1-ff00:0:110"),
+		Host: &net.UDPAddr{IP: net.ParseIP("10.0.0.1")},
+	}
+	remote := &snet.UDPAddr{
+		IA:   xtest.MustParseIA("1-ff00:0:110"),
+		Host: &net.UDPAddr{IP: net.ParseIP("10.0.0.2")},
+	}
+	for name, headerV2 := range map[string]bool{"header v2": true, "legacy": false} {
+		headerV2 := headerV2
+		t.Run(name, func(t *testing.T) {
+			t.Run("configured size", func(t *testing.T) {
+				size, err := payloadSize(100, false, local, remote, 1472, headerV2)
+				require.NoError(t, err)
+				assert.Equal(t, 100, size)
+			})
+			t.Run("max MTU", func(t *testing.T) {
+				size, err := payloadSize(100, true, local, remote, 1472, headerV2)
+				require.NoError(t, err)
+				pktSize, err := calcPktSize(local, remote, size, headerV2)
+				require.NoError(t, err)
+				assert.Equal(t, 1472, pktSize)
+			})
+		})
+	}
+}
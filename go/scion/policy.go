@@ -0,0 +1,86 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/pkg/command"
+)
+
+func newPolicy(pather CommandPather) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Inspect path policies",
+		Args:  cobra.NoArgs,
+	}
+	joined := command.Join(pather, cmd)
+	cmd.AddCommand(
+		newPolicyLint(joined),
+	)
+	return cmd
+}
+
+func newPolicyLint(pather command.Pather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lint <file>",
+		Short: "Validate a path policy file",
+		Args:  cobra.ExactArgs(1),
+		Example: fmt.Sprintf(`  %[1]s lint policies.json
+  %[1]s lint policies.yml`, pather.CommandPath()),
+		Long: `'lint' validates a file that contains a map of named path policies.
+
+The file is parsed as YAML if it has the extension .yml or .yaml, and as JSON
+otherwise. The following problems are reported:
+
+  error:   a policy extends an unknown policy
+  error:   a policy directly or indirectly extends itself
+  error:   an ACL has no default rule, i.e., the last entry does not match all hops
+  warning: a sequence can never match a path
+  warning: an option is unreachable, because an option with a higher weight
+           matches all paths or has the same policy
+
+The command fails if the file can not be parsed or if any error is reported.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			policies, err := pathpol.LoadPolicyMap(args[0])
+			if err != nil {
+				return err
+			}
+			issues := policies.Lint()
+			var errs int
+			for _, issue := range issues {
+				fmt.Fprintln(os.Stdout, issue)
+				if issue.Severity == pathpol.SeverityError {
+					errs++
+				}
+			}
+			if errs > 0 {
+				return serrors.New("policy file contains errors", "errors", errs)
+			}
+			if len(issues) == 0 {
+				fmt.Fprintf(os.Stdout, "%s: no issues found\n", args[0])
+			}
+			return nil
+		},
+	}
+	return cmd
+}
@@ -45,7 +45,9 @@ func main() {
 	cmd.AddCommand(
 		command.NewCompletion(cmd),
 		command.NewVersion(cmd),
+		newPathmon(cmd),
 		newPing(cmd),
+		newPolicy(cmd),
 		newShowpaths(cmd),
 		newTraceroute(cmd),
 	)
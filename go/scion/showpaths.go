@@ -24,6 +24,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/pkg/showpaths"
@@ -36,6 +37,8 @@ func newShowpaths(pather CommandPather) *cobra.Command {
 		expiration bool
 		json       bool
 		noColor    bool
+		policy     string
+		sort       string
 	}
 
 	var cmd = &cobra.Command{
@@ -45,9 +48,12 @@ func newShowpaths(pather CommandPather) *cobra.Command {
 		Args:    cobra.ExactArgs(1),
 		Example: fmt.Sprintf(`  %[1]s showpaths 1-ff00:0:110 --expiration
   %[1]s showpaths 1-ff00:0:110 --local 127.0.0.55 --json
+  %[1]s showpaths 1-ff00:0:110 --extended
   %[1]s showpaths 1-ff00:0:111 --sequence="0-0#2 0*" # outgoing IfID=2
   %[1]s showpaths 1-ff00:0:111 --sequence="0* 0-0#41" # incoming IfID=41 at dstIA
   %[1]s showpaths 1-ff00:0:111 --sequence="0* 1-ff00:0:112 0*" # 1-ff00:0:112 on the path
+  %[1]s showpaths 1-ff00:0:110 --policy policy.yml --sort latency
+  %[1]s showpaths 1-ff00:0:110 --policy-name low_latency
   %[1]s showpaths 1-ff00:0:110 --no-probe`, pather.CommandPath()),
 		Long: `'showpaths' lists available paths between the local and the specified SCION ASe a.
 
@@ -57,6 +63,10 @@ hole on the path). To disable path probing, set the appropriate flag.
 
 'showpaths' can be instructed to output the paths as json using the the --json flag.
 
+The static metadata of the paths, i.e., the latency, bandwidth, link type,
+location and notes that the ASes advertise for each hop, is shown with the
+--extended flag.
+
 The paths can be filtered according to a sequence. A sequence is a string of
 space separated HopPredicates. A Hop Predicate (HP) is of the form
 'ISD-AS#IF,IF'. The first IF means the inbound interface (the interface where
@@ -94,6 +104,13 @@ Available operators:
   + (the preceding ISD-level HP must appear at least once)
   * (the preceding ISD-level HP may appear zero or more times)
   | (logical OR)
+
+The SCION Daemon can filter and order the paths according to a path policy.
+The policy is either read from a file with the --policy flag, or it is one of
+the named policies configured in the SCION Daemon, selected with the
+--policy-name flag. The policy file contains a single policy in JSON, or in
+YAML if the file has the extension .yml or .yaml. The --sort flag orders the
+paths by hops, mtu, expiry, latency or bandwidth.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dst, err := addr.IAFromString(args[0])
@@ -105,6 +122,17 @@ Available operators:
 			// call the command. Silence the usage help output on error, because subsequent
 			// errors are likely not caused malformed CLI arguments.
 			// See https://github.com/spf13/cobra/issues/340
+			if flags.policy != "" {
+				if flags.cfg.Policy, err = pathpol.LoadPolicy(flags.policy); err != nil {
+					return err
+				}
+			}
+			if flags.sort != "" {
+				if err := flags.cfg.Sort.UnmarshalText([]byte(flags.sort)); err != nil {
+					return err
+				}
+			}
+
 			cmd.SilenceUsage = true
 
 			// FIXME(roosd): This practically turns of logging done in libraries. We
@@ -135,10 +163,18 @@ Available operators:
 	cmd.Flags().DurationVar(&flags.timeout, "timeout", 5*time.Second, "Timeout")
 	cmd.Flags().StringVar(&flags.cfg.Sequence, "sequence",
 		"", "sequence space separated list of HPs")
+	cmd.Flags().StringVar(&flags.policy, "policy", "",
+		"File with a path policy that the SCION Daemon applies")
+	cmd.Flags().StringVar(&flags.cfg.PolicyName, "policy-name", "",
+		"Name of a path policy configured in the SCION Daemon")
+	cmd.Flags().StringVar(&flags.sort, "sort", "",
+		"Order the paths by hops, mtu, expiry, latency or bandwidth")
 	cmd.Flags().IntVarP(&flags.cfg.MaxPaths, "maxpaths", "m", 10,
 		"Maximum number of paths that are displayed")
 	cmd.Flags().BoolVarP(&flags.expiration, "expiration", "e", false,
 		"Show path expiration information")
+	cmd.Flags().BoolVar(&flags.cfg.Extended, "extended", false,
+		"Show the latency, bandwidth, link type, location and notes of each hop")
 	cmd.Flags().BoolVarP(&flags.cfg.Refresh, "refresh", "r", false,
 		"Set refresh flag for SCION Deamon path request")
 	cmd.Flags().BoolVar(&flags.cfg.NoProbe, "no-probe", false,
@@ -37,13 +37,13 @@ import (
 
 func newTraceroute(pather CommandPather) *cobra.Command {
 	var flags struct {
-		dispatcher  string
-		interactive bool
-		local       net.IP
-		sciond      string
-		refresh     bool
-		timeout     time.Duration
-		noColor     bool
+		dispatcher string
+		local      net.IP
+		sciond     string
+		timeout    time.Duration
+		noColor    bool
+		format     string
+		paths      pathSelectionFlags
 
 		features []string
 	}
@@ -52,7 +52,14 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 		Use:     "traceroute [flags] <remote>",
 		Aliases: []string{"tr"},
 		Short:   "Trace the SCION route to a remote SCION AS using SCMP traceroute packets",
-		Args:    cobra.ExactArgs(1),
+		Long: `'traceroute' sends SCMP traceroute requests to the routers on a single path.
+
+The path is chosen like for 'ping', see 'ping --help'. With --format json or
+--format yaml, the hops are written in a machine readable format once the
+traceroute is done. The round trip times of the replies are given in
+milliseconds, lost probes are counted per hop.
+`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 			remote, err := snet.ParseUDPAddr(args[0])
@@ -63,6 +70,14 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := validateFormat(flags.format); err != nil {
+				return err
+			}
+			query, err := flags.paths.query()
+			if err != nil {
+				return err
+			}
+			human := flags.format == formatHuman
 			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
 			defer cancelF()
 			sd, err := sciond.NewService(flags.sciond).Connect(ctx)
@@ -73,8 +88,8 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			path, err := app.ChoosePath(context.Background(), sd, remote.IA,
-				flags.interactive, flags.refresh, app.WithDisableColor(flags.noColor))
+			path, err := app.ChoosePath(context.Background(), sd, remote.IA, query,
+				app.WithDisableColor(flags.noColor))
 			if err != nil {
 				return err
 			}
@@ -96,13 +111,23 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 				if localIP, err = addrutil.ResolveLocal(target); err != nil {
 					return serrors.WrapStr("resolving local address", err)
 				}
-				fmt.Printf("Resolved local address:\n  %s\n", localIP)
+				if human {
+					fmt.Printf("Resolved local address:\n  %s\n", localIP)
+				}
+			}
+			if human {
+				fmt.Printf("Using path:\n  %s\n\n", path)
 			}
-			fmt.Printf("Using path:\n  %s\n\n", path)
 			local := &snet.UDPAddr{
 				IA:   info.IA,
 				Host: &net.UDPAddr{IP: localIP},
 			}
+			result := tracerouteResult{
+				Destination: remote.String(),
+				Local:       local.String(),
+				Path:        newPathInfo(path),
+				Hops:        []tracerouteHop{},
+			}
 			ctx = app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
 			var stats traceroute.Stats
 			cfg := traceroute.Config{
@@ -115,6 +140,10 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 				ProbesPerHop: 3,
 				ErrHandler:   func(err error) { fmt.Fprintf(os.Stderr, "ERROR: %s\n", err) },
 				UpdateHandler: func(u traceroute.Update) {
+					if !human {
+						result.Hops = append(result.Hops, newTracerouteHop(u, flags.timeout))
+						return
+					}
 					fmt.Printf("%d %s %s\n", u.Index, fmtRemote(u.Remote, u.Interface),
 						fmtRTTs(u.RTTs, flags.timeout))
 				},
@@ -127,22 +156,32 @@ func newTraceroute(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !human {
+				result.Sent, result.Received = stats.Sent, stats.Recv
+				if err := encode(os.Stdout, flags.format, result); err != nil {
+					return err
+				}
+			}
 			if stats.Sent != stats.Recv {
 				return serrors.New("packets were lost")
 			}
 			return nil
 		},
-		Example: fmt.Sprintf("%[1]s traceroute 1-ff00:0:1,[10.0.0.1]", pather.CommandPath()),
+		Example: fmt.Sprintf(`  %[1]s traceroute 1-ff00:0:1,[10.0.0.1]
+  %[1]s traceroute 1-ff00:0:1,[10.0.0.1] --sequence="0* 1-ff00:0:112 0*"
+  %[1]s traceroute 1-ff00:0:1,[10.0.0.1] --policy policy.yml --format yaml`,
+			pather.CommandPath()),
 	}
 
-	cmd.Flags().BoolVar(&flags.refresh, "refresh", false, "set refresh flag for path request")
-	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "interactive mode")
+	flags.paths.register(cmd)
 	cmd.Flags().BoolVar(&flags.noColor, "no-color", false, "disable colored output")
 	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Second, "timeout per packet")
 	cmd.Flags().IPVar(&flags.local, "local", nil, "IP address to listen on")
 	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
 		"dispatcher socket")
 	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
+	cmd.Flags().StringVar(&flags.format, "format", formatHuman,
+		"output format (human|json|yaml)")
 	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
 		"enable development features "+features{}.supported())
 	return cmd
@@ -166,3 +205,46 @@ func fmtRemote(remote *snet.UDPAddr, intf uint64) string {
 	}
 	return fmt.Sprintf("%s IfID=%d", remote, intf)
 }
+
+// tracerouteResult is the machine readable output of a traceroute run.
+type tracerouteResult struct {
+	Destination string          `json:"destination" yaml:"destination"`
+	Local       string          `json:"local" yaml:"local"`
+	Path        pathInfo        `json:"path" yaml:"path"`
+	Hops        []tracerouteHop `json:"hops" yaml:"hops"`
+	Sent        uint            `json:"sent" yaml:"sent"`
+	Received    uint            `json:"received" yaml:"received"`
+}
+
+// tracerouteHop contains the replies of a router on the path. The address is
+// empty if no reply was received.
+type tracerouteHop struct {
+	Index     int       `json:"index" yaml:"index"`
+	IA        string    `json:"isd_as" yaml:"isd_as"`
+	Address   string    `json:"address" yaml:"address"`
+	Interface uint64    `json:"interface_id" yaml:"interface_id"`
+	RTTs      []float64 `json:"rtts_ms" yaml:"rtts_ms"`
+	Lost      int       `json:"lost" yaml:"lost"`
+}
+
+func newTracerouteHop(u traceroute.Update, timeout time.Duration) tracerouteHop {
+	hop := tracerouteHop{
+		Index:     u.Index,
+		Interface: u.Interface,
+		RTTs:      []float64{},
+	}
+	if u.Remote != nil {
+		hop.IA = u.Remote.IA.String()
+		if u.Remote.Host != nil {
+			hop.Address = u.Remote.Host.IP.String()
+		}
+	}
+	for _, rtt := range u.RTTs {
+		if rtt > timeout {
+			hop.Lost++
+			continue
+		}
+		hop.RTTs = append(hop.RTTs, durationMs(rtt))
+	}
+	return hop
+}
@@ -33,16 +33,22 @@ import (
 	segfetchergrpc "github.com/scionproto/scion/go/lib/infra/modules/segfetcher/grpc"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/periodic"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/revcache"
 	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/snet/multipath"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/pkg/command"
 	"github.com/scionproto/scion/go/pkg/grpc"
 	"github.com/scionproto/scion/go/pkg/sciond"
 	"github.com/scionproto/scion/go/pkg/sciond/config"
 	"github.com/scionproto/scion/go/pkg/sciond/fetcher"
+	"github.com/scionproto/scion/go/pkg/sciond/health"
 	"github.com/scionproto/scion/go/pkg/service"
 	"github.com/scionproto/scion/go/pkg/storage"
 	"github.com/scionproto/scion/go/pkg/trust"
@@ -107,6 +113,8 @@ func run(file string) error {
 		return serrors.WrapStr("initializing path storage", err)
 	}
 	pathDB = pathdb.WithMetrics(string(storage.BackendSqlite), pathDB)
+	pathUpdates := &pathdb.Notifier{}
+	pathDB = pathdb.WithNotifier(pathDB, pathUpdates)
 	defer pathDB.Close()
 	defer revCache.Close()
 	cleaner := periodic.Start(pathdb.NewCleaner(pathDB, "sd_segments"),
@@ -141,22 +149,59 @@ func run(file string) error {
 		return serrors.WrapStr("creating trust engine", err)
 	}
 
-	srv := sciond.GRPCServer(cfg.SD.Address, sciond.ServerCfg{
-		Fetcher: fetcher.NewFetcher(
-			fetcher.FetcherConfig{
-				RPC:          &segfetchergrpc.Requester{Dialer: dialer},
-				PathDB:       pathDB,
-				Inspector:    engine,
-				Verifier:     compat.Verifier{Verifier: trust.Verifier{Engine: engine}},
-				RevCache:     revCache,
-				Cfg:          cfg.SD,
-				TopoProvider: itopo.Provider(),
-				HeaderV2:     cfg.Features.HeaderV2,
+	var pathPolicies pathpol.PolicyMap
+	if cfg.SD.PathPolicies != "" {
+		if pathPolicies, err = pathpol.LoadPolicyMap(cfg.SD.PathPolicies); err != nil {
+			return serrors.WrapStr("loading path policies", err)
+		}
+	}
+
+	pathFetcher := fetcher.NewFetcher(
+		fetcher.FetcherConfig{
+			RPC:          &segfetchergrpc.Requester{Dialer: dialer},
+			PathDB:       pathDB,
+			Inspector:    engine,
+			Verifier:     compat.Verifier{Verifier: trust.Verifier{Engine: engine}},
+			RevCache:     revCache,
+			Cfg:          cfg.SD,
+			TopoProvider: itopo.Provider(),
+			HeaderV2:     cfg.Features.HeaderV2,
+		},
+	)
+
+	var pathHealth *health.Cache
+	if cfg.SD.ProbePaths {
+		if !cfg.Features.HeaderV2 {
+			return serrors.New("path probing requires the SCION header v2")
+		}
+		localIP, err := probeLocalIP(cfg.SD, itopo.Get())
+		if err != nil {
+			return err
+		}
+		pathHealth = &health.Cache{}
+		prober := periodic.Start(&health.Prober{
+			Cache:   pathHealth,
+			Fetcher: pathFetcher,
+			Prober: &multipath.EchoProber{
+				Dispatcher: reliable.NewDispatcher(""),
+				Local:      &snet.UDPAddr{IA: itopo.Get().IA(), Host: &net.UDPAddr{IP: localIP}},
+				// The control service of the destination AS answers the probes.
+				Host: addr.SvcCS,
 			},
-		),
-		Engine:   engine,
-		PathDB:   pathDB,
-		RevCache: revCache,
+			DestinationTTL: cfg.SD.ProbeDestinationTTL.Duration,
+		}, cfg.SD.ProbeInterval.Duration, cfg.SD.ProbeInterval.Duration)
+		defer prober.Stop()
+	}
+
+	srv := sciond.GRPCServer(cfg.SD.Address, sciond.ServerCfg{
+		Fetcher:           pathFetcher,
+		Engine:            engine,
+		PathDB:            pathDB,
+		RevCache:          revCache,
+		PathUpdates:       pathUpdates,
+		PathPolicies:      pathPolicies,
+		PathHealth:        pathHealth,
+		DemoteFailedPaths: cfg.SD.DemoteFailedPaths,
 	})
 	go func() {
 		defer log.HandlePanic()
@@ -218,3 +263,42 @@ func setup(cfg config.Config) error {
 	infraenv.InitInfraEnvironment(cfg.General.Topology())
 	return nil
 }
+
+// probeLocalIP returns the source IP address of the path probes. The replies
+// to the probes are sent to this address, so it must not be a loopback
+// address. If probe_local_ip is not set, the IP address of the SCION Daemon API
+// is used. If that is a loopback or unspecified address, e.g., the default
+// 127.0.0.1, the local IP address that is used to reach a border router of the
+// local AS is used instead.
+func probeLocalIP(cfg config.SDConfig, topo topology.Topology) (net.IP, error) {
+	if cfg.ProbeLocalIP != "" {
+		// The configuration validation rejects invalid and loopback addresses.
+		return net.ParseIP(cfg.ProbeLocalIP), nil
+	}
+	if host, _, err := net.SplitHostPort(cfg.Address); err == nil {
+		if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+			return ip, nil
+		}
+	}
+	var brIP net.IP
+	for _, name := range topo.BRNames() {
+		if br, ok := topo.BR(name); ok && br.InternalAddr != nil {
+			brIP = br.InternalAddr.IP
+			break
+		}
+	}
+	if brIP == nil {
+		return nil, serrors.New("no border router to resolve the local IP address of the " +
+			"path probes, set probe_local_ip")
+	}
+	ip, err := addrutil.ResolveLocal(brIP)
+	if err != nil {
+		return nil, serrors.WrapStr("resolving local IP address of the path probes, "+
+			"set probe_local_ip", err, "border_router", brIP)
+	}
+	if ip.IsLoopback() {
+		return nil, serrors.New("resolved local IP address of the path probes is a "+
+			"loopback address, set probe_local_ip", "ip", ip)
+	}
+	return ip, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// debug tool to dump the beacons of a control service.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/env"
+)
+
+func main() {
+	if err := realMain(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while executing: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func realMain() error {
+	address := flag.String("addr", "", "HTTP address of the control service status pages")
+	start := flag.String("start", "", "Only show beacons originated by this ISD-AS (optional)")
+	ingress := flag.Uint64("ingress", 0, "Only show beacons received on this interface (optional)")
+	showTimestamps := flag.Bool("t", false, "Show update and expiration times")
+	version := flag.Bool("version", false, "Output version information and exit.")
+	flag.Parse()
+
+	if *version {
+		fmt.Print(env.VersionInfo())
+		os.Exit(0)
+	}
+	if *address == "" {
+		return fmt.Errorf("Please specify the status page address of the control service " +
+			"using the -addr flag.")
+	}
+	query := url.Values{}
+	if *start != "" {
+		query.Set("start_isd_as", *start)
+	}
+	if *ingress != 0 {
+		query.Set("ingress_interface", fmt.Sprint(*ingress))
+	}
+	u := url.URL{Scheme: "http", Host: *address, Path: "/beacons", RawQuery: query.Encode()}
+	beacons, err := fetchBeacons(u.String())
+	if err != nil {
+		return err
+	}
+	for _, b := range beacons {
+		fmt.Println(b.toString(*showTimestamps))
+	}
+	return nil
+}
+
+func fetchBeacons(u string) ([]beaconInfo, error) {
+	rep, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer rep.Body.Close()
+	if rep.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(rep.Body)
+		return nil, fmt.Errorf("Request failed with status %q: %s", rep.Status,
+			strings.TrimSpace(string(msg)))
+	}
+	var beacons []beaconInfo
+	if err := json.NewDecoder(rep.Body).Decode(&beacons); err != nil {
+		return nil, fmt.Errorf("Error while decoding response: %v", err)
+	}
+	return beacons, nil
+}
+
+type beaconHop struct {
+	IA      addr.IA         `json:"isd_as"`
+	Ingress common.IFIDType `json:"ingress_interface"`
+	Egress  common.IFIDType `json:"egress_interface"`
+}
+
+type policyResult struct {
+	Type     string `json:"type"`
+	Usable   bool   `json:"usable"`
+	Filtered string `json:"filtered"`
+}
+
+type beaconInfo struct {
+	ID               string          `json:"id"`
+	IngressInterface common.IFIDType `json:"ingress_interface"`
+	Hops             []beaconHop     `json:"hops"`
+	Expiration       time.Time       `json:"expiration"`
+	LastUpdated      time.Time       `json:"last_updated"`
+	Policies         []policyResult  `json:"policies"`
+}
+
+func (b beaconInfo) toString(showTimestamps bool) string {
+	str := fmt.Sprintf("%s %s>%-4d", b.ID, hopsToString(b.Hops), b.IngressInterface)
+	var usage, filtered []string
+	for _, p := range b.Policies {
+		if p.Usable {
+			usage = append(usage, p.Type)
+		}
+		if p.Filtered != "" {
+			filtered = append(filtered, fmt.Sprintf("%s (%s)", p.Type, p.Filtered))
+		}
+	}
+	str += fmt.Sprintf(" | Usage: [%s]", strings.Join(usage, ","))
+	if len(filtered) > 0 {
+		str += fmt.Sprintf(" Filtered: [%s]", strings.Join(filtered, ","))
+	}
+	if showTimestamps {
+		now := time.Now()
+		updatedStr := now.Sub(b.LastUpdated).String()
+		expiryStr := b.Expiration.Sub(now).String()
+		str += fmt.Sprintf(" | Updated: %s Expires in: %s", updatedStr, expiryStr)
+	}
+	return str
+}
+
+func hopsToString(hops []beaconHop) string {
+	strs := make([]string, 0, len(hops))
+	for i, hop := range hops {
+		if i == 0 {
+			strs = append(strs, fmt.Sprintf("%s %4d", hop.IA, hop.Egress))
+			continue
+		}
+		strs = append(strs, fmt.Sprintf("%4d %s %4d", hop.Ingress, hop.IA, hop.Egress))
+	}
+	return strings.Join(strs, ">")
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/beacon"
+	"github.com/scionproto/scion/go/cs/beacon/beacondbtest"
+	"github.com/scionproto/scion/go/cs/beacon/mock_beacon"
+	"github.com/scionproto/scion/go/pkg/cs"
+)
+
+func TestFetchBeacons(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	b, _ := beacondbtest.AllocBeacon(t, mctrl, beacondbtest.Info2, 12, 0)
+	db := mock_beacon.NewMockDB(mctrl)
+	db.EXPECT().GetBeacons(gomock.Any(), gomock.Any()).Return(
+		[]beacon.StoredBeacon{{Beacon: b, Usage: beacon.UsageUpReg}}, nil,
+	)
+	up := beacon.Policy{Type: beacon.UpRegPolicy}
+	down := beacon.Policy{Type: beacon.DownRegPolicy, Filter: beacon.Filter{MaxHopsLength: 1}}
+	up.InitDefaults()
+	down.InitDefaults()
+	server := httptest.NewServer(cs.BeaconsHandler{
+		DB:       db,
+		Policies: func() []beacon.Policy { return []beacon.Policy{up, down} },
+	})
+	defer server.Close()
+
+	beacons, err := fetchBeacons(server.URL)
+	require.NoError(t, err)
+	require.Len(t, beacons, 1)
+	str := beacons[0].toString(false)
+	assert.Contains(t, str, b.Segment.GetLoggingID())
+	assert.Contains(t, str, "1-ff00:0:330    4>   1 1-ff00:0:331    4>12  ")
+	assert.Contains(t, str, "| Usage: [UpSegmentRegistration]")
+	assert.Contains(t, str, "Filtered: [DownSegmentRegistration (MaxHopsLength exceeded")
+	assert.NotContains(t, str, "Expires in")
+	assert.Contains(t, beacons[0].toString(true), "Expires in")
+}
+
+func TestFetchBeaconsError(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+
+	server := httptest.NewServer(cs.BeaconsHandler{DB: mock_beacon.NewMockDB(mctrl)})
+	defer server.Close()
+
+	_, err := fetchBeacons(server.URL + "?start_isd_as=garbage")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+	assert.Contains(t, err.Error(), "parsing start_isd_as")
+}